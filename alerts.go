@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// printMatchingAlerts lists the notification-controller Alerts that forward
+// the resource's events, warning when none does. Failures to read Alerts are
+// only warnings, since alerting is optional.
+func printMatchingAlerts(ctx context.Context, opts reconcileOptions) {
+	out := output.FromContext(ctx)
+	kind := opts.kind
+	if kind == "source" {
+		kind = opts.sourceType
+	}
+
+	cluster, err := events.NewCluster(opts.client)
+	if err != nil {
+		out.PrintWarning(fmt.Sprintf("Could not look up alerts: %v", err))
+		return
+	}
+	alerts, err := cluster.MatchingAlerts(ctx, kind, opts.namespace, opts.name)
+	if err != nil {
+		out.PrintWarning(fmt.Sprintf("Could not look up alerts: %v", err))
+		return
+	}
+	if len(alerts) == 0 {
+		out.PrintWarning(fmt.Sprintf("No Alert matches %s %s/%s, its events are not forwarded", opts.kind, opts.namespace, opts.name))
+		return
+	}
+
+	out.PrintSublog(fmt.Sprintf("🔔 Events are forwarded by %d alert(s):", len(alerts)))
+	for _, a := range alerts {
+		line := fmt.Sprintf("   %s/%s → %s", a.Namespace, a.Name, a.Provider)
+		if a.ProviderType != "" {
+			line += fmt.Sprintf(" (%s)", a.ProviderType)
+		} else {
+			line += " (provider not found)"
+		}
+		line += ", severity " + a.Severity
+		if a.Filtered {
+			line += ", filtered by message"
+		}
+		if a.Suspended {
+			line += ", suspended"
+		}
+		out.PrintSublog(line)
+	}
+}
+
+// tailNotifications prints the notification-controller's log lines about
+// events until ctx is done. When the logs end, e.g. because the controller
+// restarted, it warns and resumes with the current pods, from where it
+// stopped.
+func tailNotifications(ctx context.Context, cluster *events.Cluster, namespace string) {
+	printEntry := func(entry events.NotificationLog) {
+		message := entry.Message
+		if entry.Object != "" {
+			message = fmt.Sprintf("%s: %s", entry.Object, message)
+		}
+		if entry.Error {
+			output.PrintWarning(fmt.Sprintf("Notification: %s", message))
+			return
+		}
+		output.PrintSublog(fmt.Sprintf("🔔 %s", message))
+	}
+
+	since := time.Now()
+	delay := retryBaseDelay
+	for {
+		started := time.Now()
+		err := cluster.TailNotifications(ctx, namespace, since, printEntry)
+		if ctx.Err() != nil {
+			return
+		}
+		since = time.Now()
+		// A tail that ran for a while starts over with the shortest delay
+		if since.Sub(started) > retryMaxDelay {
+			delay = retryBaseDelay
+		}
+		output.PrintWarning(fmt.Sprintf("Notification tail interrupted (%v), resuming in %s", err, delay))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, retryMaxDelay)
+	}
+}
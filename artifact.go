@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// currentArtifactRevision returns the source's artifact revision before the
+// trigger, or "" when it has no artifact yet.
+func currentArtifactRevision(monitor *events.Monitor) string {
+	artifact, err := monitor.Artifact()
+	if err != nil || artifact == nil {
+		return ""
+	}
+	return artifact.Revision
+}
+
+// waitForNewArtifact waits until the source has an artifact whose revision
+// differs from previous, or ctx expires. A Ready source still serving the old
+// artifact does not count as reconciled.
+func waitForNewArtifact(ctx context.Context, monitor *events.Monitor, previous string) error {
+	out := output.FromContext(ctx)
+	ticker := time.NewTicker(monitor.PollInterval())
+	defer ticker.Stop()
+
+	for {
+		artifact, err := monitor.Artifact()
+		if err != nil {
+			return fmt.Errorf("failed to read artifact: %w", err)
+		}
+		if artifact != nil && artifact.Revision != "" && artifact.Revision != previous {
+			out.PrintSublog(fmt.Sprintf("📦 New artifact: %s", artifact.Revision))
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if artifact == nil {
+				return fmt.Errorf("source has no artifact")
+			}
+			return fmt.Errorf("artifact revision unchanged since the trigger (%s)", artifact.Revision)
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitForSourceRevision waits until the Kustomization has applied the current
+// artifact revision of its source, or ctx expires. Ready alone may still
+// reflect an older revision when the source moved on during the reconcile.
+func waitForSourceRevision(ctx context.Context, monitor *events.Monitor) error {
+	out := output.FromContext(ctx)
+	ticker := time.NewTicker(monitor.PollInterval())
+	defer ticker.Stop()
+
+	waiting := false
+	for {
+		applied, source, err := monitor.SourceRevisions()
+		if err != nil {
+			return fmt.Errorf("failed to read revisions: %w", err)
+		}
+		if source != "" && applied == source {
+			out.PrintSublog(fmt.Sprintf("🔗 Applied the source revision %s", source))
+			return nil
+		}
+		if !waiting {
+			out.PrintStatus(fmt.Sprintf("Waiting for source revision %s to be applied (applied: %s)", orDash(source), orDash(applied)))
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			if source == "" {
+				return fmt.Errorf("source has no artifact")
+			}
+			return fmt.Errorf("applied revision %s, source is at %s", orDash(applied), source)
+		case <-ticker.C:
+		}
+	}
+}
+
+// artifactHasDigest reports whether the artifact matches digest, either as
+// the digest of the artifact itself or as the OCI manifest digest in its
+// revision ("<tag>@sha256:...")
+func artifactHasDigest(artifact *events.Artifact, digest string) bool {
+	if artifact == nil {
+		return false
+	}
+	return artifact.Digest == digest || strings.HasSuffix(artifact.Revision, "@"+digest) || artifact.Revision == digest
+}
+
+// waitForArtifactDigest waits until the source's artifact has the expected
+// digest, or ctx expires
+func waitForArtifactDigest(ctx context.Context, monitor *events.Monitor, digest string) error {
+	out := output.FromContext(ctx)
+	ticker := time.NewTicker(monitor.PollInterval())
+	defer ticker.Stop()
+
+	announced := false
+	for {
+		artifact, err := monitor.Artifact()
+		if err != nil {
+			return fmt.Errorf("failed to read artifact: %w", err)
+		}
+		if artifactHasDigest(artifact, digest) {
+			out.PrintSublog(fmt.Sprintf("📦 Artifact has digest %s (%s)", digest, artifact.Revision))
+			return nil
+		}
+		if !announced {
+			out.PrintStatus(fmt.Sprintf("Waiting for artifact with digest %s", digest))
+			announced = true
+		}
+
+		select {
+		case <-ctx.Done():
+			if artifact == nil {
+				return fmt.Errorf("source has no artifact")
+			}
+			return fmt.Errorf("expected %s, artifact is %s (digest %s)", digest, artifact.Revision, artifact.Digest)
+		case <-ticker.C:
+		}
+	}
+}
+
+// printArtifactDetails prints the artifact a source reconciled to and, for
+// git sources with commitInfo, the commit message and author, fetched from
+// the origin with the local git credentials.
+func printArtifactDetails(ctx context.Context, monitor *events.Monitor, sourceType string, commitInfo bool) {
+	out := output.FromContext(ctx)
+	artifact, err := monitor.Artifact()
+	if err != nil || artifact == nil {
+		return
+	}
+
+	out.PrintSublog(fmt.Sprintf("📦 Artifact %s", artifact.Revision))
+	var details []string
+	if artifact.Digest != "" {
+		details = append(details, artifact.Digest)
+	}
+	if artifact.Size > 0 {
+		details = append(details, formatBytes(artifact.Size))
+	}
+	if artifact.LastUpdateTime != "" {
+		details = append(details, "updated "+artifact.LastUpdateTime)
+	}
+	if len(details) > 0 {
+		out.PrintSublog("   " + strings.Join(details, ", "))
+	}
+	// Set by "flux push artifact"
+	if source := artifact.Metadata["org.opencontainers.image.source"]; source != "" {
+		line := "   from " + source
+		if revision := artifact.Metadata["org.opencontainers.image.revision"]; revision != "" {
+			line += " at " + revision
+		}
+		out.PrintSublog(line)
+	}
+	if v := artifact.Verification; v != nil {
+		printVerification(out, v)
+	}
+
+	if sourceType != "git" || !commitInfo || artifact.SourceURL == "" || output.Redacting() {
+		return
+	}
+	sha := commitSHA(artifact.Revision)
+	if sha == "" {
+		return
+	}
+	commit, err := fetchCommit(ctx, artifact.SourceURL, sha)
+	if err != nil {
+		out.PrintSublog(fmt.Sprintf("   commit details unavailable: %v", err))
+		return
+	}
+	out.PrintSublog(fmt.Sprintf("   %q by %s", commit.subject, commit.author))
+}
+
+// printVerification prints the result of the source's signature verification
+func printVerification(out *output.Printer, v *events.Verification) {
+	if !v.Verified {
+		out.PrintWarning(fmt.Sprintf("spec.verify is set but the %s signature is not reported as verified", v.Provider))
+		return
+	}
+	out.PrintSublog(fmt.Sprintf("   🔏 %s signature verified: %s", v.Provider, v.Message))
+	if v.Identity != "" {
+		out.PrintSublog("      identity: " + v.Identity)
+	}
+}
+
+// commitSHA extracts the commit SHA from a git artifact revision
+// ("main@sha1:<sha>", or "main/<sha>" on older Flux versions)
+func commitSHA(revision string) string {
+	if i := strings.LastIndex(revision, ":"); i >= 0 {
+		return revision[i+1:]
+	}
+	if i := strings.LastIndex(revision, "/"); i >= 0 {
+		return revision[i+1:]
+	}
+	return ""
+}
+
+// revisionAtCommit reports whether a git artifact revision is of the commit
+// sha, which may be abbreviated
+func revisionAtCommit(revision, sha string) bool {
+	commit := commitSHA(revision)
+	return commit != "" && sha != "" && strings.HasPrefix(commit, sha)
+}
+
+type commitDetails struct {
+	subject string
+	author  string
+}
+
+// fetchCommit fetches a single commit from the repository into a temporary
+// repository and reads its subject and author. Credentials come from the
+// local git configuration (credential helpers, SSH agent); prompts are
+// disabled so missing credentials fail fast.
+func fetchCommit(ctx context.Context, url, sha string) (*commitDetails, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	dir, err := os.MkdirTemp("", "flux-enhanced-cli-commit-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	git := func(args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = env
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		stdout, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("%s", strings.TrimPrefix(lastLine(msg), "fatal: "))
+			}
+			return "", err
+		}
+		return string(stdout), nil
+	}
+
+	if _, err := git("init", "-q"); err != nil {
+		return nil, err
+	}
+	// The URL and SHA come from the cluster: "--" keeps them from being
+	// taken as options
+	if _, err := git("fetch", "-q", "--depth=1", "--filter=blob:none", "--", url, sha); err != nil {
+		return nil, err
+	}
+	log, err := git("log", "-1", "--format=%s%x00%an <%ae>, %ar", "FETCH_HEAD")
+	if err != nil {
+		return nil, err
+	}
+	subject, author, _ := strings.Cut(strings.TrimSpace(log), "\x00")
+	return &commitDetails{subject: subject, author: author}, nil
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(s, "\n")
+	return lines[len(lines)-1]
+}
+
+// formatBytes formats a size in bytes with binary units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/config"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+)
+
+// batchStage is a stage of a batch file
+type batchStage struct {
+	name      string
+	parallel  bool
+	onFailure string
+	items     []batchItem
+}
+
+// batchItem is a resource of a batch file with its expected revision and
+// dependencies
+type batchItem struct {
+	id        string
+	opts      reconcileOptions
+	revision  string
+	dependsOn []string
+}
+
+// batchResult is the outcome of a batch item
+type batchResult struct {
+	report.Result
+	stage string
+}
+
+// loadBatch reads a batch file ("-" for stdin) and returns its stages with a
+// reconcile per listed resource, based on the command line options
+func loadBatch(path string, base reconcileOptions) ([]batchStage, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch file: %w", err)
+	}
+	batch, err := config.ParseBatch(data)
+	if err != nil {
+		return nil, err
+	}
+
+	stages := make([]batchStage, 0, len(batch.Stages))
+	for _, s := range batch.Stages {
+		stage := batchStage{name: s.Name, parallel: s.Parallel, onFailure: s.OnFailure}
+		for _, r := range s.Resources {
+			opts := base
+			opts.name = r.Name
+			if r.Namespace != "" {
+				opts.namespace = r.Namespace
+			}
+			if r.Timeout != nil {
+				// The file's timeout bounds the resource and its wait
+				opts.timeout, opts.waitTimeout = r.Timeout.Duration, 0
+			}
+			if strings.ToLower(r.Kind) == "source" {
+				opts.kind, opts.sourceType = "source", r.SourceType
+				if opts.sourceType == "" {
+					opts.sourceType = "git"
+				}
+			} else {
+				monitorKind, ok := resourceKindAliases[strings.ToLower(r.Kind)]
+				if !ok {
+					return nil, fmt.Errorf("%s: unsupported kind '%s'", s.Name, r.Kind)
+				}
+				opts.kind, opts.sourceType = cliKind(monitorKind)
+			}
+			if r.PreHook != "" {
+				opts.preHook = r.PreHook
+			}
+			if r.PostHook != "" {
+				opts.postHook = r.PostHook
+			}
+			// --force only applies to the HelmReleases of the batch
+			opts.force = base.force && opts.kind == "helmrelease"
+			stage.items = append(stage.items, batchItem{id: r.ID, opts: opts, revision: r.Revision, dependsOn: r.DependsOn})
+		}
+		stages = append(stages, stage)
+	}
+	return stages, nil
+}
+
+// runBatch reconciles the stages in order. Within a stage resources run one
+// after the other, or concurrently for parallel stages, each starting once
+// its dependencies succeeded. A failed stage with the "stop" policy skips
+// the later stages.
+func runBatch(ctx context.Context, stages []batchStage) []batchResult {
+	var results []batchResult
+	// outcomes records whether each finished item succeeded
+	outcomes := map[string]bool{}
+	stoppedBy := ""
+	for _, stage := range stages {
+		if stoppedBy != "" {
+			for _, item := range stage.items {
+				results = append(results, batchResult{skippedResult(item.opts, fmt.Sprintf("skipped after %s failed", stoppedBy)), stage.name})
+			}
+			continue
+		}
+
+		if len(stages) > 1 {
+			output.PrintMain("🧱", fmt.Sprintf("Stage %s", stage.name), output.ColorBlue)
+		}
+		stageResults := runStage(ctx, stage, outcomes)
+		failed := false
+		for i, r := range stageResults {
+			outcomes[stage.items[i].id] = r.Success
+			results = append(results, batchResult{r, stage.name})
+			failed = failed || !r.Success
+		}
+		if failed && stage.onFailure == config.OnFailureStop {
+			stoppedBy = stage.name
+		}
+	}
+	return results
+}
+
+// runStage reconciles the items of a stage; outcomes holds the items of
+// earlier stages
+func runStage(ctx context.Context, stage batchStage, outcomes map[string]bool) []report.Result {
+	results := make([]report.Result, len(stage.items))
+	index := map[string]int{}
+	done := make([]chan struct{}, len(stage.items))
+	for i, item := range stage.items {
+		index[item.id] = i
+		done[i] = make(chan struct{})
+	}
+
+	// run waits for the item's dependencies, then reconciles it unless one
+	// of them failed
+	run := func(ctx context.Context, i int) {
+		defer close(done[i])
+		item := stage.items[i]
+		for _, dep := range item.dependsOn {
+			success, ok := outcomes[dep]
+			if j, inStage := index[dep]; inStage {
+				<-done[j]
+				success, ok = results[j].Success, true
+			}
+			if ok && !success {
+				results[i] = skippedResult(item.opts, fmt.Sprintf("skipped because %s failed", dep))
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			results[i] = skippedResult(item.opts, "cancelled")
+			return
+		}
+
+		output.FromContext(ctx).PrintMain("▶", fmt.Sprintf("%s %s/%s", item.opts.kind, item.opts.namespace, item.opts.name), output.ColorBold)
+		resourceCtx, cancel := context.WithTimeout(ctx, item.opts.timeout)
+		results[i] = runReconcile(resourceCtx, item.opts)
+		cancel()
+		checkRevision(ctx, item.revision, &results[i])
+	}
+
+	if stage.parallel {
+		var wg sync.WaitGroup
+		for i, item := range stage.items {
+			wg.Add(1)
+			go func(i int, id string) {
+				defer wg.Done()
+				run(output.WithPrinter(ctx, output.NewPrinter(id)), i)
+			}(i, item.id)
+		}
+		wg.Wait()
+		return results
+	}
+
+	// Sequential stages run dependencies first, otherwise in file order
+	started := make([]bool, len(stage.items))
+	var visit func(i int)
+	visit = func(i int) {
+		if started[i] {
+			return
+		}
+		started[i] = true
+		for _, dep := range stage.items[i].dependsOn {
+			if j, inStage := index[dep]; inStage {
+				visit(j)
+			}
+		}
+		run(ctx, i)
+	}
+	for i := range stage.items {
+		visit(i)
+	}
+	return results
+}
+
+func skippedResult(opts reconcileOptions, message string) report.Result {
+	return report.Result{
+		Kind: opts.kind, Name: opts.name, Namespace: opts.namespace, Context: opts.client.Context,
+		Skipped: true, ExitCode: 1, Message: message,
+	}
+}
+
+// checkRevision fails a successful result whose revision doesn't match the
+// one the batch file expects
+func checkRevision(ctx context.Context, expected string, r *report.Result) {
+	if expected == "" || !r.Success || revisionMatches(r.Revision, expected) {
+		return
+	}
+	r.Success = false
+	r.ExitCode = 1
+	r.Message = fmt.Sprintf("reconciled revision %s, expected %s", r.Revision, expected)
+	output.FromContext(ctx).PrintError(fmt.Sprintf("%s %s/%s: %s", r.Kind, r.Namespace, r.Name, r.Message))
+}
+
+// revisionMatches reports whether revision (e.g. "main@sha1:4f2738a1...",
+// "sha256:...", "6.5.4") starts with expected, or its SHA or digest does
+func revisionMatches(revision, expected string) bool {
+	if strings.HasPrefix(revision, expected) {
+		return true
+	}
+	if i := strings.LastIndex(revision, ":"); i >= 0 {
+		return strings.HasPrefix(revision[i+1:], expected)
+	}
+	return false
+}
+
+// printBatchSummary prints the outcome of every resource of a batch
+func printBatchSummary(results []batchResult, withStages bool) {
+	headers := append(append([]string{"RESOURCE", "STATUS", "REVISION"}, timingColumns...), "DURATION", "MESSAGE")
+	if withStages {
+		headers = append([]string{"STAGE"}, headers...)
+	}
+	rows := make([][]string, 0, len(results))
+	failed := 0
+	for _, r := range results {
+		status := "Ready"
+		switch {
+		case r.Skipped:
+			status = "Skipped"
+			if !r.Success {
+				failed++
+			}
+		case !r.Success:
+			status = "Failed"
+			failed++
+		case r.Flapping:
+			status = "Ready (flapping)"
+		}
+		row := append([]string{fmt.Sprintf("%s/%s/%s", r.Kind, r.Namespace, r.Name), status, r.Revision}, timingCells(r.Result)...)
+		row = append(row, r.Duration.Round(time.Second).String(), r.Message)
+		if withStages {
+			row = append([]string{r.stage}, row...)
+		}
+		rows = append(rows, row)
+	}
+
+	output.Println()
+	output.PrintTable(headers, rows)
+	output.Println()
+
+	if failed > 0 {
+		output.PrintError(fmt.Sprintf("Batch failed for %d of %d resources", failed, len(results)))
+		return
+	}
+	output.PrintMain("✅", fmt.Sprintf("Reconciled all %d resources", len(results)), output.ColorGreen)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+const buildUsage = `Usage: flux-enhanced-cli build kustomization <name> --path <dir> [options]
+
+Renders a Kustomization from a local checkout the way kustomize-controller
+would apply it, with its patches and post-build substitutions, by running
+"flux build kustomization". The YAML is written to stdout, syntax highlighted
+on a terminal.
+
+With --diff-against-cluster the rendered objects are compared with the
+cluster instead ("flux diff kustomization"); the exit code is 1 when they
+differ.
+`
+
+// buildCommand implements "build kustomization <name>"
+func buildCommand(args []string) int {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	namespace := fs.String("namespace", "flux-system", "Namespace")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace (shorthand)")
+	path := fs.String("path", "", "Local path of the Kustomization's sources")
+	diff := fs.Bool("diff-against-cluster", false, "Show how the rendered objects differ from the cluster instead of the YAML")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Timeout for the build")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, buildUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(positional) != 2 || resourceKindAliases[positional[0]] != "kustomization" || *path == "" {
+		fs.Usage()
+		return 1
+	}
+	if clientOpts.InCluster {
+		fmt.Fprintf(os.Stderr, "Error: build needs the flux binary and is not supported with --in-cluster\n")
+		return 1
+	}
+	if fluxMissing() {
+		fmt.Fprintf(os.Stderr, "Error: build needs the flux binary in PATH\n")
+		return 1
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	handleInterrupts(cancel)
+
+	opts := reconcileOptions{kind: "kustomization", name: positional[1], namespace: *namespace, path: *path, client: *clientOpts}
+	if *diff {
+		return diffAgainstCluster(ctx, opts)
+	}
+	built, err := fluxBuild(ctx, opts)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	if err := output.WriteYAML(os.Stdout, string(built), output.StdoutIsTerminal()); err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	return 0
+}
+
+// diffAgainstCluster runs "flux diff kustomization" against a local path and
+// prints the differences, returning 1 when there are any
+func diffAgainstCluster(ctx context.Context, opts reconcileOptions) int {
+	args := append([]string{"diff", "kustomization", opts.name, "-n", opts.namespace, "--path", opts.path}, opts.client.FluxArgs()...)
+	cmd := command(ctx, "flux", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	// flux diff exits with 1 both on differences and on errors; only the
+	// former print a diff
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stdout.Len() > 0) {
+		output.PrintError(fmt.Sprintf("flux diff failed: %v: %s", err, strings.TrimSpace(stderr.String())))
+		return 1
+	}
+	if err := output.WriteDiff(os.Stdout, stdout.String(), output.StdoutIsTerminal()); err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	if err != nil {
+		return 1
+	}
+	output.PrintMain("✅", fmt.Sprintf("kustomization %s/%s matches the cluster", opts.namespace, opts.name), output.ColorGreen)
+	return 0
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// waitForChildren waits until the Kustomizations and HelmReleases applied by
+// the Kustomization, and by those in turn, are Ready (--recursive) and
+// reconciled since the run started at since (zero when nothing was
+// triggered). It fails as soon as one is Stalled. Suspended children are
+// reported and skipped.
+func waitForChildren(ctx context.Context, monitor *events.Monitor, since time.Time) error {
+	out := output.FromContext(ctx)
+	out.PrintSublog("🌳 Waiting for child Kustomizations and HelmReleases...")
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	reported := map[string]bool{}
+	for {
+		children, err := monitor.ChildrenHealth(ctx, since)
+		if err != nil {
+			return fmt.Errorf("failed to check child objects: %w", err)
+		}
+
+		var pending, failed []string
+		ready := 0
+		for _, c := range children {
+			output.RedactNames(c.Object.Name)
+			output.RedactNamespaces(c.Object.Namespace)
+			name := c.Object.String()
+			switch {
+			case c.Status == events.StatusCurrent:
+				ready++
+				if !reported[name] {
+					reported[name] = true
+					out.PrintSublog(fmt.Sprintf("  ✅ %s ready", name))
+				}
+			case c.Suspended:
+				if !reported[name] {
+					reported[name] = true
+					out.PrintWarning(fmt.Sprintf("%s is suspended and not ready, skipping it: %s", name, output.Preview(c.Message)))
+				}
+			case c.Status == events.StatusFailed:
+				failed = append(failed, fmt.Sprintf("%s (%s)", name, c.Message))
+			default:
+				pending = append(pending, name)
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("child objects failed: %s", strings.Join(failed, ", "))
+		}
+		if len(pending) == 0 {
+			out.PrintSublog(fmt.Sprintf("✅ %d child objects ready", ready))
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			for _, c := range children {
+				if c.Status != events.StatusCurrent && !c.Suspended {
+					out.PrintWarning(fmt.Sprintf("%s (applied by %s) is %s: %s", c.Object, c.Parent, c.Status, output.Preview(c.Message)))
+				}
+			}
+			return fmt.Errorf("child objects not ready: %s", strings.Join(pending, ", "))
+		case <-ticker.C:
+			out.PrintStatus(fmt.Sprintf("Waiting for %d of %d child objects to become ready", len(pending), len(children)))
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+)
+
+// parseArgs parses flags that may be interspersed with positional arguments
+// (e.g. "deploy payments --timeout 5m") and returns the positional ones.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseDeadline parses --deadline: an RFC 3339 time, or a wall-clock time in
+// the local time zone as HH:MM or HH:MM:SS, tomorrow's when it has passed today
+func parseDeadline(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+			if !at.After(now) {
+				at = at.AddDate(0, 0, 1)
+			}
+			return at, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid deadline %q: expected an RFC 3339 time (2024-05-01T14:00:00Z) or a local time (14:00)", value)
+}
+
+// resourceKindAliases maps accepted kind names and short names to the kind
+// understood by the event monitor.
+var resourceKindAliases = map[string]string{
+	"kustomization":   "kustomization",
+	"kustomizations":  "kustomization",
+	"ks":              "kustomization",
+	"helmrelease":     "helmrelease",
+	"helmreleases":    "helmrelease",
+	"hr":              "helmrelease",
+	"gitrepository":   "git",
+	"gitrepositories": "git",
+	"gitrepo":         "git",
+	"ocirepository":   "oci",
+	"ocirepositories": "oci",
+	"ocirepo":         "oci",
+	"bucket":          "bucket",
+	"buckets":         "bucket",
+	"terraform":       "terraform",
+	"terraforms":      "terraform",
+	"tf":              "terraform",
+}
+
+// parseResourceRef parses a "kind/name" reference such as "kustomization/apps"
+// or "hr/podinfo" and returns the monitor kind and the name.
+func parseResourceRef(ref string) (string, string, error) {
+	kind, name, ok := strings.Cut(ref, "/")
+	if !ok || kind == "" || name == "" {
+		return "", "", fmt.Errorf("invalid resource '%s', expected <kind>/<name>", ref)
+	}
+	monitorKind, ok := resourceKindAliases[strings.ToLower(kind)]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported kind '%s'", kind)
+	}
+	return monitorKind, name, nil
+}
+
+// addClientFlags registers the flags that control the Kubernetes connection
+func addClientFlags(fs *flag.FlagSet) *events.ClientOptions {
+	opts := &events.ClientOptions{}
+	fs.StringVar(&opts.Context, "context", "", "Kubeconfig context to use (defaults to the current context)")
+	fs.BoolVar(&opts.InCluster, "in-cluster", false, "Run inside a pod: use its service account (no kubeconfig) and its namespace by default, and reconcile without the flux binary")
+	fs.StringVar(&opts.As, "as", "", "User to impersonate, e.g. system:serviceaccount:<namespace>:<name>")
+	fs.Var((*stringList)(&opts.AsGroups), "as-group", "Group to impersonate (repeatable, requires --as)")
+	fs.Float64Var(&opts.QPS, "kube-qps", 50, "Maximum Kubernetes API requests per second")
+	fs.IntVar(&opts.Burst, "kube-burst", 100, "Maximum burst of Kubernetes API requests above --kube-qps")
+	fs.DurationVar(&opts.RequestTimeout, "request-timeout", 0, "Timeout of a single Kubernetes API request, watches and log streams excluded (0 means no timeout)")
+	fs.BoolVar(&opts.SuppressWarnings, "suppress-warnings", false, "Hide Kubernetes API warnings (e.g. deprecated API versions); otherwise each is shown once")
+	return opts
+}
+
+// parseResourceArgs interprets the positional arguments naming the resource
+// to reconcile: "<kind> <name>", "<kind>/<name>" or "source <type> <name>",
+// with the kind aliases of resourceKindAliases ("ks my-app"). The name may be
+// left out when resources are picked with a selector. It returns the kind
+// and source type as used by --kind and --source-type.
+func parseResourceArgs(args []string) (kind, sourceType, name string, err error) {
+	if len(args) == 1 && strings.Contains(args[0], "/") {
+		var monitorKind string
+		if monitorKind, name, err = parseResourceRef(args[0]); err != nil {
+			return "", "", "", err
+		}
+		kind, sourceType = cliKind(monitorKind)
+		return kind, sourceType, name, nil
+	}
+	if len(args) > 3 {
+		return "", "", "", fmt.Errorf("too many arguments: %s", strings.Join(args, " "))
+	}
+
+	if strings.ToLower(args[0]) == "source" {
+		if len(args) > 1 {
+			sourceType = strings.ToLower(args[1])
+			if monitorKind, ok := resourceKindAliases[sourceType]; ok {
+				sourceType = monitorKind
+			}
+			if k, _ := cliKind(sourceType); k != "source" {
+				return "", "", "", fmt.Errorf("unsupported source type '%s'. Valid types: git, oci, bucket", args[1])
+			}
+		}
+		if len(args) > 2 {
+			name = args[2]
+		}
+		return "source", sourceType, name, nil
+	}
+	if len(args) > 2 {
+		return "", "", "", fmt.Errorf("too many arguments: %s", strings.Join(args, " "))
+	}
+
+	monitorKind, ok := resourceKindAliases[strings.ToLower(args[0])]
+	if !ok {
+		return "", "", "", fmt.Errorf("unsupported kind '%s'", args[0])
+	}
+	kind, sourceType = cliKind(monitorKind)
+	if len(args) > 1 {
+		name = args[1]
+	}
+	return kind, sourceType, name, nil
+}
+
+// cliKind splits a monitor kind into the --kind and --source-type values
+func cliKind(monitorKind string) (string, string) {
+	switch monitorKind {
+	case "git", "oci", "bucket":
+		return "source", monitorKind
+	}
+	return monitorKind, ""
+}
+
+// applyInCluster checks the --in-cluster flag against the others and makes
+// the service account's namespace the default of namespace, unless the
+// --namespace flag was set.
+func applyInCluster(fs *flag.FlagSet, opts *events.ClientOptions, namespace *string) error {
+	if !opts.InCluster {
+		return nil
+	}
+	if opts.Context != "" {
+		return fmt.Errorf("--in-cluster cannot be combined with --context")
+	}
+	namespaceSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "namespace" || f.Name == "n" {
+			namespaceSet = true
+		}
+	})
+	if ns := events.InClusterNamespace(); ns != "" && !namespaceSet && namespace != nil {
+		*namespace = ns
+	}
+	return nil
+}
+
+// stringList is a flag that may be repeated, collecting every value
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
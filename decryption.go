@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// reportDecryptionFailure explains a Kustomization that failed on SOPS
+// decryption: the file, the Secret and key provider involved, and what the
+// controller needs to decrypt, instead of leaving the raw message in a
+// condition
+func reportDecryptionFailure(ctx context.Context, monitor *events.Monitor, fluxNamespace string) {
+	failure := monitor.DecryptionFailure()
+	if failure == nil {
+		return
+	}
+	out := output.FromContext(ctx)
+	title := "🔐 SOPS decryption failed"
+	if failure.File != "" {
+		title += " for " + failure.File
+	}
+	if failure.Provider != "" {
+		title += " (" + failure.Provider + " key)"
+	}
+	out.PrintSublog(title)
+	for _, hint := range failure.Hints(fluxNamespace) {
+		out.PrintSublog("  💡 " + hint)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+)
+
+const deployUsage = `Usage: flux-enhanced-cli deploy --commit <sha> --source <gitrepository> [options]
+
+Makes sure a pushed commit is live: waits until the GitRepository fetched the
+commit (reconciling it until it does), then reconciles the Kustomizations
+that apply the GitRepository and verifies each applied the commit. The exit
+code is 0 only when all of them did.
+`
+
+// deployCommand implements "deploy --commit <sha>"
+func deployCommand(args []string) int {
+	fs := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	commit := fs.String("commit", "", "Commit SHA that must be live (at least 7 characters)")
+	source := fs.String("source", "", "Name of the GitRepository the commit is pushed to")
+	namespace := fs.String("namespace", "flux-system", "Namespace of the GitRepository")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace of the GitRepository (shorthand)")
+	kustomizations := fs.String("kustomization", "", "Comma-separated Kustomizations to reconcile, as [namespace/]name (default: all Kustomizations applying the GitRepository)")
+	timeout := fs.Duration("timeout", 10*time.Minute, "Timeout for the whole deploy")
+	retryInterval := fs.Duration("retry-interval", 10*time.Second, "Wait between reconciles of the GitRepository while it hasn't fetched the commit")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	expandErrors := fs.Bool("expand-errors", false, "Print long condition and event messages in full instead of a preview")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, deployUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+	if _, err := parseArgs(fs, args); err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	sha := strings.ToLower(*commit)
+	if len(sha) < 7 || strings.Trim(sha, "0123456789abcdef") != "" || *source == "" {
+		fs.Usage()
+		return 1
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+	if *expandErrors {
+		output.ExpandErrors()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	handleInterrupts(cancel)
+
+	flushTracing := initTracing()
+	defer flushTracing()
+
+	cluster, err := events.NewCluster(*clientOpts)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+
+	output.PrintMain("🚀", fmt.Sprintf("Deploying commit %s of GitRepository %s/%s", shortSHA(sha), *namespace, *source), output.ColorBlue)
+	sourceOpts := reconcileOptions{
+		kind:       "source",
+		sourceType: "git",
+		name:       *source,
+		namespace:  *namespace,
+		wait:       true,
+		timeout:    *timeout,
+		client:     *clientOpts,
+	}
+	if err := waitForCommit(ctx, sourceOpts, sha, *retryInterval); err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+
+	targets, err := deployTargets(ctx, cluster, *kustomizations, *namespace, *source)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	if len(targets) == 0 {
+		output.PrintWarning(fmt.Sprintf("No Kustomization applies GitRepository %s/%s", *namespace, *source))
+		return 0
+	}
+
+	var results []report.Result
+	for _, target := range targets {
+		output.PrintMain("▶", fmt.Sprintf("kustomization %s/%s", target.Namespace, target.Name), output.ColorCyan)
+		result := runReconcile(ctx, reconcileOptions{
+			kind:                  "kustomization",
+			name:                  target.Name,
+			namespace:             target.Namespace,
+			wait:                  true,
+			timeout:               *timeout,
+			client:                *clientOpts,
+			skipSource:            true,
+			requireSourceRevision: true,
+		})
+		if result.Success && !revisionAtCommit(result.Revision, sha) {
+			result.Success = false
+			result.Message = fmt.Sprintf("applied %s instead of commit %s", result.Revision, shortSHA(sha))
+			output.PrintError(fmt.Sprintf("kustomization %s/%s %s", target.Namespace, target.Name, result.Message))
+		}
+		results = append(results, result)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	printSelectionSummary(results)
+	for _, r := range results {
+		if !r.Success {
+			return 1
+		}
+	}
+	output.PrintMain("✅", fmt.Sprintf("Commit %s is live", shortSHA(sha)), output.ColorGreen)
+	return 0
+}
+
+// waitForCommit reconciles the GitRepository until its artifact is the
+// commit, skipping the reconcile when it already is
+func waitForCommit(ctx context.Context, opts reconcileOptions, sha string, retryInterval time.Duration) error {
+	monitor, err := events.NewMonitor(ctx, opts.client, "git", opts.name, opts.namespace)
+	if err != nil {
+		return err
+	}
+	revision, err := monitor.Revision()
+	monitor.Stop()
+	if err != nil {
+		return err
+	}
+	if revisionAtCommit(revision, sha) {
+		output.PrintStatus(fmt.Sprintf("GitRepository %s/%s already fetched %s", opts.namespace, opts.name, revision))
+		return nil
+	}
+
+	for {
+		result := runReconcile(ctx, opts)
+		if !result.Success {
+			return fmt.Errorf("GitRepository %s/%s failed: %s", opts.namespace, opts.name, result.Message)
+		}
+		if revisionAtCommit(result.Revision, sha) {
+			return nil
+		}
+		output.PrintStatus(fmt.Sprintf("GitRepository is at %s, not %s yet; reconciling again in %s", result.Revision, shortSHA(sha), retryInterval))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("GitRepository %s/%s did not fetch commit %s: %w", opts.namespace, opts.name, shortSHA(sha), ctx.Err())
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// deployTargets resolves the Kustomizations to deploy: the listed ones, or
+// all that apply the GitRepository except suspended ones
+func deployTargets(ctx context.Context, cluster *events.Cluster, list, namespace, source string) ([]events.Dependent, error) {
+	if list != "" {
+		var targets []events.Dependent
+		for _, ref := range splitList(list) {
+			target := events.Dependent{Namespace: namespace, Name: ref}
+			if ns, name, ok := strings.Cut(ref, "/"); ok {
+				target = events.Dependent{Namespace: ns, Name: name}
+			}
+			targets = append(targets, target)
+		}
+		return targets, nil
+	}
+
+	dependents, err := cluster.DependentKustomizations(ctx, events.SourceRef{Kind: "git", APIKind: "GitRepository", Namespace: namespace, Name: source})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the Kustomizations applying %s/%s: %w", namespace, source, err)
+	}
+	var targets []events.Dependent
+	for _, d := range dependents {
+		if d.Suspended {
+			output.PrintWarning(fmt.Sprintf("Skipping suspended kustomization %s/%s", d.Namespace, d.Name))
+			continue
+		}
+		targets = append(targets, d)
+	}
+	return targets, nil
+}
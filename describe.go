@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const describeUsage = `Usage: flux-enhanced-cli describe <kind> <name> [options]
+       flux-enhanced-cli describe <kind>/<name> [options]
+
+Shows a resource in one readable report: its readiness, the highlights of its
+spec (interval, path or chart, source, dependencies, suspension), every
+condition with its age, the revisions it last applied, attempted or fetched,
+a HelmRelease's release history and the most recent events.
+
+Kinds: kustomization (ks), helmrelease (hr), gitrepository, ocirepository,
+bucket, helmrepository, helmchart, terraform (tf)
+`
+
+// describeCommand implements "describe <kind> <name>"
+func describeCommand(args []string) int {
+	fs := flag.NewFlagSet("describe", flag.ContinueOnError)
+	namespace := fs.String("namespace", "flux-system", "Namespace")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace (shorthand)")
+	maxEvents := fs.Int("events", 10, "Number of recent events shown (0 for all)")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, describeUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+	if len(positional) == 1 && strings.Contains(positional[0], "/") {
+		positional = strings.SplitN(positional[0], "/", 2)
+	}
+	if len(positional) != 2 {
+		fs.Usage()
+		return 1
+	}
+	kinds, err := getKindsFor(positional[0])
+	if err == nil && len(kinds) > 1 {
+		err = fmt.Errorf("'%s' names several kinds, give the kind of %s", positional[0], positional[1])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	kind, name := kinds[0], positional[1]
+
+	cluster, err := events.NewCluster(*clientOpts)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	handleInterrupts(cancel)
+
+	description, err := cluster.Describe(ctx, kind, *namespace, name, *maxEvents)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	printDescription(description, kind)
+	return 0
+}
+
+// printDescription prints the report of describe, section by section
+func printDescription(d *events.Description, kind string) {
+	obj := d.Object
+	summary := events.Summarize(obj, kind)
+	status := "⏳ " + summary.Ready
+	switch {
+	case summary.Suspended:
+		status = "⏸️  Suspended"
+	case summary.Ready == "True":
+		status = output.ColorGreen + "✅ Ready" + output.ColorReset
+	case summary.Ready == "False":
+		status = output.ColorRed + "❌ Not Ready" + output.ColorReset
+	}
+	if summary.Message != "" {
+		status += ": " + summary.Message
+	}
+	generation := strconv.FormatInt(obj.GetGeneration(), 10)
+	if observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration"); found {
+		generation += fmt.Sprintf(" (observed %d)", observed)
+	}
+	printFields([]events.DescribeField{
+		{Name: "Name", Value: obj.GetName()},
+		{Name: "Namespace", Value: obj.GetNamespace()},
+		{Name: "Kind", Value: fmt.Sprintf("%s (%s)", obj.GetKind(), obj.GetAPIVersion())},
+		{Name: "Created", Value: formatAge(time.Since(summary.Created)) + " ago"},
+		{Name: "Generation", Value: generation},
+		{Name: "Status", Value: status},
+	})
+
+	printSection("Spec")
+	printFields(d.Spec)
+
+	if len(d.Revisions) > 0 {
+		printSection("Revisions")
+		printFields(d.Revisions)
+	}
+
+	printSection("Conditions")
+	if len(d.Conditions) == 0 {
+		fmt.Println("<none>")
+	} else {
+		rows := make([][]string, 0, len(d.Conditions))
+		for _, c := range d.Conditions {
+			age := "-"
+			if !c.LastTransition.IsZero() {
+				age = formatAge(time.Since(c.LastTransition))
+			}
+			rows = append(rows, []string{c.Type, c.Status, c.Reason, age, c.Message})
+		}
+		output.PrintTable([]string{"TYPE", "STATUS", "REASON", "AGE", "MESSAGE"}, rows)
+	}
+
+	if len(d.History) > 0 {
+		printSection("History")
+		printHelmHistory(d.History)
+	}
+
+	printSection("Events")
+	switch {
+	case d.EventsErr != nil:
+		fmt.Printf("Could not list events: %v\n", d.EventsErr)
+	case len(d.Events) == 0:
+		fmt.Println("<none>")
+	default:
+		rows := make([][]string, 0, len(d.Events))
+		for _, e := range d.Events {
+			age := formatAge(time.Since(e.Last))
+			if e.Count > 1 {
+				age = fmt.Sprintf("%s (x%d)", age, e.Count)
+			}
+			rows = append(rows, []string{e.Type, e.Reason, age, e.Message})
+		}
+		output.PrintTable([]string{"TYPE", "REASON", "AGE", "MESSAGE"}, rows)
+	}
+}
+
+// printSection prints the title of a section of describe
+func printSection(title string) {
+	fmt.Printf("\n%s%s:%s\n", output.ColorBold, title, output.ColorReset)
+}
+
+// printFields prints label: value lines with the values aligned
+func printFields(fields []events.DescribeField) {
+	width := 0
+	for _, f := range fields {
+		width = max(width, len(f.Name)+1)
+	}
+	for _, f := range fields {
+		fmt.Printf("%-*s %s\n", width, f.Name+":", f.Value)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+const devUsage = `Usage: flux-enhanced-cli dev --path <dir> --kustomization <name> [options]
+
+Watches a local manifest directory inside a git checkout. Whenever files
+change, it waits until a commit with the change is pushed (or pushes it with
+--push-command), reconciles the Kustomization with its source and waits until
+the pushed commit is applied. Stop it with Ctrl+C.
+`
+
+// devCommand implements "dev", an inner loop for editing the manifests of a
+// Kustomization
+func devCommand(args []string) int {
+	fs := flag.NewFlagSet("dev", flag.ContinueOnError)
+	path := fs.String("path", "", "Local directory with the Kustomization's manifests, inside a git checkout")
+	kustomization := fs.String("kustomization", "", "Name of the Kustomization to reconcile")
+	namespace := fs.String("namespace", "flux-system", "Namespace of the Kustomization")
+	pushCommand := fs.String("push-command", "", "Shell command run in --path after each change to commit and push it, e.g. 'git commit -qam wip && git push' (default: wait for you to push)")
+	debounce := fs.Duration("debounce", 2*time.Second, "React once no file changed for this long")
+	pollInterval := fs.Duration("poll-interval", time.Second, "How often --path is scanned for changes and the checkout for a push")
+	pushTimeout := fs.Duration("push-timeout", 10*time.Minute, "How long to wait for a change to be pushed")
+	timeout := fs.Duration("timeout", 5*time.Minute, "Timeout for each reconcile")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	expandErrors := fs.Bool("expand-errors", false, "Print long condition and event messages in full instead of a preview")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, devUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+	if _, err := parseArgs(fs, args); err != nil {
+		return 1
+	}
+	if *path == "" || *kustomization == "" {
+		fmt.Fprintf(os.Stderr, "Error: --path and --kustomization are required\n")
+		return 1
+	}
+	if *debounce < 0 || *pollInterval <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --poll-interval must be positive and --debounce not negative\n")
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+	if *expandErrors {
+		output.ExpandErrors()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handleInterrupts(cancel)
+
+	snapshot, err := scanFiles(*path)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	deployed, err := gitOutput(ctx, *path, "rev-parse", "HEAD")
+	if err != nil {
+		output.PrintError(fmt.Sprintf("%s is not in a git checkout: %v", *path, err))
+		return 1
+	}
+
+	opts := reconcileOptions{
+		kind:                  "kustomization",
+		name:                  *kustomization,
+		namespace:             *namespace,
+		wait:                  true,
+		timeout:               *timeout,
+		client:                *clientOpts,
+		requireSourceRevision: true,
+	}
+	output.PrintMain("👀", fmt.Sprintf("Watching %s for kustomization %s/%s, press Ctrl+C to stop", *path, *namespace, *kustomization), output.ColorCyan)
+	for {
+		changed, err := waitForFileChanges(ctx, *path, &snapshot, *pollInterval, *debounce)
+		if ctx.Err() != nil {
+			return 0
+		}
+		if err != nil {
+			output.PrintError(err.Error())
+			return 1
+		}
+		output.PrintMain("✏️", fmt.Sprintf("Changed: %s", strings.Join(changed, ", ")), output.ColorBlue)
+
+		if *pushCommand != "" {
+			cmd := shellCommand(ctx, *pushCommand)
+			cmd.Dir = *path
+			cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+			output.PrintCommand(*pushCommand)
+			if err := cmd.Run(); err != nil && ctx.Err() == nil {
+				output.PrintWarning(fmt.Sprintf("Push command failed: %v", err))
+			}
+		}
+		commit, err := waitForPush(ctx, *path, deployed, *pollInterval, *pushTimeout)
+		if ctx.Err() != nil {
+			return 0
+		}
+		if err != nil {
+			output.PrintWarning(fmt.Sprintf("Not reconciling: %v", err))
+			continue
+		}
+		deployed = commit
+
+		reconcileCtx, reconcileCancel := context.WithTimeout(ctx, *timeout)
+		result := runReconcile(reconcileCtx, opts)
+		reconcileCancel()
+		if ctx.Err() != nil {
+			return 0
+		}
+		switch {
+		case !result.Success:
+			output.PrintError(fmt.Sprintf("Commit %s was not applied: %s", shortSHA(commit), result.Message))
+		case !strings.Contains(result.Revision, commit):
+			// A newer commit pushed meanwhile is fine, as long as it was applied
+			output.PrintWarning(fmt.Sprintf("Applied revision %s instead of commit %s", result.Revision, shortSHA(commit)))
+		default:
+			output.PrintMain("✅", fmt.Sprintf("Commit %s applied in %s", shortSHA(commit), result.Duration.Round(time.Second)), output.ColorGreen)
+		}
+		output.PrintMain("👀", fmt.Sprintf("Watching %s", *path), output.ColorCyan)
+	}
+}
+
+// fileState is what scanFiles compares to notice a changed file
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// scanFiles records the size and modification time of the files below dir,
+// skipping .git
+func scanFiles(dir string) (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			// Removed while walking
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files[rel] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	return files, err
+}
+
+// changedFiles lists the files added, modified or removed between two scans
+func changedFiles(before, after map[string]fileState) []string {
+	var changed []string
+	for path, state := range after {
+		if prev, ok := before[path]; !ok || prev != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// waitForFileChanges scans dir until files changed and then stayed unchanged
+// for debounce. It returns the files changed since *snapshot, which it
+// replaces with the final scan.
+func waitForFileChanges(ctx context.Context, dir string, snapshot *map[string]fileState, interval, debounce time.Duration) ([]string, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := *snapshot
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		current, err := scanFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(changedFiles(last, current)) > 0 {
+			last, lastChange = current, time.Now()
+			continue
+		}
+		if lastChange.IsZero() || time.Since(lastChange) < debounce {
+			continue
+		}
+		changed := changedFiles(*snapshot, current)
+		*snapshot = current
+		if len(changed) == 0 {
+			// Changed back to how it was
+			lastChange = time.Time{}
+			continue
+		}
+		return changed, nil
+	}
+}
+
+// waitForPush waits until the checkout's HEAD moved past deployed and was
+// pushed to its upstream branch, returning the new commit
+func waitForPush(ctx context.Context, dir, deployed string, interval, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	waiting := false
+	for {
+		head, err := gitOutput(ctx, dir, "rev-parse", "HEAD")
+		if err != nil {
+			return "", err
+		}
+		upstream, err := gitOutput(ctx, dir, "rev-parse", "@{upstream}")
+		if err != nil {
+			return "", fmt.Errorf("no upstream branch to wait for: %w", err)
+		}
+		if head != deployed && head == upstream {
+			output.PrintSublog(fmt.Sprintf("⬆️  Commit %s is pushed", shortSHA(head)))
+			return head, nil
+		}
+		if !waiting {
+			output.PrintSublog("⏳ Waiting for the change to be committed and pushed...")
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", fmt.Errorf("nothing was pushed within %s", timeout)
+			}
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// gitOutput runs git in dir and returns its trimmed output
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", strings.TrimPrefix(lastLine(msg), "fatal: "))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// checkDrift server-side dry-runs the local build of the Kustomization again
+// after it was applied. Any object the apply would still create or change has
+// drifted right away, e.g. a field mutated by another controller or webhook,
+// and is reported as a warning. The check is refused when the checkout isn't
+// at the applied commit, since every difference between the two would show up
+// as drift.
+func checkDrift(ctx context.Context, opts reconcileOptions, applied string) error {
+	out := output.FromContext(ctx)
+	if err := checkDriftRevision(ctx, opts.path, applied); err != nil {
+		return err
+	}
+	objects, err := buildObjects(ctx, opts)
+	if err != nil {
+		return err
+	}
+	cluster, err := events.NewCluster(opts.client)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	failed := 0
+	for _, r := range cluster.DryRunApply(ctx, objects) {
+		switch r.Action {
+		case events.DryRunCreated:
+			rows = append(rows, []string{r.Object.String(), "missing"})
+		case events.DryRunConfigured:
+			rows = append(rows, []string{r.Object.String(), strings.Join(r.Changes, ", ")})
+		case events.DryRunFailed:
+			out.PrintWarning(fmt.Sprintf("Drift check of %s failed: %s", r.Object, output.Preview(r.Err.Error())))
+			failed++
+		}
+	}
+	if len(rows) == 0 {
+		if failed == 0 {
+			out.PrintSublog(fmt.Sprintf("✅ No drift: the %d applied objects match the build", len(objects)))
+		}
+		return nil
+	}
+	out.PrintWarning(fmt.Sprintf("%d objects drifted from what was applied", len(rows)))
+	out.PrintTable([]string{"OBJECT", "DRIFT"}, rows)
+	return nil
+}
+
+// checkDriftRevision verifies that the git checkout of path is at the commit
+// of the applied revision. Revisions that aren't git commits (e.g. OCI
+// digests) can't be compared and pass.
+func checkDriftRevision(ctx context.Context, path, applied string) error {
+	sha := commitSHA(applied)
+	if len(sha) != 40 {
+		return nil
+	}
+	head, err := gitOutput(ctx, path, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("can't tell the commit of %s: %v", path, err)
+	}
+	if head != sha {
+		return fmt.Errorf("%s is at commit %s but %s was applied, check out that commit to compare them", path, shortSHA(head), shortSHA(sha))
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/tracing"
+)
+
+// --dry-run modes
+const (
+	// dryRunClient prints the planned actions without contacting Flux
+	dryRunClient = "client"
+	// dryRunServer dry-run applies a Kustomization's local build
+	dryRunServer = "server"
+)
+
+// runDryRun builds the Kustomization from opts.path and server-side dry-run
+// applies the result, reporting which objects would change. Nothing is
+// reconciled or written to the cluster.
+func runDryRun(ctx context.Context, opts reconcileOptions) report.Result {
+	ctx, span := tracing.Start(ctx, "dry-run",
+		"flux.kind", opts.kind, "flux.name", opts.name, "flux.namespace", opts.namespace)
+	defer span.End()
+	out := output.FromContext(ctx)
+
+	startTime := time.Now()
+	result := report.Result{
+		Kind:      opts.kind,
+		Name:      opts.name,
+		Namespace: opts.namespace,
+		Context:   opts.client.Context,
+	}
+	fail := func(message string) report.Result {
+		span.SetError(errors.New(message))
+		out.PrintError(message)
+		result.ExitCode = 1
+		result.Message = message
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	out.PrintMain("🧪", fmt.Sprintf("Server-side dry-run of kustomization %s/%s from %s", opts.namespace, opts.name, opts.path), output.ColorCyan)
+	objects, err := buildObjects(ctx, opts)
+	if err != nil {
+		return fail(err.Error())
+	}
+	cluster, err := events.NewCluster(opts.client)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	results := cluster.DryRunApply(ctx, objects)
+	counts := map[string]int{}
+	var rows [][]string
+	for _, r := range results {
+		counts[r.Action]++
+		detail := strings.Join(r.Changes, ", ")
+		if r.Err != nil {
+			detail = output.Preview(r.Err.Error())
+		}
+		rows = append(rows, []string{r.Object.String(), r.Action, detail})
+	}
+	if len(rows) > 0 {
+		out.PrintTable([]string{"OBJECT", "ACTION", "CHANGES"}, rows)
+	}
+
+	summary := fmt.Sprintf("%d created, %d configured, %d unchanged",
+		counts[events.DryRunCreated], counts[events.DryRunConfigured], counts[events.DryRunUnchanged])
+	if counts[events.DryRunFailed] > 0 {
+		return fail(fmt.Sprintf("Dry-run failed for %d objects (%s)", counts[events.DryRunFailed], summary))
+	}
+	out.PrintMain("✅", "Dry-run: "+summary, output.ColorGreen)
+	result.Success = true
+	result.Duration = time.Since(startTime)
+	return result
+}
+
+// printPlan prints the commands and patches the run would execute, the
+// resolved API version and the wait criteria, without executing anything.
+// The cluster is only read, to resolve the API version and the source.
+func printPlan(ctx context.Context, opts reconcileOptions) report.Result {
+	out := output.FromContext(ctx)
+	result := report.Result{
+		Kind:      opts.kind,
+		Name:      opts.name,
+		Namespace: opts.namespace,
+		Context:   opts.client.Context,
+		Success:   true,
+	}
+
+	monitorKind := opts.kind
+	if opts.kind == "source" {
+		monitorKind = opts.sourceType
+	}
+	out.PrintMain("📝", fmt.Sprintf("Plan for %s %s/%s (dry-run, nothing is executed)", opts.kind, opts.namespace, opts.name), output.ColorCyan)
+	if opts.client.Context != "" {
+		out.PrintSublog("Context: " + opts.client.Context)
+	}
+	if opts.client.As != "" {
+		impersonating := "Impersonating: " + opts.client.As
+		if len(opts.client.AsGroups) > 0 {
+			impersonating += " (groups: " + strings.Join(opts.client.AsGroups, ", ") + ")"
+		}
+		out.PrintSublog(impersonating)
+	}
+	out.PrintSublog("Namespace: " + opts.namespace)
+	if opts.preHook != "" {
+		out.PrintSublog("Pre-hook: " + opts.preHook)
+	}
+	if opts.postHook != "" {
+		out.PrintSublog("Post-hook: " + opts.postHook)
+	}
+
+	cluster, err := events.NewCluster(opts.client)
+	if opts.gvr != nil {
+		out.PrintSublog(fmt.Sprintf("Resource: %s (%s)", opts.gvr.GroupResource(), opts.gvr.GroupVersion()))
+	} else if err == nil {
+		var gvr schema.GroupVersionResource
+		if gvr, err = cluster.ResolveKind(monitorKind); err == nil {
+			out.PrintSublog(fmt.Sprintf("Resource: %s (%s)", gvr.GroupResource(), gvr.GroupVersion()))
+		}
+	}
+	if err != nil {
+		out.PrintWarning(fmt.Sprintf("Could not resolve the API version: %v", err))
+	}
+
+	out.PrintSublog("Trigger:")
+	if opts.viaReceiver != "" {
+		base, _, _ := strings.Cut(opts.receiverURL, "/hook/")
+		out.PrintSublog(fmt.Sprintf("  POST %s/hook/... (webhook of receiver %s, which reconciles its resources)", strings.TrimSuffix(base, "/"), opts.viaReceiver))
+	} else if nativeTrigger(opts) {
+		if !opts.skipSource && cluster != nil && (monitorKind == "kustomization" || monitorKind == "helmrelease" || monitorKind == "terraform") {
+			if source, err := cluster.Source(ctx, opts.kind, opts.namespace, opts.name); err == nil {
+				patch, _ := events.ReconcileRequestPatch(false)
+				out.PrintSublog(fmt.Sprintf("  patch %s -n %s --type merge -p '%s'", source.APIKind+"/"+source.Name, source.Namespace, patch))
+				out.PrintSublog("  wait until the source has handled the request and is Ready")
+			} else {
+				out.PrintWarning(fmt.Sprintf("Could not resolve the source: %v", err))
+			}
+		}
+		patch, _ := events.ReconcileRequestPatch(opts.force)
+		out.PrintSublog(fmt.Sprintf("  patch %s/%s -n %s --type merge -p '%s'", monitorKind, opts.name, opts.namespace, patch))
+	} else {
+		out.PrintSublog("  " + strings.Join(fluxReconcileArgs(opts), " "))
+	}
+	if opts.retries > 0 {
+		out.PrintSublog(fmt.Sprintf("  retried up to %d times with backoff from %s to %s", opts.retries, retryBaseDelay, retryMaxDelay))
+	}
+	if opts.path != "" {
+		out.PrintSublog(fmt.Sprintf("  preceded by a prune preview of %q (confirmation: %t)", opts.path, opts.confirmPrune))
+	}
+
+	if !opts.wait {
+		out.PrintSublog("Wait: none (--wait=false)")
+		return result
+	}
+	out.PrintSublog(fmt.Sprintf("Wait (timeout %s, checked every %s):", opts.timeout, opts.intervals.Poll))
+	readyConditions := opts.readyConditions
+	if len(readyConditions) == 0 {
+		readyConditions = events.DefaultReadyConditions
+	}
+	names := make([]string, len(readyConditions))
+	for i, c := range readyConditions {
+		names[i] = c.String()
+	}
+	if len(opts.waitFor) == 0 || len(opts.readyConditions) > 0 {
+		out.PrintSublog(fmt.Sprintf("  %s with status.observedGeneration equal to metadata.generation", strings.Join(names, ", ")))
+	}
+	for _, e := range opts.waitFor {
+		out.PrintSublog("  " + e.String())
+	}
+	if opts.gvr != nil {
+		out.PrintSublog("  status.lastHandledReconcileAt equal to the requestedAt token, if the controller records it")
+	} else if nativeTrigger(opts) {
+		out.PrintSublog("  status.lastHandledReconcileAt equal to the requestedAt token")
+	}
+	if opts.requireNewArtifact && opts.kind == "source" {
+		out.PrintSublog("  status.artifact.revision different from the revision before the trigger")
+	}
+	if opts.requireSourceRevision && opts.kind == "kustomization" {
+		out.PrintSublog("  status.lastAppliedRevision equal to the source's status.artifact.revision")
+	}
+	if opts.digest != "" {
+		out.PrintSublog("  status.artifact.digest equal to " + opts.digest)
+	}
+	if opts.kind == "helmrelease" {
+		out.PrintSublog("  Helm tests passed, when tests are enabled")
+	}
+	if opts.healthCheck == healthCheckInventory {
+		out.PrintSublog("  every inventory object Current (kstatus)")
+	}
+	if opts.settle > 0 {
+		out.PrintSublog(fmt.Sprintf("  still ready %s later", opts.settle))
+	}
+	if opts.checkDrift && opts.kind == "kustomization" {
+		out.PrintSublog("  then a server-side dry-run of the build from " + opts.path + " to report drifted objects (warnings only)")
+	}
+	if len(opts.failOnEvents) > 0 {
+		out.PrintSublog("  aborted on events: " + strings.Join(opts.failOnEvents, ", "))
+	}
+	if opts.forceAfter > 0 {
+		out.PrintSublog(fmt.Sprintf("  re-triggered after %s without events or condition changes (force: %t)", opts.forceAfter, opts.retriggerForce))
+	}
+	return result
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const exportUsage = `Usage: flux-enhanced-cli export <kind> <name> [options]
+       flux-enhanced-cli export <kind>/<name> [options]
+       flux-enhanced-cli export [<kind>] --all [options]
+
+Prints the live resource as served by the API server, status included, for
+backups or to attach to a support ticket. With --all every resource of the
+kind in the namespace is exported, or every Flux resource without a kind:
+YAML documents are separated by "---", JSON is a List.
+
+Kinds: kustomization (ks), helmrelease (hr), gitrepository, ocirepository,
+bucket, helmrepository, helmchart, terraform (tf), alert, provider, receiver,
+imagerepository, imagepolicy, imageupdateautomation, sources
+`
+
+// exportOnlyKinds are the Flux kinds export handles beyond the monitor kinds
+var exportOnlyKinds = []string{"alert", "provider", "receiver", "imagerepository", "imagepolicy", "imageupdateautomation"}
+
+// exportKinds are the kinds exported by a bare "export --all"
+var exportKinds = append(append(append([]string(nil), getKinds...), "terraform"), exportOnlyKinds...)
+
+// exportKindAliases maps the names of the kinds only export handles, in
+// singular and plural
+var exportKindAliases = map[string]string{
+	"alert":                  "alert",
+	"alerts":                 "alert",
+	"provider":               "provider",
+	"providers":              "provider",
+	"receiver":               "receiver",
+	"receivers":              "receiver",
+	"imagerepository":        "imagerepository",
+	"imagerepositories":      "imagerepository",
+	"imagepolicy":            "imagepolicy",
+	"imagepolicies":          "imagepolicy",
+	"imageupdateautomation":  "imageupdateautomation",
+	"imageupdateautomations": "imageupdateautomation",
+}
+
+// exportKindsFor maps a kind argument to the kinds to export, accepting the
+// notification and image automation kinds besides the ones of get
+func exportKindsFor(kind string) ([]string, error) {
+	if exportKind, ok := exportKindAliases[strings.ToLower(kind)]; ok {
+		return []string{exportKind}, nil
+	}
+	return getKindsFor(kind)
+}
+
+// exportCommand implements "export <kind> <name>"
+func exportCommand(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	namespace := fs.String("namespace", "flux-system", "Namespace")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace (shorthand)")
+	all := fs.Bool("all", false, "Export every resource of the kind (every Flux resource without a kind) in the namespace")
+	format := fs.String("output", "yaml", "Output format: yaml or json")
+	fs.StringVar(format, "o", "yaml", "Shorthand for --output")
+	stripManagedFields := fs.Bool("strip-managed-fields", false, "Leave out metadata.managedFields")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, exportUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *format != "yaml" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: invalid output format '%s'. Valid formats: yaml, json\n", *format)
+		return 1
+	}
+	if len(positional) == 1 && strings.Contains(positional[0], "/") {
+		positional = strings.SplitN(positional[0], "/", 2)
+	}
+	kinds := exportKinds
+	var name string
+	switch {
+	case *all && len(positional) > 1:
+		fmt.Fprintln(os.Stderr, "Error: --all exports every resource, it can't be combined with a name")
+		return 1
+	case !*all && len(positional) != 2:
+		fs.Usage()
+		return 1
+	}
+	if len(positional) > 0 {
+		if kinds, err = exportKindsFor(positional[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if len(positional) > 1 {
+			name = positional[1]
+		}
+	}
+	if name != "" && len(kinds) > 1 {
+		fmt.Fprintf(os.Stderr, "Error: '%s' names several kinds, give the kind of %s\n", positional[0], name)
+		return 1
+	}
+
+	cluster, err := events.NewCluster(*clientOpts)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	handleInterrupts(cancel)
+
+	var objects []unstructured.Unstructured
+	if name != "" {
+		obj, err := cluster.GetResource(ctx, kinds[0], *namespace, name)
+		if err != nil {
+			output.PrintError(err.Error())
+			return 1
+		}
+		objects = append(objects, *obj)
+	} else {
+		for _, kind := range kinds {
+			// Exporting several kinds skips the ones this cluster doesn't serve
+			if len(kinds) > 1 {
+				if _, err := cluster.ResolveKind(kind); err != nil {
+					continue
+				}
+			}
+			items, err := cluster.ListResources(ctx, events.ListOptions{Kind: kind, Namespaces: []string{*namespace}})
+			if err != nil {
+				output.PrintError(err.Error())
+				return 1
+			}
+			objects = append(objects, items...)
+		}
+		if len(objects) == 0 {
+			fmt.Fprintf(os.Stderr, "No resources found in %s namespace.\n", *namespace)
+			return 0
+		}
+	}
+
+	if *stripManagedFields {
+		for i := range objects {
+			objects[i].SetManagedFields(nil)
+		}
+	}
+	if err := writeExport(os.Stdout, objects, *format, name == ""); err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	return 0
+}
+
+// writeExport prints the objects as YAML documents or JSON, wrapping them in
+// a List when several were asked for
+func writeExport(w io.Writer, objects []unstructured.Unstructured, format string, list bool) error {
+	if format == "json" {
+		var v interface{} = objects[0].Object
+		if list {
+			items := make([]interface{}, len(objects))
+			for i := range objects {
+				items[i] = objects[i].Object
+			}
+			v = map[string]interface{}{"apiVersion": "v1", "kind": "List", "items": items}
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	for i := range objects {
+		data, err := yaml.Marshal(objects[i].Object)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(w, "---")
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// fluxLogSymbols are the prefixes the flux CLI logger puts on its lines
+var fluxLogSymbols = []string{"►", "✔", "✗", "◎", "⚠️", "✚"}
+
+// renderFluxLine re-renders a line of the flux CLI's log through out, with a
+// timestamp and the tool's colors and indentation. It reports false for lines
+// that aren't flux log lines, which the caller passes through.
+func renderFluxLine(out *output.Printer, line string) bool {
+	trimmed := strings.TrimSpace(line)
+	var symbol string
+	for _, s := range fluxLogSymbols {
+		if strings.HasPrefix(trimmed, s) {
+			symbol = s
+			break
+		}
+	}
+	if symbol == "" {
+		return false
+	}
+	message := strings.TrimSpace(strings.TrimPrefix(trimmed, symbol))
+	stamp := time.Now().Format("15:04:05")
+	switch symbol {
+	case "✗":
+		out.PrintError(fmt.Sprintf("flux: %s", message))
+	case "⚠️":
+		out.PrintWarning(fmt.Sprintf("flux: %s", message))
+	case "✔":
+		out.PrintSublog(fmt.Sprintf("%s ✅ %s", stamp, message))
+	case "◎":
+		out.PrintSublog(fmt.Sprintf("%s ⏳ %s", stamp, message))
+	default:
+		out.PrintSublog(fmt.Sprintf("%s %s %s", stamp, symbol, message))
+	}
+	return true
+}
+
+// fluxLogWriter receives the flux CLI's output, re-rendering its log lines
+// and passing other lines through to passthrough
+type fluxLogWriter struct {
+	out         *output.Printer
+	passthrough io.Writer
+	mu          sync.Mutex
+	buf         bytes.Buffer
+}
+
+func newFluxLogWriter(out *output.Printer) *fluxLogWriter {
+	return &fluxLogWriter{out: out, passthrough: out.Stdout()}
+}
+
+func (w *fluxLogWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(data)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(data), nil
+		}
+		line := string(w.buf.Next(i + 1))
+		if !renderFluxLine(w.out, strings.TrimRight(line, "\r\n")) {
+			fmt.Fprint(w.passthrough, line)
+		}
+	}
+}
+
+// Flush passes through a last line without a newline
+func (w *fluxLogWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf.Len() == 0 {
+		return
+	}
+	line := w.buf.String()
+	w.buf.Reset()
+	if !renderFluxLine(w.out, line) {
+		fmt.Fprintln(w.passthrough, line)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// followResource keeps printing the resource's events and condition changes
+// after a successful run until Ctrl+C. It outlives --timeout, which only
+// bounds the run itself.
+func followResource(ctx context.Context, opts reconcileOptions, monitor *events.Monitor) {
+	out := output.FromContext(ctx)
+	followCtx, stop := signal.NotifyContext(context.WithoutCancel(ctx), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	out.PrintStatus(fmt.Sprintf("Following %s/%s, press Ctrl+C to stop", opts.kind, opts.name))
+	if err := monitor.Follow(followCtx); err != nil {
+		out.PrintWarning(fmt.Sprintf("Stopped following: %v", err))
+	}
+}
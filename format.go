@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+)
+
+// parseOutputFormat parses --output: json, go-template=<template> or
+// go-template-file=<path>
+func parseOutputFormat(spec string) (*template.Template, error) {
+	format, arg, _ := strings.Cut(spec, "=")
+	var text string
+	switch format {
+	case "json":
+		// One result object per line, the schema --result-file uses
+		return template.New("output").Funcs(template.FuncMap{"json": toJSON}).Parse("{{json .}}")
+	case "go-template":
+		text = arg
+	case "go-template-file":
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the output template: %w", err)
+		}
+		text = string(data)
+	default:
+		return nil, fmt.Errorf("invalid output format '%s'. Valid formats: json, go-template=<template>, go-template-file=<path>", spec)
+	}
+	if text == "" {
+		return nil, fmt.Errorf("--output %s needs a template", format)
+	}
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// printResults writes every result through the template to stdout, each
+// ending with a newline
+func printResults(tmpl *template.Template, results []report.Result) error {
+	for _, r := range results {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, r); err != nil {
+			return fmt.Errorf("failed to render the output template: %w", err)
+		}
+		text := b.String()
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		fmt.Print(text)
+	}
+	return nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
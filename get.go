@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+const getUsage = `Usage: flux-enhanced-cli get [<kind> [<name>]] [options]
+       flux-enhanced-cli get <kind>/<name> [options]
+
+Lists Flux resources with their readiness, revision and suspension. Without
+a kind all Kustomizations, HelmReleases and sources are listed; "sources"
+lists the sources only.
+
+With --watch the table stays on screen and is updated in place as resources
+change (when not on a terminal, changed rows are printed as they change).
+
+Kinds: kustomization (ks), helmrelease (hr), gitrepository, ocirepository,
+bucket, helmrepository, helmchart, sources
+`
+
+// getKinds are the monitor kinds listed by a bare "get", in order
+var getKinds = []string{"kustomization", "helmrelease", "git", "oci", "bucket", "helmrepository", "helmchart"}
+
+// getSourceKinds are the monitor kinds listed by "get sources"
+var getSourceKinds = []string{"git", "oci", "bucket", "helmrepository", "helmchart"}
+
+// getKindAliases adds the kinds only "get" lists to resourceKindAliases
+var getKindAliases = map[string]string{
+	"helmrepository":   "helmrepository",
+	"helmrepositories": "helmrepository",
+	"helmrepo":         "helmrepository",
+	"helmchart":        "helmchart",
+	"helmcharts":       "helmchart",
+}
+
+// getCommand implements "get [<kind> [<name>]]"
+func getCommand(args []string) int {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	namespace := fs.String("namespace", "flux-system", "Namespace")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace (shorthand)")
+	allNamespaces := fs.Bool("all-namespaces", false, "List resources in all namespaces")
+	fs.BoolVar(allNamespaces, "A", false, "Shorthand for --all-namespaces")
+	selector := fs.String("selector", "", "Only list resources matching this label selector")
+	fs.StringVar(selector, "l", "", "Shorthand for --selector")
+	format := fs.String("output", "", "Output format: wide adds the source and interval columns")
+	fs.StringVar(format, "o", "", "Shorthand for --output")
+	watch := fs.Bool("watch", false, "Keep the table on screen and update it as resources change")
+	fs.BoolVar(watch, "w", false, "Shorthand for --watch")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, getUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+	if *format != "" && *format != "wide" {
+		fmt.Fprintf(os.Stderr, "Error: invalid output format '%s'. Valid formats: wide\n", *format)
+		return 1
+	}
+	if len(positional) > 2 {
+		fs.Usage()
+		return 1
+	}
+
+	// Accept kind/name like kubectl
+	if len(positional) == 1 && strings.Contains(positional[0], "/") {
+		positional = strings.SplitN(positional[0], "/", 2)
+	}
+	kinds := getKinds
+	var name string
+	if len(positional) > 0 {
+		if kinds, err = getKindsFor(positional[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if len(positional) > 1 {
+			name = positional[1]
+		}
+	}
+
+	listOpts := events.ListOptions{LabelSelector: *selector}
+	if !*allNamespaces {
+		listOpts.Namespaces = []string{*namespace}
+	}
+	if name != "" {
+		listOpts.FieldSelector = "metadata.name=" + name
+	}
+
+	cluster, err := events.NewCluster(*clientOpts)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	if *watch {
+		informerOpts := events.InformerOptions{LabelSelector: listOpts.LabelSelector, FieldSelector: listOpts.FieldSelector}
+		if !*allNamespaces {
+			informerOpts.Namespace = *namespace
+		}
+		return watchResources(cluster, kinds, informerOpts, *allNamespaces, *format == "wide")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	found, served := 0, 0
+	var resolveErr error
+	for _, kind := range kinds {
+		// Listing several kinds skips the ones this cluster doesn't serve
+		if len(kinds) > 1 {
+			if _, err := cluster.ResolveKind(kind); err != nil {
+				resolveErr = err
+				continue
+			}
+		}
+		served++
+		listOpts.Kind = kind
+		items, err := cluster.ListResources(ctx, listOpts)
+		if err != nil {
+			output.PrintError(err.Error())
+			return 1
+		}
+		if len(items) == 0 {
+			continue
+		}
+		summaries := make([]events.ResourceSummary, len(items))
+		for i := range items {
+			summaries[i] = events.Summarize(&items[i], kind)
+		}
+		if found > 0 {
+			output.Println()
+		}
+		found += len(items)
+		printGetTable(summaries, len(kinds) > 1, *allNamespaces, *format == "wide")
+	}
+
+	if served == 0 {
+		output.PrintError(resolveErr.Error())
+		return 1
+	}
+	if found == 0 {
+		if *allNamespaces {
+			fmt.Fprintln(os.Stderr, "No resources found")
+		} else {
+			fmt.Fprintf(os.Stderr, "No resources found in %s namespace.\n", *namespace)
+		}
+	}
+	return 0
+}
+
+// getKindsFor maps a kind argument to the monitor kinds to list
+func getKindsFor(kind string) ([]string, error) {
+	kind = strings.ToLower(kind)
+	switch kind {
+	case "all":
+		return getKinds, nil
+	case "source", "sources":
+		return getSourceKinds, nil
+	}
+	if monitorKind, ok := resourceKindAliases[kind]; ok {
+		return []string{monitorKind}, nil
+	}
+	if monitorKind, ok := getKindAliases[kind]; ok {
+		return []string{monitorKind}, nil
+	}
+	return nil, fmt.Errorf("unsupported kind '%s'", kind)
+}
+
+// printGetTable prints resources of one kind the way kubectl does, naming
+// them kind/name when several kinds are listed
+func printGetTable(summaries []events.ResourceSummary, withKind, withNamespace, wide bool) {
+	output.PrintTable(getRows(summaries, withKind, withNamespace, wide))
+}
+
+// getRows returns the table headers and a row per resource
+func getRows(summaries []events.ResourceSummary, withKind, withNamespace, wide bool) ([]string, [][]string) {
+	headers := []string{"NAME", "READY", "REVISION", "SUSPENDED", "AGE"}
+	if wide {
+		headers = append(headers, "SOURCE", "INTERVAL")
+	}
+	headers = append(headers, "STATUS")
+	if withNamespace {
+		headers = append([]string{"NAMESPACE"}, headers...)
+	}
+
+	now := time.Now()
+	rows := make([][]string, 0, len(summaries))
+	for _, s := range summaries {
+		name := s.Name
+		if withKind {
+			name = strings.ToLower(s.Kind) + "/" + s.Name
+		}
+		row := []string{name, s.Ready, s.Revision, fmt.Sprintf("%t", s.Suspended), formatAge(now.Sub(s.Created))}
+		if wide {
+			row = append(row, s.Source, s.Interval)
+		}
+		// Messages are cut to the terminal width, so keep them on one line
+		row = append(row, strings.Join(strings.Fields(s.Message), " "))
+		if withNamespace {
+			row = append([]string{s.Namespace}, row...)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+// formatAge formats an age like kubectl: 45s, 12m, 5h, 3d
+func formatAge(d time.Duration) string {
+	switch {
+	case d < 2*time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < 2*time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+const graphUsage = `Usage: flux-enhanced-cli graph [options]
+
+Renders the topology of Kustomizations and HelmReleases: what each depends on
+(spec.dependsOn) and the source it reconciles from. The output is an ASCII
+tree, or a DOT (Graphviz) or Mermaid graph with --output.
+
+Examples:
+  flux-enhanced-cli graph -A
+  flux-enhanced-cli graph -A -o dot | dot -Tsvg > flux.svg
+  flux-enhanced-cli graph -n apps -o mermaid
+`
+
+// Graph output formats
+const (
+	graphASCII   = "ascii"
+	graphDOT     = "dot"
+	graphMermaid = "mermaid"
+)
+
+// graphCommand implements "graph"
+func graphCommand(args []string) int {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	namespace := fs.String("namespace", "flux-system", "Namespace")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace (shorthand)")
+	allNamespaces := fs.Bool("all-namespaces", false, "Include resources in all namespaces")
+	fs.BoolVar(allNamespaces, "A", false, "Shorthand for --all-namespaces")
+	selector := fs.String("selector", "", "Only include resources matching this label selector")
+	fs.StringVar(selector, "l", "", "Shorthand for --selector")
+	format := fs.String("output", graphASCII, "Output format: ascii, dot or mermaid")
+	fs.StringVar(format, "o", graphASCII, "Shorthand for --output")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, graphUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(positional) > 0 {
+		fs.Usage()
+		return 1
+	}
+	var write func(io.Writer, *events.Graph)
+	switch *format {
+	case graphASCII:
+		write = writeGraphTree
+	case graphDOT:
+		write = writeGraphDOT
+	case graphMermaid:
+		write = writeGraphMermaid
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid output format '%s'. Valid formats: ascii, dot, mermaid\n", *format)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	handleInterrupts(cancel)
+
+	cluster, err := events.NewCluster(*clientOpts)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	listOpts := events.ListOptions{LabelSelector: *selector}
+	if !*allNamespaces {
+		listOpts.Namespaces = []string{*namespace}
+	}
+	graph, err := cluster.DependencyGraph(ctx, listOpts)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	if len(graph.Nodes) == 0 {
+		fmt.Fprintln(os.Stderr, "No Kustomizations or HelmReleases found")
+		return 0
+	}
+	write(os.Stdout, graph)
+	return 0
+}
+
+// graphState is the short readiness of a node: ready, failed, progressing,
+// suspended, or unlisted for referenced objects that weren't listed, which
+// may exist outside the namespaces graphed
+func graphState(n events.GraphNode) string {
+	switch {
+	case !n.Listed:
+		return "unlisted"
+	case n.Suspended:
+		return "suspended"
+	case n.Ready == "True":
+		return "ready"
+	case n.Ready == "False":
+		return "failed"
+	}
+	return "progressing"
+}
+
+// isSource reports whether the node is only referenced as a source
+func isSource(g *events.Graph, id string) bool {
+	for _, e := range g.Edges {
+		if e.To == id && e.Type == events.EdgeSource {
+			return true
+		}
+	}
+	return false
+}
+
+// writeGraphTree prints the graph as trees: objects without dependencies at
+// the top, with the objects that depend on them below, and each object's
+// source after an arrow. An object with several dependencies appears under
+// each of them, with its dependents only the first time.
+func writeGraphTree(w io.Writer, g *events.Graph) {
+	dependents := map[string][]string{}
+	sources := map[string]string{}
+	hasDependencies := map[string]bool{}
+	for _, e := range g.Edges {
+		switch e.Type {
+		case events.EdgeDependsOn:
+			dependents[e.To] = append(dependents[e.To], e.From)
+			hasDependencies[e.From] = true
+		case events.EdgeSource:
+			sources[e.From] = e.To
+		}
+	}
+	marks := map[string]string{
+		"ready":       "✅",
+		"failed":      "❌",
+		"progressing": "⏳",
+		"suspended":   "⏸️ suspended",
+		"unlisted":    "❔ not listed",
+	}
+
+	expanded := map[string]bool{}
+	var visit func(id, prefix, connector string, path map[string]bool)
+	visit = func(id, prefix, connector string, path map[string]bool) {
+		node, _ := g.Node(id)
+		line := fmt.Sprintf("%s%s%s %s", prefix, connector, id, marks[graphState(node)])
+		if source := sources[id]; source != "" {
+			line += "  ← " + source
+		}
+		switch {
+		case path[id]:
+			fmt.Fprintln(w, line+"  (cycle)")
+			return
+		case expanded[id] && len(dependents[id]) > 0:
+			fmt.Fprintln(w, line+"  (see above)")
+			return
+		}
+		fmt.Fprintln(w, line)
+		expanded[id] = true
+
+		path[id] = true
+		defer delete(path, id)
+		switch connector {
+		case "├── ":
+			prefix += "│   "
+		case "└── ":
+			prefix += "    "
+		}
+		children := dependents[id]
+		for i, child := range children {
+			next := "├── "
+			if i == len(children)-1 {
+				next = "└── "
+			}
+			visit(child, prefix, next, path)
+		}
+	}
+
+	first := true
+	root := func(id string) {
+		if !first {
+			fmt.Fprintln(w)
+		}
+		first = false
+		visit(id, "", "", map[string]bool{})
+	}
+	for _, n := range g.Nodes {
+		if !hasDependencies[n.ID()] && (n.Listed || len(dependents[n.ID()]) > 0) && !isSource(g, n.ID()) {
+			root(n.ID())
+		}
+	}
+	// Objects whose dependencies only form cycles have no root above them
+	for _, n := range g.Nodes {
+		if n.Listed && !expanded[n.ID()] {
+			root(n.ID())
+		}
+	}
+}
+
+// dotColors are the Graphviz colors of the node states
+var dotColors = map[string]string{
+	"ready":       "forestgreen",
+	"failed":      "firebrick",
+	"progressing": "darkorange",
+	"suspended":   "gray50",
+	"unlisted":    "gray50",
+}
+
+// writeGraphDOT prints the graph in the Graphviz DOT language. Sources are
+// drawn as cylinders, source edges dashed.
+func writeGraphDOT(w io.Writer, g *events.Graph) {
+	fmt.Fprintln(w, "digraph flux {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box, style=rounded, fontname=Helvetica];")
+	fmt.Fprintln(w, "  edge [fontname=Helvetica, fontsize=10];")
+	for _, n := range g.Nodes {
+		state := graphState(n)
+		attrs := []string{
+			fmt.Sprintf("label=%q", fmt.Sprintf("%s\n%s/%s", n.Kind, n.Namespace, n.Name)),
+			fmt.Sprintf("color=%s", dotColors[state]),
+		}
+		switch {
+		case isSource(g, n.ID()):
+			attrs = append(attrs, "shape=cylinder", "style=solid")
+		case state == "unlisted" || state == "suspended":
+			attrs = append(attrs, `style="rounded,dashed"`)
+		}
+		fmt.Fprintf(w, "  %q [%s];\n", n.ID(), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		if e.Type == events.EdgeSource {
+			fmt.Fprintf(w, "  %q -> %q [style=dashed];\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(w, "  %q -> %q [label=%q];\n", e.From, e.To, e.Type)
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// mermaidStyles are the Mermaid class definitions of the node states
+var mermaidStyles = []string{
+	"classDef ready stroke:#2da44e,stroke-width:2px",
+	"classDef failed stroke:#cf222e,stroke-width:2px",
+	"classDef progressing stroke:#bf8700,stroke-width:2px",
+	"classDef suspended stroke:#8c959f,stroke-dasharray:4",
+	"classDef unlisted stroke:#8c959f,stroke-dasharray:4",
+}
+
+// writeGraphMermaid prints the graph as a Mermaid flowchart, which GitHub and
+// GitLab render in Markdown. Sources are drawn as cylinders, source edges
+// dotted.
+func writeGraphMermaid(w io.Writer, g *events.Graph) {
+	ids := make(map[string]string, len(g.Nodes))
+	fmt.Fprintln(w, "flowchart LR")
+	for i, n := range g.Nodes {
+		ids[n.ID()] = fmt.Sprintf("n%d", i)
+		label := fmt.Sprintf("%s<br/>%s/%s", n.Kind, n.Namespace, n.Name)
+		if isSource(g, n.ID()) {
+			fmt.Fprintf(w, "  %s[(\"%s\")]\n", ids[n.ID()], label)
+			continue
+		}
+		fmt.Fprintf(w, "  %s[\"%s\"]:::%s\n", ids[n.ID()], label, graphState(n))
+	}
+	for _, e := range g.Edges {
+		if e.Type == events.EdgeSource {
+			fmt.Fprintf(w, "  %s -.-> %s\n", ids[e.From], ids[e.To])
+		} else {
+			fmt.Fprintf(w, "  %s -->|%s| %s\n", ids[e.From], e.Type, ids[e.To])
+		}
+	}
+	for _, style := range mermaidStyles {
+		fmt.Fprintln(w, "  "+style)
+	}
+}
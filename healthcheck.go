@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// Supported --health-check modes
+const (
+	healthCheckNone      = ""
+	healthCheckInventory = "inventory"
+)
+
+// checkInventoryHealth waits until every object in the Kustomization's
+// inventory is Current by the kstatus rules, or ctx expires. It fails as soon
+// as an object is Failed. Warning events of the objects and their pods are
+// streamed meanwhile, and logs and events of crash looping pods are printed as
+// they are found (up to logLines lines per container).
+func checkInventoryHealth(ctx context.Context, monitor *events.Monitor, logLines int) error {
+	out := output.FromContext(ctx)
+	out.PrintSublog("🩺 Checking inventory objects...")
+
+	if objects, err := monitor.Inventory(); err == nil {
+		watchCtx, stopWatch := context.WithCancel(ctx)
+		defer stopWatch()
+		go monitor.WatchInventoryEvents(watchCtx, objects)
+	}
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	reported := map[string]bool{}
+	var workloads []events.WorkloadStatus
+	for {
+		var err error
+		workloads, err = monitor.InventoryHealth(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check inventory objects: %w", err)
+		}
+		for _, w := range workloads {
+			output.RedactNames(w.Object.Name)
+			output.RedactNamespaces(w.Object.Namespace)
+			if w.Object.IsWorkload() && (w.Status == events.StatusInProgress || w.Status == events.StatusFailed) {
+				reportCrashingPods(ctx, monitor, w.Object, logLines, reported)
+			}
+		}
+
+		pending, failed := unhealthyWorkloads(workloads)
+		if len(failed) > 0 {
+			printWorkloads(out, workloads)
+			return fmt.Errorf("objects failed: %s", strings.Join(failed, ", "))
+		}
+		if len(pending) == 0 {
+			printWorkloads(out, workloads)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			printWorkloads(out, workloads)
+			return fmt.Errorf("objects not healthy: %s", strings.Join(pending, ", "))
+		case <-ticker.C:
+			out.PrintStatus(fmt.Sprintf("Waiting for %d of %d objects to become Current", len(pending), len(workloads)))
+		}
+	}
+}
+
+// reportCrashingPods prints the log tail and warning events of the workload's
+// crash looping containers, once per container and reason.
+func reportCrashingPods(ctx context.Context, monitor *events.Monitor, o events.InventoryObject, logLines int, reported map[string]bool) {
+	out := output.FromContext(ctx)
+	problems, err := monitor.WorkloadPodProblems(ctx, o)
+	if err != nil {
+		return
+	}
+
+	for _, p := range problems {
+		output.RedactNames(p.Pod)
+		key := p.Pod + "/" + p.Container + "/" + p.Reason
+		if reported[key] {
+			continue
+		}
+		reported[key] = true
+
+		out.StartGroup(fmt.Sprintf("%s: pod %s container %s", p.Reason, p.Pod, p.Container))
+		out.PrintWarning(fmt.Sprintf("%s: pod %s, container %s is in %s", o, p.Pod, p.Container, p.Reason))
+		if p.Message != "" {
+			out.PrintSublog("  " + output.Preview(p.Message))
+		}
+
+		if logLines > 0 && p.Reason == "CrashLoopBackOff" {
+			if lines, err := monitor.PodLogs(ctx, p, int64(logLines)); err != nil {
+				out.PrintSublog(fmt.Sprintf("  (logs unavailable: %v)", err))
+			} else if len(lines) > 0 {
+				out.PrintSublog(fmt.Sprintf("  Last %d log lines:", len(lines)))
+				for _, line := range lines {
+					out.PrintSublog("    " + line)
+				}
+			}
+		}
+
+		if podEvents, err := monitor.PodWarningEvents(ctx, p.Namespace, p.Pod); err != nil {
+			out.PrintSublog(fmt.Sprintf("  (events unavailable: %v)", err))
+		} else if len(podEvents) > 0 {
+			out.PrintSublog("  Pod events:")
+			for _, evt := range podEvents {
+				out.PrintSublog("    " + evt)
+			}
+		}
+		out.EndGroup()
+	}
+}
+
+func unhealthyWorkloads(workloads []events.WorkloadStatus) ([]string, []string) {
+	var pending, failed []string
+	for _, w := range workloads {
+		switch w.Status {
+		case events.StatusCurrent:
+		case events.StatusFailed, events.StatusNotFound:
+			failed = append(failed, w.Object.String())
+		default:
+			pending = append(pending, w.Object.String())
+		}
+	}
+	return pending, failed
+}
+
+// printWorkloads lists workloads and unhealthy objects; other Current objects
+// are only counted
+func printWorkloads(out *output.Printer, workloads []events.WorkloadStatus) {
+	if len(workloads) == 0 {
+		out.PrintSublog("No objects with a status in the inventory")
+		return
+	}
+	others := 0
+	for _, w := range workloads {
+		switch {
+		case w.Status != events.StatusCurrent:
+			out.PrintWarning(fmt.Sprintf("%s is %s: %s", w.Object, w.Status, output.Preview(w.Message)))
+		case w.Object.IsWorkload():
+			out.PrintSublog(fmt.Sprintf("✅ %s (%s)", w.Object, w.Message))
+		default:
+			others++
+		}
+	}
+	if others > 0 {
+		out.PrintSublog(fmt.Sprintf("✅ %d other objects Current", others))
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// checkHelmTests waits for the TestSuccess condition of a HelmRelease with
+// tests enabled. When tests failed, the failed test hooks are listed with the
+// tail of their pod logs. Releases without tests pass immediately.
+func checkHelmTests(ctx context.Context, monitor *events.Monitor, logLines int) error {
+	out := output.FromContext(ctx)
+	ticker := time.NewTicker(monitor.PollInterval())
+	defer ticker.Stop()
+
+	announced := false
+	for {
+		tests, err := monitor.HelmTests(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read Helm test results: %w", err)
+		}
+		if !tests.Enabled {
+			return nil
+		}
+		if !announced {
+			out.PrintSublog("🧪 Checking Helm test results...")
+			announced = true
+		}
+
+		switch tests.Status {
+		case "True":
+			out.PrintSublog(fmt.Sprintf("✅ Helm tests passed (%d hooks)", len(tests.Hooks)))
+			return nil
+		case "False":
+			return reportFailedTests(ctx, monitor, tests, logLines)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for Helm test results")
+		case <-ticker.C:
+		}
+	}
+}
+
+// reportHelmTestFailures lists failed test hooks after a HelmRelease failed
+// to become Ready. The run's context may have expired by then, so a short
+// one of its own is used.
+func reportHelmTestFailures(ctx context.Context, monitor *events.Monitor, logLines int) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 15*time.Second)
+	defer cancel()
+	tests, err := monitor.HelmTests(ctx)
+	if err != nil || !tests.Enabled || tests.Status != "False" {
+		return
+	}
+	_ = reportFailedTests(ctx, monitor, tests, logLines)
+}
+
+func reportFailedTests(ctx context.Context, monitor *events.Monitor, tests *events.HelmTests, logLines int) error {
+	out := output.FromContext(ctx)
+	if tests.Message != "" {
+		out.PrintWarning(output.Preview(tests.Message))
+	}
+
+	var failed []string
+	for _, hook := range tests.Hooks {
+		output.RedactNames(hook.Name)
+		if hook.Phase == "Succeeded" {
+			out.PrintSublog(fmt.Sprintf("✅ %s", hook.Name))
+			continue
+		}
+		failed = append(failed, hook.Name)
+
+		out.StartGroup(fmt.Sprintf("Helm test %s", hook.Name))
+		out.PrintWarning(fmt.Sprintf("Test %s: %s", hook.Name, hook.Phase))
+		if logLines > 0 {
+			if lines, err := monitor.HelmTestLogs(ctx, hook, int64(logLines)); err != nil {
+				out.PrintSublog(fmt.Sprintf("  (logs unavailable: %v)", err))
+			} else if len(lines) > 0 {
+				out.PrintSublog(fmt.Sprintf("  Last %d log lines:", len(lines)))
+				for _, line := range lines {
+					out.PrintSublog("    " + line)
+				}
+			}
+		}
+		out.EndGroup()
+	}
+
+	if len(failed) == 0 {
+		return fmt.Errorf("Helm tests failed")
+	}
+	return fmt.Errorf("Helm tests failed: %s", strings.Join(failed, ", "))
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/config"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+)
+
+// loadHintRules reads the custom hints of the config file. A missing file is
+// only an error when it was given explicitly.
+func loadHintRules(path string, explicit bool) ([]events.HintRule, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil, nil
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return hintRules(cfg.Hints)
+}
+
+// hintRules compiles the hints of a config file
+func hintRules(hints []config.Hint) ([]events.HintRule, error) {
+	var rules []events.HintRule
+	for _, h := range hints {
+		rule, err := events.NewHintRule(h.Pattern, h.Hint, h.Kinds)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
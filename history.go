@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/history"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/notify"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+)
+
+const historyUsage = `Usage: flux-enhanced-cli history [<kind> [<name>]] [options]
+
+Lists the runs recorded by this tool, newest first: when each resource was
+reconciled, to which revision, how long it took and whether it succeeded.
+Give a kind, optionally with a name, to only list those resources.
+
+For "helmrelease <name>" it shows the release's Helm revisions with their
+chart versions, statuses and deploy times instead, from status.history or the
+Helm release secrets (use --runs for the recorded runs).
+`
+
+// historyCommand implements "history [<kind> [<name>]]" and
+// "history helmrelease <name>"
+func historyCommand(args []string) int {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	namespace := fs.String("namespace", "flux-system", "Namespace")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace (shorthand)")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	redactNames := fs.Bool("redact-names", false, "Replace resource names, namespaces and URLs in all output with hashed tokens")
+	runs := fs.Bool("runs", false, "List the recorded runs of a HelmRelease instead of its Helm revisions")
+	limit := fs.Int("limit", 20, "Maximum number of recorded runs listed (0 for all)")
+	historyFile := fs.String("history-file", history.DefaultPath(), "File the runs are recorded in")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, historyUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+
+	filter := history.Filter{Limit: *limit}
+	if len(positional) > 0 {
+		var err error
+		if filter.Kind, _, filter.Name, err = parseResourceArgs(positional); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fs.Usage()
+			return 1
+		}
+	}
+	if filter.Kind != "helmrelease" || filter.Name == "" || *runs {
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "namespace" || f.Name == "n" {
+				filter.Namespace = *namespace
+			}
+		})
+		if *redactNames {
+			output.EnableRedaction()
+			output.RedactNames(filter.Name)
+			output.RedactNamespaces(filter.Namespace)
+		}
+		return printRunHistory(*historyFile, filter)
+	}
+	name := filter.Name
+
+	if *redactNames {
+		output.EnableRedaction()
+		output.RedactNames(name, clientOpts.Context, clientOpts.As)
+		output.RedactNamespaces(*namespace)
+	}
+
+	ctx := context.Background()
+	monitor, err := events.NewMonitor(ctx, *clientOpts, "helmrelease", name, *namespace)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	defer monitor.Stop()
+
+	history, err := monitor.HelmHistory(ctx)
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Failed to read release history: %v", err))
+		return 1
+	}
+	printHelmHistory(history)
+	return 0
+}
+
+// printRunHistory lists the recorded runs matching filter
+func printRunHistory(path string, filter history.Filter) int {
+	runs, err := history.Load(path, filter)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	if len(runs) == 0 {
+		output.PrintStatus("No recorded runs")
+		return 0
+	}
+
+	rows := make([][]string, 0, len(runs))
+	for _, r := range runs {
+		output.RedactNames(r.Name, r.Context)
+		output.RedactNamespaces(r.Namespace)
+		resource := fmt.Sprintf("%s/%s/%s", r.Kind, r.Namespace, r.Name)
+		if r.Namespace == "" {
+			// Releases span namespaces
+			resource = r.Kind + "/" + r.Name
+		}
+		if r.Context != "" {
+			resource = r.Context + ":" + resource
+		}
+		result := "Succeeded"
+		if !r.Success {
+			result = "Failed"
+		}
+		rows = append(rows, []string{
+			r.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			resource,
+			r.Revision,
+			r.Duration.Round(time.Second).String(),
+			result,
+			r.Message,
+		})
+	}
+	output.PrintTable([]string{"STARTED", "RESOURCE", "REVISION", "DURATION", "RESULT", "MESSAGE"}, rows)
+	return 0
+}
+
+func printHelmHistory(history []events.HelmReleaseSnapshot) {
+	rows := make([][]string, 0, len(history))
+	for _, s := range history {
+		rows = append(rows, []string{strconv.FormatInt(s.Revision, 10), s.Status, s.ChartName + "-" + s.ChartVersion, s.AppVersion, s.Deployed})
+	}
+	output.PrintTable([]string{"REVISION", "STATUS", "CHART", "APP VERSION", "DEPLOYED"}, rows)
+}
+
+// runRecords returns the run records of the reconciled resources of results
+func runRecords(results []report.Result) []history.Run {
+	runs := make([]history.Run, 0, len(results))
+	for _, r := range results {
+		// Skipped resources and post-checks were never reconciled
+		if r.Skipped || r.StartedAt.IsZero() {
+			continue
+		}
+		runs = append(runs, history.FromResult(r))
+	}
+	return runs
+}
+
+// recordRuns appends runs to the run history at path; an empty path disables
+// recording
+func recordRuns(path string, runs []history.Run) {
+	if path == "" || len(runs) == 0 {
+		return
+	}
+	if err := history.Append(path, runs...); err != nil {
+		output.PrintWarning(fmt.Sprintf("Could not record the run: %v", err))
+	}
+}
+
+// notifyRuns posts the outcome of each run that changed from the previous
+// runs of its resource in the run history at path, marking the runs notified
+func notifyRuns(path string, notifier *notify.Notifier, runs []history.Run) {
+	for i := range runs {
+		run := &runs[i]
+		previous, err := history.Load(path, history.Filter{Kind: run.Kind, Name: run.Name, Namespace: run.Namespace})
+		if err != nil {
+			output.PrintWarning(fmt.Sprintf("Could not send notification: %v", err))
+			continue
+		}
+		previous = slices.DeleteFunc(previous, func(r history.Run) bool { return r.Context != run.Context })
+		key := fmt.Sprintf("%s/%s/%s", run.Kind, run.Namespace, run.Name)
+		if run.Namespace == "" {
+			key = run.Kind + "/" + run.Name
+		}
+		if run.Context != "" {
+			key = run.Context + "/" + key
+		}
+		if sent, err := notifier.Notify(context.Background(), key, previous, run); err != nil {
+			output.PrintWarning(fmt.Sprintf("Could not send notification: %v", err))
+		} else if sent {
+			output.PrintStatus("Notification sent")
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+)
+
+// Hook phases, passed to hooks as RECONCILE_PHASE
+const (
+	hookPre  = "pre"
+	hookPost = "post"
+)
+
+// runHook runs a hook command through the shell with the environment
+// describing the target and, for post-hooks, the outcome. Its output is
+// passed through like flux's.
+func runHook(ctx context.Context, phase, command string, opts reconcileOptions, result *report.Result) error {
+	out := output.FromContext(ctx)
+	out.PrintSublog(fmt.Sprintf("Running %s-hook", phase))
+	out.PrintCommand(command)
+
+	cmd := shellCommand(ctx, command)
+	cmd.Stdout = out.Stdout()
+	cmd.Stderr = out.Stderr()
+	cmd.Env = append(os.Environ(), hookEnv(phase, opts, result)...)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s-hook failed: %w", phase, err)
+	}
+	return nil
+}
+
+// shellCommand runs script through the platform's shell
+func shellCommand(ctx context.Context, script string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return command(ctx, "cmd", "/C", script)
+	}
+	return command(ctx, "sh", "-c", script)
+}
+
+// hookEnv describes the reconciled resource (and the outcome) to hooks
+func hookEnv(phase string, opts reconcileOptions, result *report.Result) []string {
+	env := []string{
+		"RECONCILE_PHASE=" + phase,
+		"RECONCILE_KIND=" + opts.kind,
+		"RECONCILE_NAME=" + opts.name,
+		"RECONCILE_NAMESPACE=" + opts.namespace,
+		"RECONCILE_CONTEXT=" + opts.client.Context,
+	}
+	if opts.kind == "source" {
+		env = append(env, "RECONCILE_SOURCE_TYPE="+opts.sourceType)
+	}
+	if result != nil {
+		outcome := "success"
+		if !result.Success {
+			outcome = "failure"
+		}
+		env = append(env,
+			"RECONCILE_RESULT="+outcome,
+			"RECONCILE_REVISION="+result.Revision,
+			"RECONCILE_DURATION="+strconv.Itoa(int(result.Duration.Seconds())),
+			"RECONCILE_MESSAGE="+result.Message,
+		)
+	}
+	return env
+}
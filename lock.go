@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// acquireLock takes the Lease named after the resource in its namespace
+// (--lock), waiting up to opts.lockTimeout while another run holds it
+func acquireLock(ctx context.Context, opts reconcileOptions) (*events.Lock, error) {
+	out := output.FromContext(ctx)
+	kind := opts.kind
+	if kind == "source" {
+		kind = opts.sourceType
+	}
+	cluster, err := events.NewCluster(opts.client)
+	if err != nil {
+		return nil, err
+	}
+	name := events.LockName(kind, opts.name)
+	lock, err := cluster.AcquireLock(ctx, opts.namespace, name, events.LockOptions{
+		TTL:      opts.lockTTL,
+		Wait:     opts.lockTimeout,
+		Progress: out.PrintStatus,
+		Lost:     out.PrintWarning,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.PrintStatus(fmt.Sprintf("Acquired lock %s/%s", opts.namespace, name))
+	return lock, nil
+}
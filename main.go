@@ -7,17 +7,26 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"os/signal"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
+	"text/template"
 	"time"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/config"
 	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/history"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/notify"
 	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/tracing"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/webhook"
 )
 
 // Version information (set at build time with -ldflags)
@@ -29,35 +38,204 @@ var (
 // Kubernetes client warning pattern: W1123 13:40:53.387945   52532 warnings.go:70] message
 var kubernetesWarningRegex = regexp.MustCompile(`^W\d+\s+\d+:\d+:\d+\.\d+\s+\d+\s+\S+:\d+\]\s+(.+)$`)
 
-func processStderr(reader io.Reader, wg *sync.WaitGroup) {
+// processStderr passes through the flux binary's stderr, re-rendering its log
+// lines and the API warnings klog writes there. The native client path gets them through
+// a rest.WarningHandler instead (see events.PrintAPIWarning). onFluxLine, when
+// set, is called with each flux log line after it was rendered.
+func processStderr(reader io.Reader, out *output.Printer, suppressWarnings bool, onFluxLine func(string), wg *sync.WaitGroup) {
 	defer wg.Done()
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		// Check if this is a Kubernetes client warning
+		// Check if this is a Kubernetes client warning; flux logs its
+		// progress to stderr too, other output is passed through as-is
 		if matches := kubernetesWarningRegex.FindStringSubmatch(line); matches != nil {
-			// Format the warning nicely
-			output.PrintWarning(matches[1])
+			events.PrintAPIWarning(out, matches[1], suppressWarnings)
+		} else if renderFluxLine(out, line) {
+			if onFluxLine != nil {
+				onFluxLine(line)
+			}
 		} else if strings.TrimSpace(line) != "" {
-			// Pass through other stderr output as-is
-			fmt.Fprintf(os.Stderr, "%s\n", line)
+			fmt.Fprintf(out.Stderr(), "%s\n", line)
 		}
 	}
+	// Keep draining after a read error (e.g. an overlong line) so the
+	// writer never blocks
+	_, _ = io.Copy(io.Discard, reader)
 }
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "release":
+			os.Exit(releaseCommand(os.Args[2:]))
+		case "sync-flux":
+			os.Exit(syncFluxCommand(os.Args[2:]))
+		case "is-ready":
+			os.Exit(isReadyCommand(os.Args[2:]))
+		case "wait-until-ready":
+			os.Exit(waitUntilReadyCommand(os.Args[2:]))
+		case "rollback":
+			os.Exit(rollbackCommand(os.Args[2:]))
+		case "history":
+			os.Exit(historyCommand(os.Args[2:]))
+		case "stats":
+			os.Exit(statsCommand(os.Args[2:]))
+		case "serve":
+			os.Exit(serveCommand(os.Args[2:]))
+		case "get":
+			os.Exit(getCommand(os.Args[2:]))
+		case "version":
+			os.Exit(versionCommand(os.Args[2:]))
+		case "dev":
+			os.Exit(devCommand(os.Args[2:]))
+		case "build":
+			os.Exit(buildCommand(os.Args[2:]))
+		case "deploy":
+			os.Exit(deployCommand(os.Args[2:]))
+		case "notify":
+			os.Exit(notifyCommand(os.Args[2:]))
+		case "wait":
+			os.Exit(waitCommand(os.Args[2:]))
+		case "graph":
+			os.Exit(graphCommand(os.Args[2:]))
+		case "export":
+			os.Exit(exportCommand(os.Args[2:]))
+		case "describe":
+			os.Exit(describeCommand(os.Args[2:]))
+		}
+	}
+
 	var (
-		kind       = flag.String("kind", "", "Resource kind (kustomization, helmrelease, source)")
-		name       = flag.String("name", "", "Resource name")
-		namespace  = flag.String("namespace", "flux-system", "Namespace")
-		wait       = flag.Bool("wait", true, "Wait for reconciliation to complete")
-		timeout    = flag.Duration("timeout", 5*time.Minute, "Timeout for waiting (e.g., 5m, 1h)")
-		version    = flag.Bool("version", false, "Print version information and exit")
-		noColor    = flag.Bool("no-color", false, "Disable colored output")
-		sourceType = flag.String("source-type", "git", "Source type for 'source' kind (git, oci)")
+		kind         = flag.String("kind", "", "Resource kind (kustomization, helmrelease, source, terraform)")
+		name         = flag.String("name", "", "Resource name")
+		namespace    = flag.String("namespace", "flux-system", "Namespace")
+		wait         = flag.Bool("wait", true, "Wait for reconciliation to complete")
+		timeout      = flag.Duration("timeout", 5*time.Minute, "Timeout for the whole run, and for waiting unless --wait-timeout is set (e.g., 5m, 1h)")
+		version      = flag.Bool("version", false, "Print version information and exit")
+		noColor      = flag.Bool("no-color", false, "Disable colored output")
+		redactNames  = flag.Bool("redact-names", false, "Replace resource names, namespaces and URLs in all output with hashed tokens")
+		expandErrors = flag.Bool("expand-errors", false, "Print long condition and event messages in full instead of a preview")
+		sourceType   = flag.String("source-type", "git", "Source type for 'source' kind (git, oci, bucket)")
+		ciMode       = flag.String("ci-mode", "", "Emit CI workflow commands for annotations and groups (github)")
+		junitReport  = flag.String("junit-report", "", "Write a JUnit XML report of the reconciled resources to this path")
+		resultFile   = flag.String("result-file", "", "Write the results as a JSON array (the schema of -o json) to this path, whatever the console output")
+		outputFormat = flag.String("output", "", "Print each result as JSON or with a Go template (json, go-template=<template> or go-template-file=<path>); logs go to stderr")
+
+		triggerTimeout = flag.Duration("trigger-timeout", 5*time.Minute, "Timeout for each attempt to request the reconcile (flux reconcile, the annotation or the receiver call)")
+		waitTimeout    = flag.Duration("wait-timeout", 0, "Timeout for waiting for readiness after the trigger (defaults to --timeout)")
+		deadline       = flag.String("deadline", "", "Wall-clock time the whole run must end by, as RFC 3339 (2024-05-01T14:00:00Z) or a local time (14:00, tomorrow's once passed)")
+
+		lock        = flag.Bool("lock", false, "Hold a Lease named after the resource while reconciling, so concurrent runs on it take turns")
+		lockTimeout = flag.Duration("lock-timeout", 5*time.Minute, "How long to wait for the --lock Lease while another run holds it")
+		lockTTL     = flag.Duration("lock-ttl", time.Minute, "How long a --lock Lease stays valid without being renewed before others take it over")
+
+		notifyURL      = flag.String("notify-url", "", "Webhook URL to notify when the outcome changes from the previous run")
+		notifyFailures = flag.Int("notify-failures", 1, "Consecutive failures required before a failure is notified")
+		historyFile    = flag.String("history-file", history.DefaultPath(), "File recording every run for the history command (empty disables)")
+
+		contexts           = flag.String("contexts", "", "Comma-separated kubeconfig contexts to reconcile in concurrently")
+		requireNewArtifact = flag.Bool("require-new-artifact", false, "For sources, require a new artifact revision since the trigger, not just Ready")
+		requireSourceRev   = flag.Bool("require-source-revision", false, "For kustomizations, after Ready wait until status.lastAppliedRevision equals the source's artifact revision")
+		recursive          = flag.Bool("recursive", false, "For kustomizations, after Ready wait until the Kustomizations and HelmReleases in its inventory, and in theirs, are Ready too")
+		healthCheck        = flag.String("health-check", "", "Extra checks after the resource is Ready (inventory: verify every Kustomization inventory object is Current)")
+		retries            = flag.Int("retries", 0, "Retry a reconcile trigger that failed transiently (timeouts, server errors, refused connections) this many times with exponential backoff")
+		ifOlderThan        = flag.Duration("if-older-than", 0, "Skip the reconcile and exit 0 when the resource reconciled successfully more recently than this (0 disables)")
+		attachIfRunning    = flag.Bool("attach-if-running", false, "When the resource is already reconciling (Reconciling=True or an unhandled request), wait for that reconcile instead of requesting another")
+		pollInterval       = flag.Duration("poll-interval", events.DefaultIntervals.Poll, "Interval between readiness checks")
+		eventInterval      = flag.Duration("event-interval", events.DefaultIntervals.Events, "Delay before re-listing events after an event watch ends")
+		statusInterval     = flag.Duration("status-interval", events.DefaultIntervals.Status, "Interval between periodic status lines while waiting")
+		hintAt             = flag.String("hint-at", "50,80", "Percentages of --timeout at which to explain what blocks the resource, with suggestions (none disables)")
+		configPath         = flag.String("config", config.DefaultPath(), "Config file whose hints: add remediation advice for failure messages (read when it exists)")
+		withSource         = flag.Bool("with-source", true, "Reconcile the source of a kustomization or helmrelease first")
+		force              = flag.Bool("force", false, "Force a HelmRelease upgrade even when chart and values are unchanged (sets reconcile.fluxcd.io/forceAt)")
+		forceAfter         = flag.Duration("force-after", 0, "Re-trigger the reconcile when no events or condition changes are seen for this long (0 disables)")
+		failFast           = flag.Bool("fail-fast", false, "Abort the wait as soon as an event with one of the --fail-on-events reasons occurs")
+		failOnEvents       = flag.String("fail-on-events", strings.Join(events.DefaultFailOnEvents, ","), "Comma-separated event reasons that abort the wait (setting it implies --fail-fast)")
+		settle             = flag.Duration("settle", 0, "After Ready, keep watching this long and fail if the resource turns not ready again (0 disables)")
+		viaReceiver        = flag.String("via-receiver", "", "Trigger the reconcile through the webhook of this notification-controller Receiver ([namespace/]name) instead of annotating")
+		receiverURL        = flag.String("receiver-url", "", "Address the Receivers are reachable at, e.g. https://flux-webhook.example.com, or the full webhook URL of a Receiver")
+		receiverType       = flag.String("receiver-type", "", "Type the --via-receiver request is signed as (generic, generic-hmac, github, gitlab, bitbucket, harbor; defaults to the Receiver's, or generic with a full webhook URL)")
+		receiverToken      = flag.String("receiver-token", os.Getenv("FLUX_ENHANCED_CLI_RECEIVER_TOKEN"), "Token the --via-receiver request is signed with instead of the one in the Receiver's secret (defaults to $FLUX_ENHANCED_CLI_RECEIVER_TOKEN)")
+		follow             = flag.Bool("follow", false, "After a successful reconcile, keep printing events and condition changes until Ctrl+C")
+		retriggerForce     = flag.Bool("retrigger-force", false, "Make --force-after re-triggers of HelmReleases force an upgrade")
+		digest             = flag.String("digest", "", "For oci and bucket sources, wait until the artifact has this digest (sha256:...)")
+		path               = flag.String("path", "", "Local path of a Kustomization's sources; previews the objects the reconcile would prune")
+		confirmPrune       = flag.Bool("confirm-prune", false, "Ask for confirmation before reconciling when objects would be pruned (requires --path)")
+		controllerLogs     = flag.Bool("controller-logs", false, "While waiting, stream the controller's log lines about the resource (e.g. helm-controller for a helmrelease)")
+		verbose            = flag.Bool("verbose", false, "While waiting, stream the kustomize-controller, source-controller and helm-controller log lines about the resource and its sources, prefixed with the controller")
+		fluxNamespace      = flag.String("flux-namespace", defaultFluxNamespace, "Namespace of the Flux controllers, for --controller-logs and --verbose")
+		driftCheck         = flag.Bool("drift-check", false, "After a kustomization is Ready, dry-run apply the build from --path again and warn about objects that drifted")
+		dryRun             = flag.String("dry-run", "", "Print the planned actions without executing them (client), or dry-run apply a Kustomization's local build (server, requires --path)")
+		logLines           = flag.Int("log-lines", 20, "Log lines shown for crash looping pods and failed Helm tests")
+
+		sequential = flag.Bool("sequential", false, "Reconcile several resources one at a time, in the order given, instead of concurrently")
+		onError    = flag.String("on-error", config.OnFailureContinue, "After a failed resource of several or of --selector: stop (skip the rest) or continue")
+		selector   = flag.String("selector", "", "Reconcile every resource of --kind matching this label selector instead of --name")
+		namespaces = flag.String("namespaces", "", "Comma-separated namespaces searched with --selector (defaults to --namespace)")
+
+		customResource  = flag.String("gvr", "", "Reconcile and wait for a custom resource of this group/version/resource instead of a Flux kind")
+		conditionStatus = flag.String("condition-status", "True", "Status required of --condition entries that don't give one (True, False, Unknown)")
+
+		allNamespaces = flag.Bool("all-namespaces", false, "Find the resource by name in any namespace, or with --selector search all namespaces")
+		batchFile     = flag.String("file", "", "Reconcile the resources listed in this YAML file (- for stdin) as a batch")
+
+		preHook             = flag.String("pre-hook", "", "Shell command run before the reconcile (RECONCILE_* variables describe the target)")
+		showAlerts          = flag.Bool("show-alerts", false, "List the notification-controller Alerts that forward the resource's events")
+		skipPermissionCheck = flag.Bool("skip-permission-check", false, "Don't verify through access reviews that the resource may be triggered and watched before triggering")
+		tenantCheck         = flag.Bool("tenant-check", false, "Before triggering, impersonate a kustomization's serviceAccountName and verify it may apply the kinds in its inventory")
+		commitInfo          = flag.Bool("commit-info", false, "After a git source reconciles, fetch its commit message and author from the origin (uses local git credentials)")
+		postHook            = flag.String("post-hook", "", "Shell command run after the reconcile, also on failure (RECONCILE_RESULT holds the outcome)")
 	)
-	flag.Parse()
+	flag.StringVar(selector, "l", "", "Shorthand for --selector")
+	flag.StringVar(batchFile, "f", "", "Shorthand for --file")
+	flag.StringVar(outputFormat, "o", "", "Shorthand for --output")
+	flag.BoolVar(allNamespaces, "A", false, "Shorthand for --all-namespaces")
+	var conditionSpecs, waitForSpecs stringList
+	flag.Var(&waitForSpecs, "wait-for", "CEL or JSONPath expression that must hold on the live object, e.g. 'status.lastAppliedRevision == \"main@sha1:abc\"' (repeatable; replaces Ready=True unless --condition is set)")
+	flag.Var(&conditionSpecs, "condition", "Condition required instead of Ready=True, as <type> or <type>=<status> (repeatable, comma-separated; all must hold)")
+	clientOpts := addClientFlags(flag.CommandLine)
+	// Unknown subcommands run the flux-enhanced-cli-<name> plugin in PATH
+	if plugin, i := findPlugin(flag.CommandLine, os.Args[1:]); plugin != "" {
+		os.Exit(runPlugin(plugin, flag.CommandLine, os.Args[1:i+1], os.Args[i+2:]))
+	}
+	positional, _ := parseArgs(flag.CommandLine, os.Args[1:])
+	// With --gvr the only argument is the name
+	if *customResource != "" && len(positional) == 1 && *name == "" {
+		*name, positional = positional[0], nil
+	}
+	// Several <kind>/<name> arguments are reconciled as a queue
+	var queue []queuedResource
+	var err error
+	if len(positional) > 0 {
+		if *kind != "" || *name != "" {
+			fmt.Fprintf(os.Stderr, "Error: a positional resource cannot be combined with --kind or --name\n")
+			os.Exit(1)
+		}
+		var isQueue bool
+		queue, isQueue, err = parseResourceQueue(positional)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !isQueue {
+			argKind, argSourceType, argName, err := parseResourceArgs(positional)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			*kind, *name = argKind, argName
+			if argSourceType != "" {
+				*sourceType = argSourceType
+			}
+		}
+	}
+	if err := applyInCluster(flag.CommandLine, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Handle --version flag
 	if *version {
@@ -69,21 +247,487 @@ func main() {
 	if *noColor || os.Getenv("NO_COLOR") != "" {
 		output.DisableColors()
 	}
+	if *expandErrors {
+		output.ExpandErrors()
+	}
+	if *redactNames {
+		output.EnableRedaction()
+		output.RedactNames(*name, clientOpts.Context, clientOpts.As)
+		output.RedactNames(splitList(*contexts)...)
+		output.RedactNamespaces(*namespace)
+		output.RedactNamespaces(splitList(*namespaces)...)
+	}
+
+	if err := output.SetCIMode(*ciMode); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var resultTemplate *template.Template
+	if *outputFormat != "" {
+		var err error
+		if resultTemplate, err = parseOutputFormat(*outputFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		output.LogToStderr()
+	}
 
-	if *kind == "" || *name == "" {
-		fmt.Fprintf(os.Stderr, "Error: --kind and --name are required\n")
-		fmt.Fprintf(os.Stderr, "\nUsage: flux-enhanced-cli --kind <kind> --name <name> [options]\n")
-		fmt.Fprintf(os.Stderr, "\nKinds: kustomization, helmrelease, source\n")
+	if queue != nil && (*selector != "" || *batchFile != "" || *contexts != "" || *allNamespaces || *customResource != "") {
+		fmt.Fprintf(os.Stderr, "Error: several resources cannot be combined with --selector, --file, --contexts, --all-namespaces or --gvr\n")
+		os.Exit(1)
+	}
+	if (*sequential || *onError != config.OnFailureContinue) && queue == nil && *selector == "" {
+		fmt.Fprintf(os.Stderr, "Error: --sequential and --on-error require several resources or --selector\n")
+		os.Exit(1)
+	}
+	if *onError != config.OnFailureStop && *onError != config.OnFailureContinue {
+		fmt.Fprintf(os.Stderr, "Error: invalid --on-error '%s'. Valid policies: stop, continue\n", *onError)
+		os.Exit(1)
+	}
+	if queue != nil && *onError == config.OnFailureStop && !*sequential {
+		fmt.Fprintf(os.Stderr, "Error: --on-error stop requires --sequential, concurrent resources all run\n")
+		os.Exit(1)
+	}
+	if *batchFile != "" && (*kind != "" || *name != "" || *selector != "" || *contexts != "" || *allNamespaces) {
+		fmt.Fprintf(os.Stderr, "Error: --file cannot be combined with a resource, --selector, --contexts or --all-namespaces\n")
+		os.Exit(1)
+	}
+	if (*batchFile != "" || queue != nil) && (*path != "" || *digest != "" || *dryRun == dryRunServer) {
+		fmt.Fprintf(os.Stderr, "Error: --path, --digest and --dry-run=server apply to a single resource and cannot be used with --file or several resources\n")
+		os.Exit(1)
+	}
+	var gvr *schema.GroupVersionResource
+	if *customResource != "" {
+		parsed, err := events.ParseGVR(*customResource)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *kind != "" || *selector != "" || *batchFile != "" || *allNamespaces || *force || *path != "" || *digest != "" || *healthCheck != "" {
+			fmt.Fprintf(os.Stderr, "Error: --gvr cannot be combined with a kind, --selector, --file, --all-namespaces, --force, --path, --digest or --health-check\n")
+			os.Exit(1)
+		}
+		if *name == "" {
+			fmt.Fprintf(os.Stderr, "Error: --gvr requires a name\n")
+			os.Exit(1)
+		}
+		gvr, *kind = &parsed, parsed.Resource
+	}
+	var conditionList []string
+	for _, spec := range conditionSpecs {
+		conditionList = append(conditionList, splitList(spec)...)
+	}
+	readyConditions, err := events.ParseConditions(conditionList, *conditionStatus)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var failOn []string
+	configGiven, timeoutGiven := false, false
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "fail-on-events":
+			*failFast = true
+		case "config":
+			configGiven = true
+		case "timeout":
+			timeoutGiven = true
+		}
+	})
+	if *failFast {
+		if failOn = splitList(*failOnEvents); len(failOn) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: --fail-on-events needs at least one event reason\n")
+			os.Exit(1)
+		}
+	}
+	hintThresholds, err := events.ParseHintThresholds(*hintAt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	hintRules, err := loadHintRules(*configPath, configGiven)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var waitFor []events.WaitExpression
+	for _, spec := range waitForSpecs {
+		expression, err := events.ParseWaitExpression(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		waitFor = append(waitFor, expression)
+	}
+	if *batchFile == "" && queue == nil && (*kind == "" || (*name == "" && *selector == "")) {
+		fmt.Fprintf(os.Stderr, "Error: a kind and a name (or --selector or --file) are required\n")
+		fmt.Fprintf(os.Stderr, "\nUsage: flux-enhanced-cli <kind> <name> [options]\n")
+		fmt.Fprintf(os.Stderr, "       flux-enhanced-cli source <git|oci|bucket> <name> [options]\n")
+		fmt.Fprintf(os.Stderr, "       flux-enhanced-cli --kind <kind> --name <name> [options]\n")
+		fmt.Fprintf(os.Stderr, "       flux-enhanced-cli --file <deploys.yaml> [options]\n")
+		fmt.Fprintf(os.Stderr, "       flux-enhanced-cli --gvr <group/version/resource> <name> [options]\n")
+		fmt.Fprintf(os.Stderr, "\nKinds: kustomization (ks), helmrelease (hr), source, gitrepository, ocirepository, bucket, terraform (tf)\n")
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	if *digest != "" && (*kind != "source" || *sourceType == "git") {
+		fmt.Fprintf(os.Stderr, "Error: --digest requires --kind source with --source-type oci or bucket\n")
+		os.Exit(1)
+	}
+	if *digest != "" && !*wait {
+		fmt.Fprintf(os.Stderr, "Error: --digest waits for the artifact and cannot be combined with --wait=false\n")
+		os.Exit(1)
+	}
+	if *pollInterval <= 0 || *eventInterval <= 0 || *statusInterval <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --poll-interval, --event-interval and --status-interval must be positive\n")
+		os.Exit(1)
+	}
+	if *force && *kind != "helmrelease" && *batchFile == "" && queue == nil {
+		fmt.Fprintf(os.Stderr, "Error: --force is only supported for --kind helmrelease\n")
+		os.Exit(1)
+	}
+	if *requireSourceRev && *kind != "kustomization" && *batchFile == "" && queue == nil {
+		fmt.Fprintf(os.Stderr, "Error: --require-source-revision is only supported for --kind kustomization\n")
+		os.Exit(1)
+	}
+	if *recursive && *kind != "kustomization" && *batchFile == "" && queue == nil {
+		fmt.Fprintf(os.Stderr, "Error: --recursive is only supported for --kind kustomization\n")
+		os.Exit(1)
+	}
+	if *tenantCheck && *kind != "kustomization" && *batchFile == "" && queue == nil {
+		fmt.Fprintf(os.Stderr, "Error: --tenant-check is only supported for --kind kustomization\n")
+		os.Exit(1)
+	}
+	if clientOpts.InCluster && *contexts != "" {
+		fmt.Fprintf(os.Stderr, "Error: --in-cluster cannot be combined with --contexts\n")
+		os.Exit(1)
+	}
+	if clientOpts.InCluster && (*path != "" || *dryRun == dryRunServer) {
+		fmt.Fprintf(os.Stderr, "Error: --path and --dry-run=server need the flux binary and are not supported with --in-cluster\n")
+		os.Exit(1)
+	}
+	if *notifyURL != "" && *historyFile == "" {
+		fmt.Fprintf(os.Stderr, "Error: --notify-url compares with the previous runs and needs --history-file\n")
+		os.Exit(1)
+	}
+	if *confirmPrune && *path == "" {
+		fmt.Fprintf(os.Stderr, "Error: --confirm-prune requires --path\n")
+		os.Exit(1)
+	}
+	if *driftCheck && (*kind != "kustomization" || *path == "") {
+		fmt.Fprintf(os.Stderr, "Error: --drift-check requires --kind kustomization and --path\n")
+		os.Exit(1)
+	}
+	if (*viaReceiver == "") != (*receiverURL == "") {
+		fmt.Fprintf(os.Stderr, "Error: --via-receiver and --receiver-url must be given together\n")
+		os.Exit(1)
+	}
+	if *receiverType != "" && !slices.Contains(webhook.ReceiverTypes, *receiverType) {
+		fmt.Fprintf(os.Stderr, "Error: invalid --receiver-type '%s'. Valid types: %s\n", *receiverType, strings.Join(webhook.ReceiverTypes, ", "))
+		os.Exit(1)
+	}
+	if receiverWebhookURL(*receiverURL) && *receiverType != "" && *receiverType != "generic" && *receiverToken == "" {
+		fmt.Fprintf(os.Stderr, "Error: a full webhook URL with --receiver-type %s requires --receiver-token\n", *receiverType)
+		os.Exit(1)
+	}
+	if *viaReceiver != "" && (*force || gvr != nil) {
+		fmt.Fprintf(os.Stderr, "Error: --via-receiver cannot be combined with --force or --gvr\n")
+		os.Exit(1)
+	}
+	if *follow && (!*wait || *selector != "" || *batchFile != "" || *contexts != "" || queue != nil) {
+		fmt.Fprintf(os.Stderr, "Error: --follow requires --wait and a single resource (no --selector, --file or --contexts)\n")
+		os.Exit(1)
+	}
+	if *dryRun != "" && *dryRun != dryRunClient && *dryRun != dryRunServer {
+		fmt.Fprintf(os.Stderr, "Error: invalid dry-run mode '%s'. Valid modes: client, server\n", *dryRun)
+		os.Exit(1)
+	}
+	if *dryRun == dryRunServer && (*kind != "kustomization" || *path == "") {
+		fmt.Fprintf(os.Stderr, "Error: --dry-run=server requires --kind kustomization and --path\n")
+		os.Exit(1)
+	}
+	if *allNamespaces && (*namespaces != "" || *contexts != "") {
+		fmt.Fprintf(os.Stderr, "Error: --all-namespaces cannot be combined with --namespaces or --contexts\n")
+		os.Exit(1)
+	}
+	if *selector != "" && (*name != "" || *contexts != "") {
+		fmt.Fprintf(os.Stderr, "Error: --selector cannot be combined with --name or --contexts\n")
+		os.Exit(1)
+	}
+
+	if *triggerTimeout <= 0 || *waitTimeout < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --trigger-timeout must be positive and --wait-timeout can't be negative\n")
+		os.Exit(1)
+	}
+	if *waitTimeout > 0 && !timeoutGiven {
+		// Without --timeout the run is bounded by its phases
+		*timeout = time.Duration(*retries+1)**triggerTimeout + *waitTimeout
+	}
+
+	if *lockTimeout < 0 || *lockTTL < 3*time.Second {
+		fmt.Fprintf(os.Stderr, "Error: --lock-timeout can't be negative and --lock-ttl must be at least 3s\n")
+		os.Exit(1)
+	}
+	if *lock && !timeoutGiven {
+		// Waiting for the lock doesn't eat into the default timeout
+		*timeout += *lockTimeout
+	}
+
+	// --deadline bounds everything, and replaces the default --timeout
+	root := context.Background()
+	cancelRoot := func() {}
+	if *deadline != "" {
+		at, err := parseDeadline(*deadline, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		remaining := time.Until(at).Round(time.Second)
+		if remaining <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --deadline %s has already passed\n", at.Format(time.RFC3339))
+			os.Exit(1)
+		}
+		if remaining < *timeout || (!timeoutGiven && *waitTimeout == 0) {
+			*timeout = remaining
+		}
+		if *waitTimeout > remaining {
+			*waitTimeout = remaining
+		}
+		if *triggerTimeout > remaining {
+			*triggerTimeout = remaining
+		}
+		root, cancelRoot = context.WithDeadline(context.Background(), at)
+	}
+	defer cancelRoot()
+
+	// Create context with timeout; with --selector, --file or several
+	// resources each resource gets its own
+	ctx, cancel := context.WithTimeout(root, *timeout)
+	if *selector != "" || *batchFile != "" || queue != nil {
+		ctx, cancel = context.WithCancel(root)
+	}
 	defer cancel()
 
-	// Handle signals with double Ctrl+C support (thread-safe)
+	handleInterrupts(cancel)
+
+	// Validate source type
+	validSourceTypes := map[string]bool{"git": true, "oci": true, "bucket": true}
+	if *kind == "source" && !validSourceTypes[*sourceType] {
+		fmt.Fprintf(os.Stderr, "Error: invalid source-type '%s'. Valid types: git, oci, bucket\n", *sourceType)
+		os.Exit(1)
+	}
+	if *healthCheck != healthCheckNone && *healthCheck != healthCheckInventory {
+		fmt.Fprintf(os.Stderr, "Error: invalid health-check '%s'. Valid checks: inventory\n", *healthCheck)
+		os.Exit(1)
+	}
+
+	flushTracing := initTracing()
+
+	opts := reconcileOptions{
+		kind:           *kind,
+		name:           *name,
+		namespace:      *namespace,
+		sourceType:     *sourceType,
+		wait:           *wait,
+		timeout:        *timeout,
+		triggerTimeout: *triggerTimeout,
+		waitTimeout:    *waitTimeout,
+		client:         *clientOpts,
+		healthCheck:    *healthCheck,
+		logLines:       *logLines,
+
+		requireNewArtifact:    *requireNewArtifact,
+		requireSourceRevision: *requireSourceRev,
+		retries:               *retries,
+		skipSource:            !*withSource,
+		force:                 *force,
+		forceAfter:            *forceAfter,
+		settle:                *settle,
+		follow:                *follow,
+		retriggerForce:        *retriggerForce,
+		digest:                *digest,
+		path:                  *path,
+		confirmPrune:          *confirmPrune,
+		checkDrift:            *driftCheck,
+		viaReceiver:           *viaReceiver,
+		receiverURL:           *receiverURL,
+		receiverType:          *receiverType,
+		receiverToken:         *receiverToken,
+		controllerLogs:        *controllerLogs,
+		verbose:               *verbose,
+		fluxNamespace:         *fluxNamespace,
+		dryRun:                *dryRun,
+		preHook:               *preHook,
+		postHook:              *postHook,
+		commitInfo:            *commitInfo,
+		showAlerts:            *showAlerts,
+		tenantCheck:           *tenantCheck,
+		skipPermissionCheck:   *skipPermissionCheck,
+		attachIfRunning:       *attachIfRunning,
+		ifOlderThan:           *ifOlderThan,
+		recursive:             *recursive,
+		lock:                  *lock,
+		lockTimeout:           *lockTimeout,
+		lockTTL:               *lockTTL,
+		gvr:                   gvr,
+		readyConditions:       readyConditions,
+		waitFor:               waitFor,
+		hintThresholds:        hintThresholds,
+		hintRules:             hintRules,
+		failOnEvents:          failOn,
+
+		intervals: events.Intervals{
+			Poll:   *pollInterval,
+			Events: *eventInterval,
+			Status: *statusInterval,
+		},
+	}
+
+	if fluxMissing() && !nativeOnly(opts) && opts.viaReceiver == "" {
+		output.PrintStatus("flux binary not found in PATH, requesting reconciles by annotating the resources directly")
+	}
+
+	if *allNamespaces && *selector == "" {
+		found, err := findNamespace(ctx, opts)
+		if err != nil {
+			output.PrintError(err.Error())
+			flushTracing()
+			os.Exit(1)
+		}
+		opts.namespace = found
+		output.PrintStatus(fmt.Sprintf("Found %s %s in namespace %s", *kind, *name, found))
+	}
+
+	var results []report.Result
+	exitCode := 0
+	if *selector != "" {
+		// With --all-namespaces no namespaces means a cluster-wide list
+		searchNamespaces := splitList(*namespaces)
+		if len(searchNamespaces) == 0 && !*allNamespaces {
+			searchNamespaces = []string{*namespace}
+		}
+		selected, err := selectResources(ctx, opts, *selector, searchNamespaces)
+		if err != nil {
+			output.PrintError(fmt.Sprintf("Failed to list resources: %v", err))
+			flushTracing()
+			os.Exit(1)
+		}
+		if len(selected) == 0 {
+			output.PrintWarning(fmt.Sprintf("No %s matches selector '%s'", *kind, *selector))
+		}
+		results = runSelected(ctx, selected, *onError == config.OnFailureStop)
+		if len(results) > 0 {
+			printSelectionSummary(results)
+		}
+		for _, r := range results {
+			if !r.Success {
+				exitCode = 1
+			}
+		}
+	} else if queue != nil {
+		batchResults := runBatch(ctx, []batchStage{queueStage(opts, queue, *sequential, *onError)})
+		printBatchSummary(batchResults, false)
+		for _, r := range batchResults {
+			results = append(results, r.Result)
+			if !r.Success {
+				exitCode = 1
+			}
+		}
+	} else if *batchFile != "" {
+		stages, err := loadBatch(*batchFile, opts)
+		if err != nil {
+			output.PrintError(err.Error())
+			flushTracing()
+			os.Exit(1)
+		}
+		if *redactNames {
+			for _, stage := range stages {
+				for _, item := range stage.items {
+					output.RedactNames(item.opts.name)
+					output.RedactNamespaces(item.opts.namespace)
+				}
+			}
+		}
+		batchResults := runBatch(ctx, stages)
+		printBatchSummary(batchResults, len(stages) > 1)
+		for _, r := range batchResults {
+			results = append(results, r.Result)
+			if !r.Success {
+				exitCode = 1
+			}
+		}
+	} else if *contexts != "" {
+		results = runAcrossContexts(ctx, opts, splitList(*contexts))
+		printContextSummary(results)
+		for _, r := range results {
+			if !r.Success {
+				exitCode = 1
+			}
+		}
+	} else {
+		result := runReconcile(ctx, opts)
+		results = []report.Result{result}
+		exitCode = result.ExitCode
+		if opts.wait && *dryRun == "" {
+			printRunSummary(result)
+		}
+	}
+
+	if resultTemplate != nil {
+		if err := printResults(resultTemplate, results); err != nil {
+			output.PrintError(err.Error())
+			exitCode = 1
+		}
+	}
+
+	if *junitReport != "" {
+		if err := report.WriteJUnit(*junitReport, "flux-enhanced-cli", results); err != nil {
+			output.PrintWarning(err.Error())
+		}
+	}
+
+	if *resultFile != "" {
+		if err := report.WriteJSON(*resultFile, results); err != nil {
+			output.PrintWarning(err.Error())
+		}
+	}
+
+	// Send a notification only when the outcome differs from previous runs,
+	// before the runs are recorded as the previous ones
+	if *dryRun == "" {
+		runs := runRecords(results)
+		if *notifyURL != "" {
+			notifyRuns(*historyFile, &notify.Notifier{URL: *notifyURL, FailureThreshold: *notifyFailures}, runs)
+		}
+		recordRuns(*historyFile, runs)
+	}
+
+	flushTracing()
+	os.Exit(exitCode)
+}
+
+// initTracing enables OpenTelemetry tracing when configured through the
+// OTEL_* environment variables. The returned function exports the recorded
+// spans and must be called before exiting.
+func initTracing() func() {
+	shutdown, err := tracing.Init("flux-enhanced-cli", Version)
+	if err != nil {
+		output.PrintWarning(fmt.Sprintf("Tracing disabled: %v", err))
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			output.PrintWarning(fmt.Sprintf("Could not export traces: %v", err))
+		}
+	}
+}
+
+// handleInterrupts cancels the run on the first Ctrl+C and force exits on a
+// second one within 2 seconds (thread-safe).
+func handleInterrupts(cancel context.CancelFunc) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
@@ -118,86 +762,4 @@ func main() {
 			}
 		}
 	}()
-
-	// Validate source type
-	validSourceTypes := map[string]bool{"git": true, "oci": true}
-	if *kind == "source" && !validSourceTypes[*sourceType] {
-		fmt.Fprintf(os.Stderr, "Error: invalid source-type '%s'. Valid types: git, oci\n", *sourceType)
-		os.Exit(1)
-	}
-
-	// Start event monitoring (only if we have a valid kind for monitoring)
-	var eventMonitor *events.Monitor
-	if *kind == "kustomization" || *kind == "helmrelease" || *kind == "source" {
-		var err error
-		monitorKind := *kind
-		if *kind == "source" {
-			monitorKind = *sourceType // Pass "git" or "oci" to monitor
-		}
-		eventMonitor, err = events.NewMonitor(ctx, monitorKind, *name, *namespace)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Could not start event monitoring: %v\n", err)
-		} else {
-			defer eventMonitor.Stop()
-			go eventMonitor.Watch()
-		}
-	}
-
-	// Build flux command
-	var cmd *exec.Cmd
-	if *kind == "source" {
-		// For source, we need "flux reconcile source <type> <name>"
-		cmd = exec.CommandContext(ctx, "flux", "reconcile", "source", *sourceType, *name, "-n", *namespace)
-	} else {
-		cmd = exec.CommandContext(ctx, "flux", "reconcile", *kind, *name, "-n", *namespace)
-		if *kind == "kustomization" || *kind == "helmrelease" {
-			cmd.Args = append(cmd.Args, "--with-source")
-		}
-	}
-
-	// Run command and stream output
-	output.PrintCommand(cmd.Args...)
-	cmd.Stdout = os.Stdout
-
-	// Intercept stderr to format warnings nicely
-	stderrPipe, err := cmd.StderrPipe()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating stderr pipe: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error starting flux: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Process stderr in a goroutine with WaitGroup to ensure completion
-	var stderrWg sync.WaitGroup
-	stderrWg.Add(1)
-	go processStderr(stderrPipe, &stderrWg)
-
-	// Wait for command to complete
-	cmdErr := cmd.Wait()
-
-	// Wait for stderr processing to complete before handling errors
-	stderrWg.Wait()
-
-	if cmdErr != nil {
-		if exitErr, ok := cmdErr.(*exec.ExitError); ok {
-			os.Exit(exitErr.ExitCode())
-		}
-		fmt.Fprintf(os.Stderr, "Error running flux: %v\n", cmdErr)
-		os.Exit(1)
-	}
-
-	// Wait for reconciliation if requested
-	if *wait && eventMonitor != nil {
-		output.PrintWaiting(*kind, *name)
-		if err := eventMonitor.WaitForReady(ctx, *timeout); err != nil {
-			output.PrintError(fmt.Sprintf("Reconciliation failed or timed out: %v", err))
-			os.Exit(1)
-		}
-		output.PrintSuccess(*kind, *name)
-	}
 }
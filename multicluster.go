@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+)
+
+// runAcrossContexts reconciles the same resource in every kubeconfig context
+// concurrently. Each cluster gets its own clients and its output is prefixed
+// with the context name.
+func runAcrossContexts(ctx context.Context, opts reconcileOptions, contexts []string) []report.Result {
+	results := make([]report.Result, len(contexts))
+
+	var wg sync.WaitGroup
+	for i, kubeContext := range contexts {
+		wg.Add(1)
+		go func(i int, kubeContext string) {
+			defer wg.Done()
+			contextOpts := opts
+			contextOpts.client.Context = kubeContext
+			clusterCtx := output.WithPrinter(ctx, output.NewPrinter(kubeContext))
+			results[i] = runReconcile(clusterCtx, contextOpts)
+		}(i, kubeContext)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// printContextSummary prints the aggregate outcome of a multi-cluster run
+func printContextSummary(results []report.Result) {
+	rows := make([][]string, 0, len(results))
+	failed := 0
+	for _, r := range results {
+		status := "Ready"
+		switch {
+		case !r.Success:
+			status = "Failed"
+			failed++
+		case r.Flapping:
+			status = "Ready (flapping)"
+		}
+		row := append([]string{r.Context, status}, timingCells(r)...)
+		rows = append(rows, append(row, r.Duration.Round(time.Second).String(), r.Message))
+	}
+
+	output.Println()
+	output.PrintTable(append(append([]string{"CONTEXT", "STATUS"}, timingColumns...), "DURATION", "MESSAGE"), rows)
+	output.Println()
+
+	if failed > 0 {
+		output.PrintError(fmt.Sprintf("Reconciliation failed in %d of %d clusters", failed, len(results)))
+		return
+	}
+	output.PrintMain("✅", fmt.Sprintf("Reconciliation succeeded in all %d clusters", len(results)), output.ColorGreen)
+}
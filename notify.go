@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+const notifyUsage = `Usage: flux-enhanced-cli notify test provider <name> [options]
+
+Sends a test event through a notification-controller Provider and reports
+whether it was delivered. A temporary Alert forwarding the event to the
+Provider is created and deleted again; the outcome is read from the
+notification-controller's logs. The exit code is 1 when the dispatch failed.
+`
+
+// notifyCommand implements "notify test provider <name>"
+func notifyCommand(args []string) int {
+	fs := flag.NewFlagSet("notify", flag.ContinueOnError)
+	namespace := fs.String("namespace", "flux-system", "Namespace of the Provider")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace of the Provider (shorthand)")
+	severity := fs.String("severity", "info", "Severity of the test event (info or error)")
+	message := fs.String("message", "Test notification sent by flux-enhanced-cli", "Message of the test event")
+	fluxNamespace := fs.String("flux-namespace", "flux-system", "Namespace the notification-controller runs in")
+	wait := fs.Duration("wait", 15*time.Second, "How long to watch the notification-controller logs for the dispatch")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Timeout for the whole test")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, notifyUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(positional) != 3 || positional[0] != "test" || positional[1] != "provider" {
+		fs.Usage()
+		return 1
+	}
+	if *severity != "info" && *severity != "error" {
+		fmt.Fprintf(os.Stderr, "Error: --severity must be info or error\n")
+		return 1
+	}
+	name := positional[2]
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	handleInterrupts(cancel)
+
+	cluster, err := events.NewCluster(*clientOpts)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+
+	output.PrintMain("🔔", fmt.Sprintf("Testing provider %s/%s", *namespace, name), output.ColorBlue)
+	result, err := cluster.TestProvider(ctx, events.ProviderTest{
+		Namespace:     *namespace,
+		Name:          name,
+		Severity:      *severity,
+		Message:       *message,
+		FluxNamespace: *fluxNamespace,
+		Wait:          *wait,
+		Progress:      output.PrintStatus,
+	})
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+
+	switch {
+	case result.Failed:
+		output.PrintError(fmt.Sprintf("Delivery through %s provider %s/%s failed: %s", result.Type, *namespace, name, result.Error))
+		return 1
+	case result.Confirmed:
+		output.PrintMain("✅", fmt.Sprintf("Test event delivered through %s provider %s/%s", result.Type, *namespace, name), output.ColorGreen)
+	case result.LogErr != nil:
+		output.PrintWarning(fmt.Sprintf("Test event sent, but the notification-controller logs could not be read: %v", result.LogErr))
+	default:
+		output.PrintMain("✅", fmt.Sprintf("Test event sent through %s provider %s/%s, no delivery error logged within %s", result.Type, *namespace, name, *wait), output.ColorGreen)
+		output.PrintSublog("   Successful deliveries are only logged when the notification-controller runs with --log-level=debug")
+	}
+	return 0
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Stage failure policies
+const (
+	OnFailureStop     = "stop"
+	OnFailureContinue = "continue"
+)
+
+// Batch is a file listing resources to reconcile in one run, either as a
+// flat list or as ordered stages
+type Batch struct {
+	// Resources are reconciled one after the other; a failure doesn't stop
+	// the rest. ParseBatch turns them into a single stage.
+	Resources []BatchEntry `json:"resources,omitempty"`
+	Stages    []Stage      `json:"stages,omitempty"`
+}
+
+// Stage is a group of resources that completes before the next stage starts
+type Stage struct {
+	Name string `json:"name,omitempty"`
+	// Parallel reconciles the resources concurrently, apart from dependsOn
+	Parallel bool `json:"parallel,omitempty"`
+	// OnFailure is "stop" (the default), skipping the later stages when a
+	// resource of this stage fails, or "continue"
+	OnFailure string       `json:"onFailure,omitempty"`
+	Resources []BatchEntry `json:"resources"`
+}
+
+// BatchEntry is a resource of a batch file
+type BatchEntry struct {
+	Resource
+	// ID is how dependsOn refers to the entry; defaults to the name
+	ID string `json:"id,omitempty"`
+	// DependsOn lists entries of this or an earlier stage that must have
+	// succeeded before this one starts
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// ParseBatch parses and validates a batch file
+func ParseBatch(data []byte) (*Batch, error) {
+	var batch Batch
+	if err := yaml.UnmarshalStrict(data, &batch); err != nil {
+		return nil, fmt.Errorf("failed to parse batch file: %w", err)
+	}
+	if len(batch.Resources) > 0 && len(batch.Stages) > 0 {
+		return nil, fmt.Errorf("batch file lists both resources and stages")
+	}
+	if len(batch.Resources) > 0 {
+		batch.Stages = []Stage{{Resources: batch.Resources, OnFailure: OnFailureContinue}}
+		batch.Resources = nil
+	}
+	if len(batch.Stages) == 0 {
+		return nil, fmt.Errorf("batch file lists no resources")
+	}
+
+	known := map[string]bool{}
+	for i := range batch.Stages {
+		stage := &batch.Stages[i]
+		if stage.Name == "" {
+			stage.Name = fmt.Sprintf("stage %d", i+1)
+		}
+		switch stage.OnFailure {
+		case "":
+			stage.OnFailure = OnFailureStop
+		case OnFailureStop, OnFailureContinue:
+		default:
+			return nil, fmt.Errorf("%s: invalid onFailure '%s'. Valid policies: stop, continue", stage.Name, stage.OnFailure)
+		}
+		if len(stage.Resources) == 0 {
+			return nil, fmt.Errorf("%s lists no resources", stage.Name)
+		}
+
+		for j := range stage.Resources {
+			entry := &stage.Resources[j]
+			if entry.Kind == "" || entry.Name == "" {
+				return nil, fmt.Errorf("%s: resource %d needs a kind and name", stage.Name, j+1)
+			}
+			if entry.ID == "" {
+				entry.ID = entry.Name
+			}
+			if known[entry.ID] {
+				return nil, fmt.Errorf("%s: duplicate id '%s', set a unique id on the entries", stage.Name, entry.ID)
+			}
+			known[entry.ID] = true
+		}
+		// Dependencies may point back to earlier stages but not ahead
+		for _, entry := range stage.Resources {
+			for _, dep := range entry.DependsOn {
+				if !known[dep] {
+					return nil, fmt.Errorf("%s: '%s' depends on '%s', which is not defined in this or an earlier stage", stage.Name, entry.ID, dep)
+				}
+			}
+		}
+		if err := checkCycles(stage.Resources); err != nil {
+			return nil, fmt.Errorf("%s: %w", stage.Name, err)
+		}
+	}
+	return &batch, nil
+}
+
+// checkCycles rejects dependsOn cycles among the entries of a stage
+func checkCycles(entries []BatchEntry) error {
+	deps := map[string][]string{}
+	for _, e := range entries {
+		deps[e.ID] = e.DependsOn
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			return fmt.Errorf("dependency cycle through '%s'", id)
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		for _, dep := range deps[id] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[id] = visited
+		return nil
+	}
+	for _, e := range entries {
+		if err := visit(e.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/schedule"
+)
+
+// Config is the user configuration file
+type Config struct {
+	Releases map[string]Release `json:"releases,omitempty"`
+	// Webhooks maps Git pushes received by "serve" to reconciles
+	Webhooks []WebhookRoute `json:"webhooks,omitempty"`
+	// Schedules reconcile resources periodically in "serve"
+	Schedules []Schedule `json:"schedules,omitempty"`
+	// Hints add remediation advice for failure messages, checked before the
+	// built-in hints
+	Hints []Hint `json:"hints,omitempty"`
+}
+
+// Hint maps failure messages matching a regular expression to advice
+type Hint struct {
+	Pattern string `json:"pattern"`
+	// Hint may insert capture groups of the pattern ($1, ${name}) and the
+	// kind of the resource (${kind})
+	Hint string `json:"hint"`
+	// Kinds limits the hint to these kinds; it applies to all when empty
+	Kinds []string `json:"kinds,omitempty"`
+}
+
+// Schedule reconciles a set of resources on a cron schedule
+type Schedule struct {
+	Name string `json:"name"`
+	// Cron is a five-field cron expression or @hourly, @daily, ...
+	Cron string `json:"cron"`
+	// Jitter delays every run by a random duration up to this value
+	Jitter *metav1.Duration `json:"jitter,omitempty"`
+	// Force upgrades HelmReleases even when nothing changed
+	Force bool `json:"force,omitempty"`
+	// NotifyURL receives a notification when the outcome of a resource
+	// changes from its previous runs
+	NotifyURL string `json:"notifyURL,omitempty"`
+	// NotifyFailures is the number of consecutive failures required before
+	// a failure is notified; it defaults to 1
+	NotifyFailures int `json:"notifyFailures,omitempty"`
+	// Resources are reconciled in order, each waiting for the previous one
+	Resources []Resource `json:"resources"`
+}
+
+// WebhookRoute maps pushes to a repository branch to the GitRepository that
+// fetches it and the Kustomizations applying it
+type WebhookRoute struct {
+	// Repository is the repository path, e.g. org/repo or group/subgroup/project
+	Repository string `json:"repository"`
+	// Branch limits the route to one branch; pushes to any branch match when
+	// empty
+	Branch         string      `json:"branch,omitempty"`
+	GitRepository  ObjectRef   `json:"gitRepository"`
+	Kustomizations []ObjectRef `json:"kustomizations"`
+}
+
+// ObjectRef names a Flux resource; the namespace defaults to flux-system
+type ObjectRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Release groups several Flux resources that are deployed as one logical unit
+type Release struct {
+	Description string `json:"description,omitempty"`
+	// Source is reconciled first, before any of the resources
+	Source *Resource `json:"source,omitempty"`
+	// Resources are reconciled in order, each waiting for the previous one
+	Resources []Resource `json:"resources"`
+	// PostCheck verifies the release once all resources are ready
+	PostCheck *PostCheck `json:"postCheck,omitempty"`
+	// NotifyURL receives a notification when the release outcome changes
+	NotifyURL string `json:"notifyURL,omitempty"`
+}
+
+// Resource identifies a Flux resource to reconcile
+type Resource struct {
+	Kind       string           `json:"kind"`
+	Name       string           `json:"name"`
+	Namespace  string           `json:"namespace,omitempty"`
+	SourceType string           `json:"sourceType,omitempty"`
+	Timeout    *metav1.Duration `json:"timeout,omitempty"`
+	// Revision is the revision the resource must report once Ready (batch
+	// files only); a prefix of the revision or of its commit SHA or digest
+	Revision string `json:"revision,omitempty"`
+	// PreHook and PostHook are shell commands run before and after the
+	// reconcile of this resource
+	PreHook  string `json:"preHook,omitempty"`
+	PostHook string `json:"postHook,omitempty"`
+}
+
+// PostCheck is an HTTP endpoint that must respond successfully after deploy
+type PostCheck struct {
+	URL string `json:"url"`
+	// ExpectStatus is the expected HTTP status code; any 2xx when unset
+	ExpectStatus int              `json:"expectStatus,omitempty"`
+	Timeout      *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// DefaultPath returns the config file location, honouring
+// FLUX_ENHANCED_CLI_CONFIG and XDG_CONFIG_HOME.
+func DefaultPath() string {
+	if path := os.Getenv("FLUX_ENHANCED_CLI_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "flux-enhanced-cli.yaml"
+	}
+	return filepath.Join(dir, "flux-enhanced-cli", "config.yaml")
+}
+
+// Load reads and validates the config file at path
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	for name, release := range cfg.Releases {
+		if len(release.Resources) == 0 && release.Source == nil {
+			return nil, fmt.Errorf("release '%s' has no resources", name)
+		}
+		resources := release.Resources
+		if release.Source != nil {
+			resources = append([]Resource{*release.Source}, resources...)
+		}
+		for _, r := range resources {
+			if r.Kind == "" || r.Name == "" {
+				return nil, fmt.Errorf("release '%s': every resource needs a kind and name", name)
+			}
+		}
+		if release.PostCheck != nil && release.PostCheck.URL == "" {
+			return nil, fmt.Errorf("release '%s': postCheck needs a url", name)
+		}
+	}
+
+	for i, route := range cfg.Webhooks {
+		if route.Repository == "" || route.GitRepository.Name == "" {
+			return nil, fmt.Errorf("webhook %d: a repository and gitRepository are required", i+1)
+		}
+		for _, ks := range route.Kustomizations {
+			if ks.Name == "" {
+				return nil, fmt.Errorf("webhook %d: every kustomization needs a name", i+1)
+			}
+		}
+	}
+
+	names := map[string]bool{}
+	for i, sched := range cfg.Schedules {
+		if sched.Name == "" {
+			return nil, fmt.Errorf("schedule %d has no name", i+1)
+		}
+		if names[sched.Name] {
+			return nil, fmt.Errorf("schedule '%s' is defined twice", sched.Name)
+		}
+		names[sched.Name] = true
+		if _, err := schedule.Parse(sched.Cron); err != nil {
+			return nil, fmt.Errorf("schedule '%s': %w", sched.Name, err)
+		}
+		if len(sched.Resources) == 0 {
+			return nil, fmt.Errorf("schedule '%s' has no resources", sched.Name)
+		}
+		if sched.NotifyFailures < 0 {
+			return nil, fmt.Errorf("schedule '%s': notifyFailures can't be negative", sched.Name)
+		}
+		for _, r := range sched.Resources {
+			if r.Kind == "" || r.Name == "" {
+				return nil, fmt.Errorf("schedule '%s': every resource needs a kind and name", sched.Name)
+			}
+		}
+	}
+
+	for i, hint := range cfg.Hints {
+		if hint.Pattern == "" || hint.Hint == "" {
+			return nil, fmt.Errorf("hint %d: a pattern and hint are required", i+1)
+		}
+		if _, err := regexp.Compile(hint.Pattern); err != nil {
+			return nil, fmt.Errorf("hint %d: invalid pattern: %w", i+1, err)
+		}
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,172 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// Permission is an API request the caller must be allowed to make
+type Permission struct {
+	Verb      string
+	Group     string
+	Resource  string
+	Namespace string
+	// Name is empty for requests on the whole collection (list, watch)
+	Name string
+	// Optional permissions only improve the output (e.g. events), missing
+	// them doesn't fail the run
+	Optional bool
+}
+
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource += "." + p.Group
+	}
+	if p.Name != "" {
+		resource += fmt.Sprintf(" %q", p.Name)
+	}
+	if p.Namespace == "" {
+		return fmt.Sprintf("%q on %s cluster-wide", p.Verb, resource)
+	}
+	return fmt.Sprintf("%q on %s in namespace %s", p.Verb, resource, p.Namespace)
+}
+
+// ReconcilePermissions returns the permissions needed to trigger and watch a
+// resource of a monitor kind: get and patch on the resource (and on its source
+// with withSource), and, optionally, list and watch on events in its
+// namespace.
+func (c *Cluster) ReconcilePermissions(ctx context.Context, kind, namespace, name string, withSource bool) ([]Permission, error) {
+	gvr, err := c.ResolveKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	permissions := []Permission{
+		{Verb: "get", Group: gvr.Group, Resource: gvr.Resource, Namespace: namespace, Name: name},
+		{Verb: "patch", Group: gvr.Group, Resource: gvr.Resource, Namespace: namespace, Name: name},
+		{Verb: "list", Group: "events.k8s.io", Resource: "events", Namespace: namespace, Optional: true},
+		{Verb: "watch", Group: "events.k8s.io", Resource: "events", Namespace: namespace, Optional: true},
+	}
+	if !withSource {
+		return permissions, nil
+	}
+	// Without a readable resource there is no source to check, the review of
+	// get on the resource reports why
+	source, err := c.Source(ctx, kind, namespace, name)
+	if err != nil {
+		return permissions, nil
+	}
+	sourceGVR, err := c.ResolveKind(source.Kind)
+	if err != nil {
+		return permissions, nil
+	}
+	return append(permissions,
+		Permission{Verb: "get", Group: sourceGVR.Group, Resource: sourceGVR.Resource, Namespace: source.Namespace, Name: source.Name},
+		Permission{Verb: "patch", Group: sourceGVR.Group, Resource: sourceGVR.Resource, Namespace: source.Namespace, Name: source.Name},
+	), nil
+}
+
+// MissingPermissions checks each permission with a SelfSubjectAccessReview
+// and returns the ones that are denied.
+func (c *Cluster) MissingPermissions(ctx context.Context, permissions []Permission) ([]Permission, error) {
+	var missing []Permission
+	for _, p := range permissions {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:      p.Verb,
+					Group:     p.Group,
+					Resource:  p.Resource,
+					Namespace: p.Namespace,
+					Name:      p.Name,
+				},
+			},
+		}
+		result, err := c.clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("access review failed: %w", err)
+		}
+		if !result.Status.Allowed {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
+
+// Tenant is what the kustomize-controller applies a Kustomization as: the
+// service account it impersonates and the objects of its last apply
+type Tenant struct {
+	// ServiceAccount is spec.serviceAccountName, empty when the controller's
+	// own account is used
+	ServiceAccount string
+	Prune          bool
+	Objects        []InventoryObject
+}
+
+// User is the username of the tenant's service account, as impersonated
+func (t Tenant) User(namespace string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, t.ServiceAccount)
+}
+
+// KustomizationTenant reads the service account and inventory of a
+// Kustomization
+func (c *Cluster) KustomizationTenant(ctx context.Context, namespace, name string) (*Tenant, error) {
+	gvr, err := c.ResolveKind("kustomization")
+	if err != nil {
+		return nil, err
+	}
+	obj, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	tenant := &Tenant{}
+	tenant.ServiceAccount, _, _ = unstructured.NestedString(obj.Object, "spec", "serviceAccountName")
+	tenant.Prune, _, _ = unstructured.NestedBool(obj.Object, "spec", "prune")
+	if tenant.Objects, err = inventoryObjects(obj); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// ApplyPermissions returns the permissions the kustomize-controller needs to
+// apply the tenant's objects with server-side apply: get, create and patch
+// on each kind in each namespace, and delete when pruning. Kinds the API
+// server doesn't serve anymore are returned separately.
+func (c *Cluster) ApplyPermissions(tenant *Tenant) ([]Permission, []string) {
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(c.clientset.Discovery()))
+	verbs := []string{"get", "create", "patch"}
+	if tenant.Prune {
+		verbs = append(verbs, "delete")
+	}
+
+	var permissions []Permission
+	var unknown []string
+	seen := map[string]bool{}
+	for _, o := range tenant.Objects {
+		gk := schema.GroupKind{Group: o.Group, Kind: o.Kind}
+		mapping, err := mapper.RESTMapping(gk, o.Version)
+		if err != nil {
+			if !seen[gk.String()] {
+				unknown = append(unknown, gk.String())
+			}
+			seen[gk.String()] = true
+			continue
+		}
+		key := mapping.Resource.GroupResource().String() + "/" + o.Namespace
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		for _, verb := range verbs {
+			permissions = append(permissions, Permission{Verb: verb, Group: o.Group, Resource: mapping.Resource.Resource, Namespace: o.Namespace})
+		}
+	}
+	return permissions, unknown
+}
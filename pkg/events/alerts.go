@@ -0,0 +1,249 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	alertsResource    = schema.GroupResource{Group: "notification.toolkit.fluxcd.io", Resource: "alerts"}
+	providersResource = schema.GroupResource{Group: "notification.toolkit.fluxcd.io", Resource: "providers"}
+	receiversResource = schema.GroupResource{Group: "notification.toolkit.fluxcd.io", Resource: "receivers"}
+)
+
+// AlertMatch is an Alert whose eventSources select a resource, so the
+// resource's events are forwarded to the Alert's provider
+type AlertMatch struct {
+	Namespace string
+	Name      string
+	// Severity is the minimum event severity forwarded (info or error)
+	Severity string
+	Provider string
+	// ProviderType is the Provider's spec.type (slack, github, ...), empty
+	// when the Provider doesn't exist
+	ProviderType string
+	// Suspended is set when the Alert or its Provider is suspended
+	Suspended bool
+	// Filtered is set when the Alert has inclusion or exclusion lists, so
+	// only some events are forwarded
+	Filtered bool
+}
+
+// MatchingAlerts returns the Alerts whose eventSources select the resource.
+// Alerts are listed cluster-wide, falling back to the resource's namespace
+// when that is forbidden.
+func (c *Cluster) MatchingAlerts(ctx context.Context, kind, namespace, name string) ([]AlertMatch, error) {
+	gvr, err := c.ResolveKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	alertsGVR, err := discoverGVR(c.clientset.Discovery(), alertsResource.WithVersion(""))
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := c.dynamicClient.Resource(alertsGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if apierrors.IsForbidden(err) {
+		alerts, err = c.dynamicClient.Resource(alertsGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+
+	var matches []AlertMatch
+	for i := range alerts.Items {
+		alert := &alerts.Items[i]
+		if !alertSelects(alert, obj) {
+			continue
+		}
+		match := AlertMatch{
+			Namespace: alert.GetNamespace(),
+			Name:      alert.GetName(),
+			Severity:  "info",
+		}
+		if severity, _, _ := unstructured.NestedString(alert.Object, "spec", "eventSeverity"); severity != "" {
+			match.Severity = severity
+		}
+		match.Suspended, _, _ = unstructured.NestedBool(alert.Object, "spec", "suspend")
+		inclusions, _, _ := unstructured.NestedStringSlice(alert.Object, "spec", "inclusionList")
+		exclusions, _, _ := unstructured.NestedStringSlice(alert.Object, "spec", "exclusionList")
+		match.Filtered = len(inclusions) > 0 || len(exclusions) > 0
+		match.Provider, _, _ = unstructured.NestedString(alert.Object, "spec", "providerRef", "name")
+		if provider := c.alertProvider(ctx, alertsGVR.Version, match.Namespace, match.Provider); provider != nil {
+			match.ProviderType, _, _ = unstructured.NestedString(provider.Object, "spec", "type")
+			if suspended, _, _ := unstructured.NestedBool(provider.Object, "spec", "suspend"); suspended {
+				match.Suspended = true
+			}
+		}
+		matches = append(matches, match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Namespace != matches[j].Namespace {
+			return matches[i].Namespace < matches[j].Namespace
+		}
+		return matches[i].Name < matches[j].Name
+	})
+	return matches, nil
+}
+
+// alertProvider returns the Alert's Provider, or nil when it can't be read
+func (c *Cluster) alertProvider(ctx context.Context, version, namespace, name string) *unstructured.Unstructured {
+	if name == "" {
+		return nil
+	}
+	provider, err := c.dynamicClient.Resource(providersResource.WithVersion(version)).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	return provider
+}
+
+// alertSelects reports whether an Alert's eventSources select obj
+func alertSelects(alert, obj *unstructured.Unstructured) bool {
+	return refsSelect(alert, obj, "spec", "eventSources")
+}
+
+// refsSelect follows the notification-controller's rules for the object
+// references at fields of owner (an Alert's eventSources, a Receiver's
+// resources): the kind must match, the namespace defaults to the owner's,
+// and the name is either exact or "*" narrowed by matchLabels.
+func refsSelect(owner, obj *unstructured.Unstructured, fields ...string) bool {
+	refs, _, _ := unstructured.NestedSlice(owner.Object, fields...)
+	for _, r := range refs {
+		ref, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _, _ := unstructured.NestedString(ref, "kind")
+		name, _, _ := unstructured.NestedString(ref, "name")
+		namespace, _, _ := unstructured.NestedString(ref, "namespace")
+		if namespace == "" {
+			namespace = owner.GetNamespace()
+		}
+		if kind != obj.GetKind() || namespace != obj.GetNamespace() {
+			continue
+		}
+		if name != "*" {
+			if name == obj.GetName() {
+				return true
+			}
+			continue
+		}
+		matchLabels, _, _ := unstructured.NestedStringMap(ref, "matchLabels")
+		if labels.SelectorFromSet(matchLabels).Matches(labels.Set(obj.GetLabels())) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationLog is a log line of the notification-controller about an
+// event it received or dispatched
+type NotificationLog struct {
+	Time    time.Time
+	Pod     string
+	Error   bool
+	Message string
+	// Object is the involved object as Kind/namespace/name, when logged
+	Object string
+}
+
+// TailNotifications follows the logs of the notification-controller pods in
+// namespace from since on, calling fn for each line about an event (lines
+// naming an involved object, and errors). Failed dispatches are always
+// logged; successful ones only when the controller runs with
+// --log-level=debug. It returns nil when ctx is done, and an error as soon as
+// the log of a pod ends, e.g. because the pod restarted or was replaced.
+func (c *Cluster) TailNotifications(ctx context.Context, namespace string, since time.Time, fn func(NotificationLog)) error {
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "app=notification-controller"})
+	if err != nil {
+		return fmt.Errorf("failed to find the notification-controller: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return fmt.Errorf("no notification-controller pods in namespace %s", namespace)
+	}
+
+	sinceTime := metav1.NewTime(since)
+	// The first log to end stops the others, so the caller can find the
+	// current pods again
+	streamCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var ended error
+	end := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ended == nil && streamCtx.Err() == nil {
+			ended = err
+		}
+		stop()
+	}
+	for _, pod := range pods.Items {
+		wg.Add(1)
+		go func(pod string) {
+			defer wg.Done()
+			req := c.clientset.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Follow: true, SinceTime: &sinceTime})
+			stream, err := req.Stream(streamCtx)
+			if err != nil {
+				end(fmt.Errorf("pod %s: %w", pod, err))
+				return
+			}
+			defer stream.Close()
+			scanner := bufio.NewScanner(stream)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				entry, ok := parseNotificationLog(scanner.Bytes())
+				if !ok {
+					continue
+				}
+				entry.Pod = pod
+				mu.Lock()
+				fn(entry)
+				mu.Unlock()
+			}
+			if err := scanner.Err(); err != nil {
+				end(fmt.Errorf("pod %s: %w", pod, err))
+				return
+			}
+			end(fmt.Errorf("the log of pod %s ended", pod))
+		}(pod.Name)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	return ended
+}
+
+// parseNotificationLog reads a JSON log line of the notification-controller,
+// keeping errors and lines that name an involved object
+func parseNotificationLog(line []byte) (NotificationLog, bool) {
+	l, ok := ParseLogLine(line)
+	if !ok {
+		return NotificationLog{}, false
+	}
+	entry := NotificationLog{Error: l.Level == "error", Message: l.Text(), Object: l.Object(), Time: l.Time}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if entry.Object == "" && !entry.Error {
+		return NotificationLog{}, false
+	}
+	return entry, true
+}
@@ -0,0 +1,184 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Annotations understood by the Flux controllers
+const (
+	// RequestedAtAnnotation requests a reconcile when its value changes
+	RequestedAtAnnotation = "reconcile.fluxcd.io/requestedAt"
+	// ForceAtAnnotation forces a Helm upgrade when set to the same value as
+	// RequestedAtAnnotation
+	ForceAtAnnotation = "reconcile.fluxcd.io/forceAt"
+)
+
+// reconcileRequestPatch builds a merge patch requesting a reconcile, merged
+// with extra fields (e.g. spec changes). With force, the HelmRelease is
+// upgraded even if nothing changed.
+func reconcileRequestPatch(force bool, extra map[string]interface{}) ([]byte, string, error) {
+	now := time.Now().Format(time.RFC3339Nano)
+	annotations := map[string]string{RequestedAtAnnotation: now}
+	if force {
+		annotations[ForceAtAnnotation] = now
+	}
+
+	patch := map[string]interface{}{}
+	for k, v := range extra {
+		patch[k] = v
+	}
+	patch["metadata"] = map[string]interface{}{"annotations": annotations}
+	data, err := json.Marshal(patch)
+	return data, now, err
+}
+
+// ReconcileRequestPatch returns the merge patch RequestReconcile would apply
+// at this moment
+func ReconcileRequestPatch(force bool) ([]byte, error) {
+	patch, _, err := reconcileRequestPatch(force, nil)
+	return patch, err
+}
+
+// RequestReconcile sets the reconcile.fluxcd.io/requestedAt annotation (and
+// forceAt when force is set) on a resource of a monitor kind, and returns the
+// requested token.
+func (c *Cluster) RequestReconcile(ctx context.Context, kind, namespace, name string, force bool) (string, error) {
+	gvr, err := c.ResolveKind(kind)
+	if err != nil {
+		return "", err
+	}
+	patch, token, err := reconcileRequestPatch(force, nil)
+	if err != nil {
+		return "", err
+	}
+	_, err = c.dynamicClient.Resource(gvr).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return token, err
+}
+
+// sourceKinds maps Flux source kinds to monitor kinds
+var sourceKinds = map[string]string{
+	"GitRepository":  "git",
+	"OCIRepository":  "oci",
+	"Bucket":         "bucket",
+	"HelmChart":      "helmchart",
+	"HelmRepository": "helmrepository",
+}
+
+// SourceRef identifies a source object
+type SourceRef struct {
+	// Kind is the monitor kind (git, oci, helmchart, ...)
+	Kind string
+	// APIKind is the Kubernetes kind (GitRepository, HelmChart, ...)
+	APIKind   string
+	Namespace string
+	Name      string
+}
+
+func (r SourceRef) String() string {
+	return fmt.Sprintf("%s/%s/%s", r.APIKind, r.Namespace, r.Name)
+}
+
+// Source returns the source that "flux reconcile --with-source" would
+// reconcile first: the sourceRef of a Kustomization, and the HelmChart (or
+// chartRef) of a HelmRelease.
+func (c *Cluster) Source(ctx context.Context, kind, namespace, name string) (*SourceRef, error) {
+	gvr, err := c.ResolveKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	refs, err := sourceRefs(obj, kind)
+	if err != nil {
+		return nil, err
+	}
+	return &refs[0], nil
+}
+
+// sourceRefs returns the sources of a Kustomization (its sourceRef) or a
+// HelmRelease (its HelmChart and the chart's source, or its chartRef), in
+// the order "flux reconcile --with-source" handles them.
+func sourceRefs(obj *unstructured.Unstructured, kind string) ([]SourceRef, error) {
+	namespace, name := obj.GetNamespace(), obj.GetName()
+	toRef := func(ref map[string]interface{}) (SourceRef, error) {
+		refKind, _, _ := unstructured.NestedString(ref, "kind")
+		refName, _, _ := unstructured.NestedString(ref, "name")
+		refNamespace, _, _ := unstructured.NestedString(ref, "namespace")
+		if refNamespace == "" {
+			refNamespace = namespace
+		}
+		sourceKind, ok := sourceKinds[refKind]
+		if !ok {
+			return SourceRef{}, fmt.Errorf("unsupported source kind %s", refKind)
+		}
+		return SourceRef{Kind: sourceKind, APIKind: refKind, Namespace: refNamespace, Name: refName}, nil
+	}
+
+	var ref map[string]interface{}
+	if kind == "helmrelease" {
+		if chartRef, found, _ := unstructured.NestedMap(obj.Object, "spec", "chartRef"); found {
+			ref = chartRef
+		} else if sourceRef, found, _ := unstructured.NestedMap(obj.Object, "spec", "chart", "spec", "sourceRef"); found {
+			source, err := toRef(sourceRef)
+			if err != nil {
+				return nil, err
+			}
+			// helm-controller creates the HelmChart <namespace>-<name> next to the source
+			chart := SourceRef{Kind: "helmchart", APIKind: "HelmChart", Namespace: source.Namespace, Name: namespace + "-" + name}
+			return []SourceRef{chart, source}, nil
+		}
+	} else {
+		ref, _, _ = unstructured.NestedMap(obj.Object, "spec", "sourceRef")
+	}
+	if ref == nil {
+		return nil, fmt.Errorf("%s %s/%s has no source reference", kind, namespace, name)
+	}
+	source, err := toRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	return []SourceRef{source}, nil
+}
+
+// WaitHandled waits until a resource has handled the reconcile request token
+// and is Ready again
+func (c *Cluster) WaitHandled(ctx context.Context, kind, namespace, name, token string) error {
+	gvr, err := c.ResolveKind(kind)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		obj, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		handled, _, _ := unstructured.NestedString(obj.Object, "status", "lastHandledReconcileAt")
+		status, conditions := summarizeConditions(obj)
+		if handled == token {
+			if status == "ready" {
+				return nil
+			}
+			if status == "not ready" || status == "failed" || status == "terminating" {
+				return fmt.Errorf("%s", conditions)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
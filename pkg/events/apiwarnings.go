@@ -0,0 +1,43 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+var (
+	apiWarningsMu   sync.Mutex
+	apiWarningsSeen = map[string]bool{}
+)
+
+// apiWarnings is a rest.WarningHandler that prints the warnings the API
+// server returns (deprecated API versions, unknown fields, ...) through
+// pkg/output instead of klog, with the printer of the client's caller
+type apiWarnings struct {
+	out      *output.Printer
+	suppress bool
+}
+
+func (w apiWarnings) HandleWarningHeader(code int, agent string, text string) {
+	// Only code 299 is a warning (RFC 7234)
+	if code != 299 {
+		return
+	}
+	PrintAPIWarning(w.out, text, w.suppress)
+}
+
+// PrintAPIWarning prints an API server warning, once per run since the same
+// warning comes with every poll. Suppressed warnings are dropped.
+func PrintAPIWarning(out *output.Printer, text string, suppress bool) {
+	if suppress || text == "" {
+		return
+	}
+	apiWarningsMu.Lock()
+	seen := apiWarningsSeen[text]
+	apiWarningsSeen[text] = true
+	apiWarningsMu.Unlock()
+	if !seen {
+		out.PrintWarning(text)
+	}
+}
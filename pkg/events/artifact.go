@@ -0,0 +1,135 @@
+package events
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Artifact is the artifact a source has produced, from its status.artifact
+type Artifact struct {
+	Revision       string
+	Digest         string
+	LastUpdateTime string
+	// Size is the artifact size in bytes, 0 when not reported
+	Size int64
+	// Metadata holds the OCI annotations of OCIRepository artifacts
+	Metadata map[string]string
+	// SourceURL is the source's spec.url
+	SourceURL string
+	// Verification is set for sources with spec.verify
+	Verification *Verification
+}
+
+// Artifact returns the source's current artifact, or nil when it has none
+func (m *Monitor) Artifact() (*Artifact, error) {
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return nil, err
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	artifact, found, err := unstructured.NestedMap(obj.Object, "status", "artifact")
+	if err != nil || !found {
+		return nil, err
+	}
+	revision, _, _ := unstructured.NestedString(artifact, "revision")
+	digest, _, _ := unstructured.NestedString(artifact, "digest")
+	updated, _, _ := unstructured.NestedString(artifact, "lastUpdateTime")
+	size, _, _ := unstructured.NestedInt64(artifact, "size")
+	metadata, _, _ := unstructured.NestedStringMap(artifact, "metadata")
+	url, _, _ := unstructured.NestedString(obj.Object, "spec", "url")
+	return &Artifact{
+		Revision:       revision,
+		Digest:         digest,
+		LastUpdateTime: updated,
+		Size:           size,
+		Metadata:       metadata,
+		SourceURL:      url,
+		Verification:   sourceVerification(obj),
+	}, nil
+}
+
+// SourceRevisions returns a Kustomization's status.lastAppliedRevision and the
+// artifact revision of its source, which are equal once the source's latest
+// artifact has been applied
+func (m *Monitor) SourceRevisions() (applied, source string, err error) {
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return "", "", err
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	if source, err = m.sourceRevision(m.ctx, obj, m.kind); err != nil {
+		return "", "", err
+	}
+	return objectRevision(obj, m.kind), source, nil
+}
+
+// sourceRevision returns the artifact revision of the source of obj, a
+// resource of the monitor kind kind
+func (m *Monitor) sourceRevision(ctx context.Context, obj *unstructured.Unstructured, kind string) (string, error) {
+	refs, err := sourceRefs(obj, kind)
+	if err != nil {
+		return "", err
+	}
+	sourceGVR, err := discoverGVR(m.clientset.Discovery(), kindGroupResources[refs[0].Kind].WithVersion(""))
+	if err != nil {
+		return "", err
+	}
+	sourceObj, err := m.dynamicClient.Resource(sourceGVR).Namespace(refs[0].Namespace).Get(ctx, refs[0].Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	revision, _, _ := unstructured.NestedString(sourceObj.Object, "status", "artifact", "revision")
+	return revision, nil
+}
+
+// Revision returns the revision the resource last reconciled: a source's
+// artifact revision, a Kustomization's status.lastAppliedRevision, or a
+// HelmRelease's latest chart version from status.history (falling back to
+// status.lastAppliedRevision on older APIs).
+func (m *Monitor) Revision() (string, error) {
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return "", err
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	return objectRevision(obj, m.kind), nil
+}
+
+// objectRevision reads the last reconciled revision of a resource of a
+// monitor kind
+func objectRevision(obj *unstructured.Unstructured, kind string) string {
+	switch kind {
+	case "kustomization":
+		revision, _, _ := unstructured.NestedString(obj.Object, "status", "lastAppliedRevision")
+		return revision
+	case "helmrelease":
+		if history, _, _ := unstructured.NestedSlice(obj.Object, "status", "history"); len(history) > 0 {
+			if latest, ok := history[0].(map[string]interface{}); ok {
+				if version, _, _ := unstructured.NestedString(latest, "chartVersion"); version != "" {
+					return version
+				}
+			}
+		}
+		revision, _, _ := unstructured.NestedString(obj.Object, "status", "lastAppliedRevision")
+		return revision
+	}
+	if revision, _, _ := unstructured.NestedString(obj.Object, "status", "artifact", "revision"); revision != "" {
+		return revision
+	}
+	// Custom resources following the Flux conventions (e.g. Terraform)
+	revision, _, _ := unstructured.NestedString(obj.Object, "status", "lastAppliedRevision")
+	return revision
+}
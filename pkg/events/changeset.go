@@ -0,0 +1,48 @@
+package events
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	eventsv1 "k8s.io/api/events/v1"
+)
+
+// ChangeSetEntry is an object kustomize-controller reported applying, from
+// the change set in its events: "Deployment/apps/web configured"
+type ChangeSetEntry struct {
+	// Object is Kind/namespace/name, or Kind/name when cluster-scoped
+	Object string
+	// Action is created, configured or deleted
+	Action string
+}
+
+// changeSetLine matches a line of a kustomize-controller change set
+var changeSetLine = regexp.MustCompile(`^(\S+/\S+) (created|configured|deleted)$`)
+
+// recordChangeSet collects the change set lines of the resource's events
+// from this run. Callers hold m.mu.
+func (m *Monitor) recordChangeSet(t eventTarget, evt eventsv1.Event) {
+	if t.kind != "" || eventTime(evt).Before(m.createdAt.Truncate(time.Second)) {
+		return
+	}
+	for _, line := range strings.Split(evt.Note, "\n") {
+		match := changeSetLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		entry := ChangeSetEntry{Object: match[1], Action: match[2]}
+		if !m.changeSetSeen[entry] {
+			m.changeSetSeen[entry] = true
+			m.changeSet = append(m.changeSet, entry)
+		}
+	}
+}
+
+// ChangeSet returns the objects the controller reported creating,
+// configuring or deleting during the run, in the order reported
+func (m *Monitor) ChangeSet() []ChangeSetEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ChangeSetEntry(nil), m.changeSet...)
+}
@@ -0,0 +1,150 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ChildStatus is the kstatus state of a Kustomization or HelmRelease applied
+// by the monitored Kustomization, directly or through other Kustomizations
+type ChildStatus struct {
+	Object InventoryObject
+	// Parent is the Kustomization that applied it, as Kind/namespace/name
+	Parent    string
+	Status    string
+	Message   string
+	Suspended bool
+}
+
+// IsFluxChild reports whether the inventory object is a Kustomization or a
+// HelmRelease, which reconcile on their own after being applied
+func (o InventoryObject) IsFluxChild() bool {
+	return (o.Group == "kustomize.toolkit.fluxcd.io" && o.Kind == "Kustomization") ||
+		(o.Group == "helm.toolkit.fluxcd.io" && o.Kind == "HelmRelease")
+}
+
+// ChildrenHealth computes the state of the Kustomizations and HelmReleases in
+// the Kustomization's inventory, and of those in the inventories of these
+// Kustomizations in turn (app-of-apps). The children of a Kustomization are
+// only known once it is Current, so the list grows as they become ready.
+// A ready Kustomization is only Current once it applied its source's
+// artifact, and, unless since is zero, a ready HelmRelease once it handled a
+// reconcile or turned Ready after since.
+func (m *Monitor) ChildrenHealth(ctx context.Context, since time.Time) ([]ChildStatus, error) {
+	objects, err := m.Inventory()
+	if err != nil {
+		return nil, err
+	}
+	root := fmt.Sprintf("Kustomization/%s/%s", m.namespace, m.name)
+	// A Kustomization may list itself, like flux-system does
+	seen := map[string]bool{root: true}
+	type pending struct {
+		object InventoryObject
+		parent string
+	}
+	var queue []pending
+	enqueue := func(objects []InventoryObject, parent string) {
+		for _, o := range objects {
+			if o.IsFluxChild() && !seen[o.String()] {
+				seen[o.String()] = true
+				queue = append(queue, pending{o, parent})
+			}
+		}
+	}
+	enqueue(objects, root)
+
+	var statuses []ChildStatus
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		child := ChildStatus{Object: next.object, Parent: next.parent}
+		obj, missing, err := m.inventoryObject(ctx, next.object)
+		if err != nil {
+			return nil, err
+		}
+		if obj == nil {
+			child.Status, child.Message = StatusNotFound, missing
+			statuses = append(statuses, child)
+			continue
+		}
+		child.Status, child.Message = childStatus(obj)
+		if child.Status == StatusCurrent {
+			if child.Status, child.Message, err = m.childUpToDate(ctx, obj, next.object.Kind, since); err != nil {
+				return nil, err
+			}
+		}
+		child.Suspended, _, _ = unstructured.NestedBool(obj.Object, "spec", "suspend")
+		statuses = append(statuses, child)
+		if child.Status == StatusCurrent && next.object.Kind == "Kustomization" {
+			grandchildren, err := inventoryObjects(obj)
+			if err != nil {
+				return nil, err
+			}
+			enqueue(grandchildren, next.object.String())
+		}
+	}
+	return statuses, nil
+}
+
+// childStatus is the kstatus state of a Flux object, which is only Current
+// once its controller reported it Ready
+func childStatus(obj *unstructured.Unstructured) (string, string) {
+	status, message := ComputeStatus(obj)
+	if status != StatusCurrent {
+		return status, message
+	}
+	if ready, _, _ := conditionStatus(obj, "Ready"); ready != "True" {
+		return StatusInProgress, "waiting for the controller to report readiness"
+	}
+	return status, message
+}
+
+// childUpToDate checks that a ready child reconciled what this run changed:
+// a Kustomization must have applied the revision of its source's artifact,
+// and a HelmRelease must have handled a reconcile request or turned Ready
+// after since
+func (m *Monitor) childUpToDate(ctx context.Context, obj *unstructured.Unstructured, kind string, since time.Time) (string, string, error) {
+	switch kind {
+	case "Kustomization":
+		source, err := m.sourceRevision(ctx, obj, "kustomization")
+		if err != nil {
+			return "", "", err
+		}
+		applied, _, _ := unstructured.NestedString(obj.Object, "status", "lastAppliedRevision")
+		if source != "" && applied != source {
+			return StatusInProgress, fmt.Sprintf("applied %s, its source is at %s", applied, source), nil
+		}
+	case "HelmRelease":
+		if since.IsZero() {
+			break
+		}
+		handled, _, _ := unstructured.NestedString(obj.Object, "status", "lastHandledReconcileAt")
+		if t, err := time.Parse(time.RFC3339Nano, handled); err == nil && !t.Before(since) {
+			break
+		}
+		if t := conditionTransition(obj, "Ready"); !t.Before(since) {
+			break
+		}
+		return StatusInProgress, "waiting for a reconcile since the run started", nil
+	}
+	return StatusCurrent, "", nil
+}
+
+// conditionTransition returns the lastTransitionTime of a condition, or the
+// zero time
+func conditionTransition(obj *unstructured.Unstructured, condType string) time.Time {
+	list, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range list {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != condType {
+			continue
+		}
+		transition, _, _ := unstructured.NestedString(cond, "lastTransitionTime")
+		t, _ := time.Parse(time.RFC3339, transition)
+		return t
+	}
+	return time.Time{}
+}
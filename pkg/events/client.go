@@ -0,0 +1,255 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ClientOptions configures how the Kubernetes clients connect to a cluster
+type ClientOptions struct {
+	// Context is the kubeconfig context to use; empty means the current one
+	Context string
+	// InCluster only uses the pod's service account, never a kubeconfig
+	InCluster bool
+	// As is the user to impersonate, like kubectl's --as
+	As string
+	// AsGroups are the groups to impersonate, like kubectl's --as-group
+	AsGroups []string
+	// QPS and Burst limit the client-side request rate (--kube-qps and
+	// --kube-burst default to 50 and 100); zero keeps the client-go defaults
+	// of 5 and 10
+	QPS   float64
+	Burst int
+	// RequestTimeout bounds every API request except watches and followed
+	// log streams, which last as long as their context; zero means no
+	// timeout
+	RequestTimeout time.Duration
+	// SuppressWarnings drops the warnings returned by the API server
+	// instead of printing them
+	SuppressWarnings bool
+	// Printer prints the warnings returned by the API server; nil means the
+	// default printer
+	Printer *output.Printer
+}
+
+// FluxArgs returns the flags passing the options on to the flux CLI
+func (o ClientOptions) FluxArgs() []string {
+	var args []string
+	if o.Context != "" {
+		args = append(args, "--context", o.Context)
+	}
+	if o.As != "" {
+		args = append(args, "--as", o.As)
+	}
+	for _, group := range o.AsGroups {
+		args = append(args, "--as-group", group)
+	}
+	return args
+}
+
+// serviceAccountNamespaceFile holds the namespace of a pod's service account
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// InClusterNamespace returns the namespace of the pod's service account, or
+// "" when not running in a pod
+func InClusterNamespace() string {
+	data, err := os.ReadFile(serviceAccountNamespaceFile)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func getKubeConfig(opts ClientOptions) (*rest.Config, error) {
+	if len(opts.AsGroups) > 0 && opts.As == "" {
+		return nil, fmt.Errorf("impersonating groups requires a user (--as)")
+	}
+
+	if opts.InCluster {
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, err
+		}
+		configure(config, opts)
+		return config, nil
+	}
+
+	// Try in-cluster config first, unless a specific context was requested
+	if opts.Context == "" {
+		config, err := rest.InClusterConfig()
+		if err == nil {
+			configure(config, opts)
+			return config, nil
+		}
+	}
+
+	// Fall back to kubeconfig files (KUBECONFIG, or ~/.kube/config)
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: opts.Context}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	configure(config, opts)
+	return config, nil
+}
+
+// configure applies the rate limits and impersonation from opts that are
+// set, and routes API server warnings through pkg/output. The request
+// timeout is applied per request by timeoutTransport instead of through
+// config.Timeout, which would cut off watches and log streams as well.
+func configure(config *rest.Config, opts ClientOptions) {
+	out := opts.Printer
+	if out == nil {
+		out = output.FromContext(context.Background())
+	}
+	config.WarningHandler = apiWarnings{out: out, suppress: opts.SuppressWarnings}
+	if opts.QPS > 0 {
+		config.QPS = float32(opts.QPS)
+	}
+	if opts.Burst > 0 {
+		config.Burst = opts.Burst
+	}
+	if opts.As != "" {
+		config.Impersonate = rest.ImpersonationConfig{
+			UserName: opts.As,
+			Groups:   opts.AsGroups,
+		}
+	}
+}
+
+// newClients creates the typed and dynamic clients for opts. They share an
+// HTTP client that survives expiring credentials (see reauthTransport).
+func newClients(opts ClientOptions) (*kubernetes.Clientset, dynamic.Interface, error) {
+	config, err := getKubeConfig(opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	rt, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create transport: %w", err)
+	}
+	var transport http.RoundTripper = &reauthTransport{opts: opts, current: rt}
+	if opts.RequestTimeout > 0 {
+		transport = &timeoutTransport{timeout: opts.RequestTimeout, next: transport}
+	}
+	httpClient := &http.Client{Transport: transport}
+
+	clientset, err := kubernetes.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	return clientset, dynamicClient, nil
+}
+
+// timeoutTransport bounds each request, including the read of its response
+// body, by a timeout. Watches and followed log streams are left to their
+// context.
+type timeoutTransport struct {
+	timeout time.Duration
+	next    http.RoundTripper
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	query := req.URL.Query()
+	if query.Get("watch") == "true" || query.Get("watch") == "1" || query.Get("follow") == "true" {
+		return t.next.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases the context of a request once its response body is
+// closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// reauthTransport retries a request answered with 401 Unauthorized once,
+// through a transport built from freshly loaded credentials. Exec plugins
+// are run again and a kubeconfig rewritten by a cloud CLI is re-read, so
+// short-lived tokens expiring during a long wait don't abort it.
+type reauthTransport struct {
+	opts    ClientOptions
+	mu      sync.Mutex
+	current http.RoundTripper
+}
+
+func (t *reauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	rt := t.current
+	t.mu.Unlock()
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	// Requests whose body can't be replayed are returned as they are
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	fresh, err := t.reload(rt)
+	if err != nil {
+		return resp, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	resp.Body.Close()
+	return fresh.RoundTrip(retry)
+}
+
+// reload replaces the stale transport with one using fresh credentials,
+// unless a concurrent request already did
+func (t *reauthTransport) reload(stale http.RoundTripper) (http.RoundTripper, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current != stale {
+		return t.current, nil
+	}
+	config, err := getKubeConfig(t.opts)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, err
+	}
+	t.current = rt
+	return rt, nil
+}
@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Cluster gives access to cluster-wide checks that are not tied to a single
+// monitored resource.
+type Cluster struct {
+	clientset     *kubernetes.Clientset
+	dynamicClient dynamic.Interface
+}
+
+// ControllerStatus is the rollout state of a controller Deployment
+type ControllerStatus struct {
+	Name      string
+	Ready     bool
+	Desired   int32
+	Available int32
+	Message   string
+}
+
+// FluxCRDs lists the resources (by API group) that Flux needs to be served
+var FluxCRDs = map[string][]string{
+	"source.toolkit.fluxcd.io":    {"gitrepositories", "ocirepositories", "helmrepositories", "helmcharts", "buckets"},
+	"kustomize.toolkit.fluxcd.io": {"kustomizations"},
+	"helm.toolkit.fluxcd.io":      {"helmreleases"},
+}
+
+func NewCluster(clientOpts ClientOptions) (*Cluster, error) {
+	clientset, dynamicClient, err := newClients(clientOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cluster{clientset: clientset, dynamicClient: dynamicClient}, nil
+}
+
+// ControllerHealth returns the rollout state of every Deployment in the
+// namespace where the Flux controllers run.
+func (c *Cluster) ControllerHealth(ctx context.Context, namespace string) ([]ControllerStatus, error) {
+	deployments, err := c.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list controllers in %s: %w", namespace, err)
+	}
+
+	statuses := make([]ControllerStatus, 0, len(deployments.Items))
+	for _, d := range deployments.Items {
+		desired := int32(1)
+		if d.Spec.Replicas != nil {
+			desired = *d.Spec.Replicas
+		}
+		status := ControllerStatus{
+			Name:      d.Name,
+			Desired:   desired,
+			Available: d.Status.AvailableReplicas,
+		}
+
+		switch {
+		case d.Status.ObservedGeneration < d.Generation:
+			status.Message = "rollout not yet observed"
+		case d.Status.UpdatedReplicas < desired:
+			status.Message = fmt.Sprintf("%d of %d replicas updated", d.Status.UpdatedReplicas, desired)
+		case d.Status.AvailableReplicas < desired:
+			status.Message = fmt.Sprintf("%d of %d replicas available", d.Status.AvailableReplicas, desired)
+		case d.Status.Replicas > d.Status.UpdatedReplicas:
+			status.Message = fmt.Sprintf("%d old replicas pending termination", d.Status.Replicas-d.Status.UpdatedReplicas)
+		default:
+			status.Ready = true
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// MissingCRDs returns the Flux resources that the API server does not serve
+func (c *Cluster) MissingCRDs() ([]string, error) {
+	groups, err := c.clientset.Discovery().ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover API groups: %w", err)
+	}
+
+	served := map[string]bool{}
+	for _, group := range groups.Groups {
+		if _, ok := FluxCRDs[group.Name]; !ok {
+			continue
+		}
+		resources, err := c.clientset.Discovery().ServerResourcesForGroupVersion(group.PreferredVersion.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range resources.APIResources {
+			served[r.Name+"."+group.Name] = true
+		}
+	}
+
+	var missing []string
+	for group, resources := range FluxCRDs {
+		for _, r := range resources {
+			if !served[r+"."+group] {
+				missing = append(missing, r+"."+group)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
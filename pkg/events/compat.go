@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ExpectedAPIVersions are the API versions the CLI uses for each Flux
+// resource, most preferred first. Keep in sync with getResourceGVR.
+var ExpectedAPIVersions = map[schema.GroupResource][]string{
+	{Group: "kustomize.toolkit.fluxcd.io", Resource: "kustomizations"}: {"v1"},
+	{Group: "helm.toolkit.fluxcd.io", Resource: "helmreleases"}:        {"v2", "v2beta1"},
+	{Group: "source.toolkit.fluxcd.io", Resource: "gitrepositories"}:   {"v1"},
+	{Group: "source.toolkit.fluxcd.io", Resource: "ocirepositories"}:   {"v1beta2"},
+	{Group: "source.toolkit.fluxcd.io", Resource: "buckets"}:           {"v1beta2"},
+}
+
+// ControllerVersion is the version of a Flux controller Deployment
+type ControllerVersion struct {
+	Name string
+	// Distribution is the Flux release from the app.kubernetes.io/version label
+	Distribution string
+	// Version is the tag of the controller's image
+	Version string
+}
+
+// APIVersions are the versions the API server serves for a Flux resource
+type APIVersions struct {
+	Resource schema.GroupResource
+	Served   []string
+	Expected []string
+}
+
+// Compatible reports whether one of the expected versions is served
+func (a APIVersions) Compatible() bool {
+	for _, v := range a.Expected {
+		if slices.Contains(a.Served, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ControllerVersions returns the versions of the Flux controllers in the
+// namespace, found by their app.kubernetes.io/part-of=flux label
+func (c *Cluster) ControllerVersions(ctx context.Context, namespace string) ([]ControllerVersion, error) {
+	deployments, err := c.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: "app.kubernetes.io/part-of=flux"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list controllers in %s: %w", namespace, err)
+	}
+
+	versions := make([]ControllerVersion, 0, len(deployments.Items))
+	for _, d := range deployments.Items {
+		versions = append(versions, ControllerVersion{
+			Name:         d.Name,
+			Distribution: d.Labels["app.kubernetes.io/version"],
+			Version:      imageTag(d),
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Name < versions[j].Name })
+	return versions, nil
+}
+
+// imageTag returns the tag of the Deployment's manager container, or of its
+// first container
+func imageTag(d appsv1.Deployment) string {
+	containers := d.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return ""
+	}
+	image := containers[0].Image
+	for _, c := range containers {
+		if c.Name == "manager" {
+			image = c.Image
+		}
+	}
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// ServedAPIVersions returns the versions served for every resource in
+// ExpectedAPIVersions. Resources that aren't installed have no Served
+// versions.
+func (c *Cluster) ServedAPIVersions() ([]APIVersions, error) {
+	groups, err := c.clientset.Discovery().ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover API groups: %w", err)
+	}
+
+	fluxGroups := map[string]bool{}
+	for gr := range ExpectedAPIVersions {
+		fluxGroups[gr.Group] = true
+	}
+	served := map[schema.GroupResource][]string{}
+	for _, group := range groups.Groups {
+		if !fluxGroups[group.Name] {
+			continue
+		}
+		for _, version := range group.Versions {
+			resources, err := c.clientset.Discovery().ServerResourcesForGroupVersion(version.GroupVersion)
+			if err != nil {
+				continue
+			}
+			for _, r := range resources.APIResources {
+				gr := schema.GroupResource{Group: group.Name, Resource: r.Name}
+				if _, ok := ExpectedAPIVersions[gr]; ok {
+					served[gr] = append(served[gr], version.Version)
+				}
+			}
+		}
+	}
+
+	apis := make([]APIVersions, 0, len(ExpectedAPIVersions))
+	for gr, expected := range ExpectedAPIVersions {
+		apis = append(apis, APIVersions{Resource: gr, Served: served[gr], Expected: expected})
+	}
+	sort.Slice(apis, func(i, j int) bool { return apis[i].Resource.String() < apis[j].Resource.String() })
+	return apis, nil
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ConditionRequirement is a status condition the resource must report for
+// the wait to succeed, e.g. Released=True
+type ConditionRequirement struct {
+	Type   string
+	Status string
+}
+
+func (r ConditionRequirement) String() string {
+	return r.Type + "=" + r.Status
+}
+
+// DefaultReadyConditions is the requirement used when none is given
+var DefaultReadyConditions = []ConditionRequirement{{Type: "Ready", Status: "True"}}
+
+// ParseConditions parses "Type" or "Type=Status" specs; specs without a
+// status require defaultStatus (True, False or Unknown).
+func ParseConditions(specs []string, defaultStatus string) ([]ConditionRequirement, error) {
+	if !validConditionStatus(defaultStatus) {
+		return nil, fmt.Errorf("invalid condition status '%s'. Valid statuses: True, False, Unknown", defaultStatus)
+	}
+	var requirements []ConditionRequirement
+	for _, spec := range specs {
+		condType, status, found := strings.Cut(spec, "=")
+		if !found {
+			status = defaultStatus
+		}
+		if condType == "" || !validConditionStatus(status) {
+			return nil, fmt.Errorf("invalid condition '%s', expected <type> or <type>=<True|False|Unknown>", spec)
+		}
+		requirement := ConditionRequirement{Type: condType, Status: status}
+		// A later spec for the same type replaces the earlier one
+		if i := slices.IndexFunc(requirements, func(r ConditionRequirement) bool { return r.Type == condType }); i >= 0 {
+			requirements[i] = requirement
+			continue
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
+
+func validConditionStatus(status string) bool {
+	return status == "True" || status == "False" || status == "Unknown"
+}
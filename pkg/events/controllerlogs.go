@@ -0,0 +1,177 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// kindControllers maps monitor kinds to the Flux controller reconciling them
+// and the kind it logs as controllerKind
+var kindControllers = map[string][2]string{
+	"helmrelease":   {"helm-controller", "HelmRelease"},
+	"kustomization": {"kustomize-controller", "Kustomization"},
+	"git":           {"source-controller", "GitRepository"},
+	"oci":           {"source-controller", "OCIRepository"},
+	"bucket":        {"source-controller", "Bucket"},
+}
+
+// Controller returns the Flux controller that reconciles the resource, or ""
+// for kinds without one
+func (m *Monitor) Controller() string {
+	return kindControllers[m.kind][0]
+}
+
+// ControllerKind returns the Kubernetes kind of a monitor kind as the Flux
+// controllers and CLI log it, or "" for kinds without a controller
+func ControllerKind(kind string) string {
+	return kindControllers[kind][1]
+}
+
+// logControllers are the controllers whose logs TailAllControllerLogs
+// multiplexes
+var logControllers = []string{"kustomize-controller", "source-controller", "helm-controller"}
+
+// controllerColor tells the controllers apart in multiplexed logs
+func controllerColor(controller string) string {
+	switch controller {
+	case "kustomize-controller":
+		return output.ColorBlue
+	case "source-controller":
+		return output.ColorCyan
+	case "helm-controller":
+		return output.ColorMagenta
+	default:
+		return output.ColorYellow
+	}
+}
+
+// TailControllerLogs follows the logs of the resource's controller in the
+// namespace of the Flux installation and prints the structured log lines
+// about the resource, logged since the given time, until ctx is done
+func (m *Monitor) TailControllerLogs(ctx context.Context, fluxNamespace string, since time.Time) error {
+	controller := m.Controller()
+	if controller == "" {
+		return fmt.Errorf("no Flux controller reconciles %s resources", m.kind)
+	}
+	pods, err := m.controllerPods(ctx, fluxNamespace, controller)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no %s pods in %s", controller, fluxNamespace)
+	}
+	m.followControllerLogs(ctx, fluxNamespace, map[string][]string{controller: pods}, since, false)
+	return nil
+}
+
+// TailAllControllerLogs follows the logs of kustomize-controller,
+// source-controller and helm-controller at once and prints the lines about
+// the resource and its sources, each prefixed with the color-coded
+// controller name, from the given time until ctx is done. Controllers that
+// aren't installed are skipped.
+func (m *Monitor) TailAllControllerLogs(ctx context.Context, fluxNamespace string, since time.Time) error {
+	pods := make(map[string][]string, len(logControllers))
+	for _, controller := range logControllers {
+		names, err := m.controllerPods(ctx, fluxNamespace, controller)
+		if err != nil {
+			return err
+		}
+		if len(names) > 0 {
+			pods[controller] = names
+		}
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no Flux controller pods in %s", fluxNamespace)
+	}
+	m.followControllerLogs(ctx, fluxNamespace, pods, since, true)
+	return nil
+}
+
+// controllerPods returns the names of the pods of a controller
+func (m *Monitor) controllerPods(ctx context.Context, namespace, controller string) ([]string, error) {
+	pods, err := m.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "app=" + controller})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s: %w", controller, err)
+	}
+	names := make([]string, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	return names, nil
+}
+
+// followControllerLogs follows the pods of every controller concurrently.
+// With all set, lines about the resource's sources are shown too and every
+// line is prefixed with its controller.
+func (m *Monitor) followControllerLogs(ctx context.Context, namespace string, pods map[string][]string, since time.Time, all bool) {
+	sinceTime := metav1.NewTime(since)
+	var wg sync.WaitGroup
+	for controller, names := range pods {
+		for _, pod := range names {
+			wg.Add(1)
+			go func(controller, pod string) {
+				defer wg.Done()
+				m.followControllerLog(ctx, namespace, pod, controller, sinceTime, all)
+			}(controller, pod)
+		}
+	}
+	wg.Wait()
+}
+
+func (m *Monitor) followControllerLog(ctx context.Context, namespace, pod, controller string, since metav1.Time, all bool) {
+	stream, err := m.clientset.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{
+		Container: "manager",
+		Follow:    true,
+		SinceTime: &since,
+	}).Stream(ctx)
+	if err != nil {
+		return
+	}
+	defer stream.Close()
+
+	out := output.FromContext(ctx)
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line, ok := ParseLogLine(scanner.Bytes())
+		if !ok || !m.logLineInvolves(line, all) {
+			continue
+		}
+		// In the multiplexed logs, name any other object than the resource
+		self := line.Name == m.name && line.Namespace == m.namespace
+		message := output.Preview(line.Format(all && !self))
+		switch {
+		case all:
+			out.PrintLogLine(controller, controllerColor(controller), message, line.Level == "error")
+		case line.Level == "error":
+			out.PrintWarning(fmt.Sprintf("📜 %s: %s", controller, message))
+		default:
+			out.PrintSublog(fmt.Sprintf("📜 %s: %s", controller, message))
+		}
+	}
+}
+
+// logLineInvolves reports whether a log line is about the resource or, with
+// sources set, one of the sources added by WatchSources
+func (m *Monitor) logLineInvolves(line LogLine, sources bool) bool {
+	if line.Name == m.name && line.Namespace == m.namespace && (line.Kind == "" || line.Kind == kindControllers[m.kind][1]) {
+		return true
+	}
+	if !sources || line.Kind == "" {
+		return false
+	}
+	for _, ref := range m.Sources() {
+		if line.Kind == ref.APIKind && line.Name == ref.Name && line.Namespace == ref.Namespace {
+			return true
+		}
+	}
+	return false
+}
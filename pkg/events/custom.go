@@ -0,0 +1,69 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ParseGVR parses "group/version/resource", or "version/resource" for the
+// core group
+func ParseGVR(s string) (schema.GroupVersionResource, error) {
+	parts := strings.Split(s, "/")
+	for _, p := range parts {
+		if p == "" {
+			return schema.GroupVersionResource{}, fmt.Errorf("invalid resource %q, expected group/version/resource", s)
+		}
+	}
+	switch len(parts) {
+	case 2:
+		return schema.GroupVersionResource{Version: parts[0], Resource: parts[1]}, nil
+	case 3:
+		return schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}, nil
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("invalid resource %q, expected group/version/resource", s)
+}
+
+// NewCustomMonitor returns a monitor for any resource that reports its state
+// through status conditions (e.g. tf-controller Terraforms, Crossplane
+// claims).
+func NewCustomMonitor(ctx context.Context, clientOpts ClientOptions, gvr schema.GroupVersionResource, name, namespace string) (*Monitor, error) {
+	m, err := NewMonitor(ctx, clientOpts, gvr.Resource, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	m.gvr = &gvr
+	return m, nil
+}
+
+// TracksReconcileRequests reports whether the resource records handled
+// reconcile requests in status.lastHandledReconcileAt, as Flux controllers do
+func (m *Monitor) TracksReconcileRequests() bool {
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return false
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	_, found, _ := unstructured.NestedString(obj.Object, "status", "lastHandledReconcileAt")
+	return found
+}
+
+// RequestReconcileGVR sets the reconcile.fluxcd.io/requestedAt annotation on
+// a resource of any type, and returns the requested token. Controllers that
+// don't follow the Flux convention ignore it.
+func (c *Cluster) RequestReconcileGVR(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (string, error) {
+	patch, token, err := reconcileRequestPatch(false, nil)
+	if err != nil {
+		return "", err
+	}
+	_, err = c.dynamicClient.Resource(gvr).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return token, err
+}
@@ -0,0 +1,190 @@
+package events
+
+import (
+	"fmt"
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SOPS key providers, as named in the kustomize-controller's decryption
+// secret keys (sops.aws-kms, ...) and spec.decryption
+const (
+	KeyProviderAge   = "age"
+	KeyProviderPGP   = "pgp"
+	KeyProviderAWS   = "aws-kms"
+	KeyProviderGCP   = "gcp-kms"
+	KeyProviderAzure = "azure-kv"
+	KeyProviderVault = "vault"
+)
+
+var (
+	// decryptionPattern matches the messages of SOPS decryption failures
+	decryptionPattern = regexp.MustCompile(`(?i)\bsops\b|decrypt|age identit|no identity matched|failed to get the data key`)
+	// decryptedFilePattern matches the file named in "failed to decrypt and
+	// format 'apps/secret.yaml': ..."
+	decryptedFilePattern = regexp.MustCompile(`decrypt and format '([^']+)'`)
+	// secretErrorPattern matches a decryption Secret that can't be read
+	secretErrorPattern   = regexp.MustCompile(`secrets? "([^"]+)" (not found|is forbidden)`)
+	forbiddenUserPattern = regexp.MustCompile(`User "([^"]+)" cannot`)
+
+	// keyPatterns recognize the SOPS master keys named in failure messages,
+	// most specific first. The last ones only recognize the provider.
+	keyPatterns = []struct {
+		provider string
+		pattern  *regexp.Regexp
+		isKey    bool
+	}{
+		{KeyProviderAWS, regexp.MustCompile(`arn:aws[\w-]*:kms:[\w-]+:\d+:(?:key|alias)/[\w/-]+`), true},
+		{KeyProviderGCP, regexp.MustCompile(`projects/[^/\s]+/locations/[^/\s]+/keyRings/[^/\s]+/cryptoKeys/[^/\s:]+`), true},
+		{KeyProviderAzure, regexp.MustCompile(`https://[^/\s]+\.vault\.azure\.net/keys/[^\s:]+(?::[^\s:]+)?`), true},
+		{KeyProviderVault, regexp.MustCompile(`https?://\S+/v1/[^\s:]+/keys/[^\s:]+`), true},
+		{KeyProviderAge, regexp.MustCompile(`age1[0-9a-z]{58}`), true},
+		{KeyProviderPGP, regexp.MustCompile(`\b[0-9A-F]{40}\b`), true},
+		{KeyProviderAge, regexp.MustCompile(`(?i)\bage\b`), false},
+		{KeyProviderVault, regexp.MustCompile(`(?i)hc_?vault|/transit/`), false},
+	}
+)
+
+// DecryptionFailure is a failed SOPS decryption of a Kustomization, with
+// what was learned about it from the failure messages and the spec
+type DecryptionFailure struct {
+	Namespace string
+	// Message is the failure message the failure was detected in
+	Message string
+	// File is the encrypted file, when named in the message
+	File string
+	// Provider is the key provider of the master key that failed (one of
+	// the KeyProvider constants), empty when not recognized
+	Provider string
+	// Key is the master key (age recipient, KMS key ARN, ...), when named
+	Key string
+	// Secret is spec.decryption.secretRef.name
+	Secret string
+	// SecretMissing is set when the Secret doesn't exist
+	SecretMissing bool
+	// Forbidden is the user denied reading the Secret, when it was denied
+	Forbidden string
+	// ServiceAccount is the account the controller decrypts as
+	// (spec.decryption.serviceAccountName or spec.serviceAccountName), empty
+	// for its own
+	ServiceAccount string
+}
+
+// parseDecryptionFailure recognizes a SOPS decryption failure in a condition
+// or event message
+func parseDecryptionFailure(message string) (*DecryptionFailure, bool) {
+	if !decryptionPattern.MatchString(message) {
+		return nil, false
+	}
+	f := &DecryptionFailure{Message: message}
+	if match := decryptedFilePattern.FindStringSubmatch(message); match != nil {
+		f.File = match[1]
+	}
+	if match := secretErrorPattern.FindStringSubmatch(message); match != nil {
+		f.Secret = match[1]
+		if match[2] == "not found" {
+			f.SecretMissing = true
+		} else if user := forbiddenUserPattern.FindStringSubmatch(message); user != nil {
+			f.Forbidden = user[1]
+		} else {
+			f.Forbidden = "the controller"
+		}
+	}
+	for _, k := range keyPatterns {
+		if match := k.pattern.FindString(message); match != "" {
+			f.Provider = k.provider
+			if k.isKey {
+				f.Key = match
+			}
+			break
+		}
+	}
+	return f, true
+}
+
+// DecryptionFailure looks for a SOPS decryption failure among the failing
+// conditions and warning events seen during the run, and completes it with
+// the Kustomization's spec.decryption. It returns nil when there is none.
+func (m *Monitor) DecryptionFailure() *DecryptionFailure {
+	if m.kind != "kustomization" {
+		return nil
+	}
+	var failure *DecryptionFailure
+	for _, message := range append(m.FailingConditions(), m.WarningEvents()...) {
+		if f, ok := parseDecryptionFailure(message); ok {
+			failure = f
+			break
+		}
+	}
+	if failure == nil {
+		return nil
+	}
+
+	failure.Namespace = m.namespace
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return failure
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return failure
+	}
+	if failure.Secret == "" {
+		failure.Secret, _, _ = unstructured.NestedString(obj.Object, "spec", "decryption", "secretRef", "name")
+	}
+	failure.ServiceAccount, _, _ = unstructured.NestedString(obj.Object, "spec", "decryption", "serviceAccountName")
+	if failure.ServiceAccount == "" {
+		failure.ServiceAccount, _, _ = unstructured.NestedString(obj.Object, "spec", "serviceAccountName")
+	}
+	return failure
+}
+
+// Hints suggests how to fix the decryption failure: the Secret to create or
+// grant access to, and what the key provider needs. fluxNamespace is where
+// the kustomize-controller runs.
+func (f *DecryptionFailure) Hints(fluxNamespace string) []string {
+	var hints []string
+	switch {
+	case f.SecretMissing:
+		hints = append(hints, fmt.Sprintf("the decryption Secret %s/%s does not exist, create it with the private key or credentials (kubectl -n %s create secret generic %s --from-file=...)", f.Namespace, f.Secret, f.Namespace, f.Secret))
+	case f.Forbidden != "":
+		hints = append(hints, fmt.Sprintf("%s may not read the decryption Secret %s/%s, grant it \"get\" on secrets in namespace %s", f.Forbidden, f.Namespace, f.Secret, f.Namespace))
+	case f.Secret == "" && (f.Provider == KeyProviderAge || f.Provider == KeyProviderPGP):
+		hints = append(hints, fmt.Sprintf("spec.decryption.secretRef is not set, %s private keys can only come from a Secret", f.Provider))
+	}
+
+	secret := "the decryption Secret"
+	if f.Secret != "" {
+		secret = fmt.Sprintf("Secret %s/%s", f.Namespace, f.Secret)
+	}
+	account := fmt.Sprintf("service account %s/kustomize-controller", fluxNamespace)
+	if f.ServiceAccount != "" {
+		account = fmt.Sprintf("service account %s/%s", f.Namespace, f.ServiceAccount)
+	}
+	key := "the key"
+	if f.Key != "" {
+		key = f.Key
+	}
+	switch f.Provider {
+	case KeyProviderAge:
+		hints = append(hints, fmt.Sprintf("%s needs a *.agekey entry holding the age identity of %s", secret, key))
+	case KeyProviderPGP:
+		hints = append(hints, fmt.Sprintf("%s needs a *.asc entry holding the private PGP key %s", secret, key))
+	case KeyProviderAWS:
+		hints = append(hints, fmt.Sprintf("the IAM role of %s (eks.amazonaws.com/role-arn) or the sops.aws-kms entry of %s needs kms:Decrypt on %s", account, secret, key))
+	case KeyProviderGCP:
+		hints = append(hints, fmt.Sprintf("the workload identity of %s or the sops.gcp-kms entry of %s needs roles/cloudkms.cryptoKeyDecrypter on %s", account, secret, key))
+	case KeyProviderAzure:
+		hints = append(hints, fmt.Sprintf("the workload identity of %s or the sops.azure-kv entry of %s needs the decrypt permission (Key Vault Crypto User) on %s", account, secret, key))
+	case KeyProviderVault:
+		hints = append(hints, fmt.Sprintf("the sops.vault-token entry of %s needs a token whose policy allows \"update\" on the transit decrypt path of %s", secret, key))
+	default:
+		hints = append(hints, fmt.Sprintf("check that %s or %s holds a key for one of the recipients in the file's sops metadata", secret, account))
+	}
+	if f.File != "" {
+		hints = append(hints, fmt.Sprintf("verify locally that the key decrypts it: sops --decrypt %s", f.File))
+	}
+	return hints
+}
@@ -0,0 +1,145 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// conditionState is a status condition as compared between checks
+type conditionState struct {
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+}
+
+// conditionChange is a difference in one condition between two checks. An
+// added condition has an empty From, a removed one an empty To.
+type conditionChange struct {
+	Type    string
+	Field   string // status, reason or message
+	From    string
+	To      string
+	Message string
+}
+
+// abnormalTrueConditions are the condition types that report a problem when
+// True
+var abnormalTrueConditions = map[string]bool{"Stalled": true}
+
+func conditionStates(obj *unstructured.Unstructured) []conditionState {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	states := make([]conditionState, 0, len(conditions))
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		state := conditionState{}
+		state.Type, _ = cond["type"].(string)
+		state.Status, _ = cond["status"].(string)
+		state.Reason, _ = cond["reason"].(string)
+		state.Message, _ = cond["message"].(string)
+		states = append(states, state)
+	}
+	return states
+}
+
+// diffConditions returns the changes from prev to cur, in the order of cur
+// followed by the removed conditions
+func diffConditions(prev, cur []conditionState) []conditionChange {
+	previous := make(map[string]conditionState, len(prev))
+	for _, c := range prev {
+		previous[c.Type] = c
+	}
+
+	var changes []conditionChange
+	current := make(map[string]bool, len(cur))
+	for _, c := range cur {
+		current[c.Type] = true
+		p, ok := previous[c.Type]
+		switch {
+		case !ok:
+			changes = append(changes, conditionChange{Type: c.Type, Field: "status", To: c.Status, Message: c.Message})
+		case p.Status != c.Status:
+			change := conditionChange{Type: c.Type, Field: "status", From: p.Status, To: c.Status}
+			if p.Message != c.Message {
+				change.Message = c.Message
+			}
+			changes = append(changes, change)
+		case p.Reason != c.Reason:
+			change := conditionChange{Type: c.Type, Field: "reason", From: p.Reason, To: c.Reason}
+			if p.Message != c.Message {
+				change.Message = c.Message
+			}
+			changes = append(changes, change)
+		case p.Message != c.Message:
+			changes = append(changes, conditionChange{Type: c.Type, Field: "message", Message: c.Message})
+		}
+	}
+	for _, p := range prev {
+		if !current[p.Type] {
+			changes = append(changes, conditionChange{Type: p.Type, Field: "status", From: p.Status})
+		}
+	}
+	return changes
+}
+
+// recordConditionStates stores the conditions of obj, queueing the changes
+// since the previous check for printConditionChanges. The first check only
+// sets the baseline.
+func (m *Monitor) recordConditionStates(obj *unstructured.Unstructured) {
+	states := conditionStates(obj)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.conditionStates != nil {
+		m.conditionChanges = append(m.conditionChanges, diffConditions(m.conditionStates, states)...)
+	}
+	m.conditionStates = states
+}
+
+// printConditionChanges prints and clears the queued condition changes
+func (m *Monitor) printConditionChanges(out *output.Printer) {
+	m.mu.Lock()
+	changes := m.conditionChanges
+	m.conditionChanges = nil
+	m.mu.Unlock()
+
+	for _, c := range changes {
+		switch c.Field {
+		case "status":
+			out.PrintChange(c.Type, orNone(c.From), orNone(c.To), conditionColor(c.Type, c.To))
+		case "reason":
+			out.PrintChange(c.Type+" reason", orNone(c.From), orNone(c.To), output.ColorCyan)
+		}
+		switch {
+		case c.Message == "":
+		case c.Field == "message":
+			out.PrintSublog(fmt.Sprintf("🔄 %s message changed: %s", c.Type, output.Preview(c.Message)))
+		default:
+			out.PrintSublog(fmt.Sprintf("   %s", output.Preview(c.Message)))
+		}
+	}
+}
+
+// conditionColor is the color of a condition status: green when healthy, red
+// when not and yellow when unknown or still reconciling
+func conditionColor(condType, status string) string {
+	switch {
+	case status != "True" && status != "False", condType == "Reconciling" && status == "True":
+		return output.ColorYellow
+	case (status == "True") != abnormalTrueConditions[condType]:
+		return output.ColorGreen
+	default:
+		return output.ColorRed
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+package events
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Dependent is a Kustomization that applies the artifact of a source
+type Dependent struct {
+	Namespace string
+	Name      string
+	Suspended bool
+}
+
+// DependentKustomizations returns the Kustomizations in all namespaces whose
+// sourceRef is the given source
+func (c *Cluster) DependentKustomizations(ctx context.Context, source SourceRef) ([]Dependent, error) {
+	items, err := c.ListResources(ctx, ListOptions{Kind: "kustomization"})
+	if err != nil {
+		return nil, err
+	}
+	var dependents []Dependent
+	for i := range items {
+		refs, err := sourceRefs(&items[i], "kustomization")
+		if err != nil {
+			continue
+		}
+		ref := refs[0]
+		if ref.APIKind != source.APIKind || ref.Namespace != source.Namespace || ref.Name != source.Name {
+			continue
+		}
+		suspended, _, _ := unstructured.NestedBool(items[i].Object, "spec", "suspend")
+		dependents = append(dependents, Dependent{Namespace: items[i].GetNamespace(), Name: items[i].GetName(), Suspended: suspended})
+	}
+	return dependents, nil
+}
@@ -0,0 +1,215 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DescribeField is a labelled value of a Description
+type DescribeField struct {
+	Name  string
+	Value string
+}
+
+// DescribedCondition is a status condition of a described resource
+type DescribedCondition struct {
+	Type           string
+	Status         string
+	Reason         string
+	Message        string
+	LastTransition time.Time
+}
+
+// DescribedEvent is a Kubernetes event recorded for a described resource
+type DescribedEvent struct {
+	Type    string
+	Reason  string
+	Message string
+	// Count is how often the event occurred, at least 1
+	Count int32
+	Last  time.Time
+}
+
+// Description is what "describe" reports about a resource of a monitor kind
+type Description struct {
+	Object *unstructured.Unstructured
+	// Spec holds the highlights of the spec, the ones without a value left out
+	Spec       []DescribeField
+	Conditions []DescribedCondition
+	// Revisions holds the revisions last applied, attempted or fetched
+	Revisions []DescribeField
+	// History is a HelmRelease's status.history, newest first
+	History []HelmReleaseSnapshot
+	// Events are the most recent events, oldest first
+	Events []DescribedEvent
+	// EventsErr is set when the events couldn't be listed
+	EventsErr error
+}
+
+// specFields are the plain spec fields shown by Describe, in order
+var specFields = []struct {
+	name   string
+	fields []string
+}{
+	{"Interval", []string{"spec", "interval"}},
+	{"Timeout", []string{"spec", "timeout"}},
+	{"URL", []string{"spec", "url"}},
+	{"Bucket", []string{"spec", "bucketName"}},
+	{"Path", []string{"spec", "path"}},
+	{"Release Name", []string{"spec", "releaseName"}},
+	{"Target Namespace", []string{"spec", "targetNamespace"}},
+}
+
+// revisionFields are the status fields Describe shows as revisions, in order
+var revisionFields = []struct {
+	name   string
+	fields []string
+}{
+	{"Last Applied", []string{"status", "lastAppliedRevision"}},
+	{"Last Attempted", []string{"status", "lastAttemptedRevision"}},
+	{"Last Planned", []string{"status", "lastPlannedRevision"}},
+	{"Artifact", []string{"status", "artifact", "revision"}},
+	{"Artifact Updated", []string{"status", "artifact", "lastUpdateTime"}},
+	{"Last Handled Reconcile", []string{"status", "lastHandledReconcileAt"}},
+}
+
+// Describe reads a resource of a monitor kind with its recent events, up to
+// maxEvents of them
+func (c *Cluster) Describe(ctx context.Context, kind, namespace, name string, maxEvents int) (*Description, error) {
+	obj, err := c.GetResource(ctx, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	d := &Description{Object: obj, Spec: describeSpec(obj, kind)}
+
+	for _, f := range revisionFields {
+		if value, _, _ := unstructured.NestedString(obj.Object, f.fields...); value != "" {
+			d.Revisions = append(d.Revisions, DescribeField{Name: f.name, Value: value})
+		}
+	}
+	if kind == "helmrelease" {
+		history, _, _ := unstructured.NestedSlice(obj.Object, "status", "history")
+		d.History = historySnapshots(history)
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range conditions {
+		cond, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		dc := DescribedCondition{}
+		dc.Type, _, _ = unstructured.NestedString(cond, "type")
+		dc.Status, _, _ = unstructured.NestedString(cond, "status")
+		dc.Reason, _, _ = unstructured.NestedString(cond, "reason")
+		dc.Message, _, _ = unstructured.NestedString(cond, "message")
+		if transition, _, _ := unstructured.NestedString(cond, "lastTransitionTime"); transition != "" {
+			dc.LastTransition, _ = time.Parse(time.RFC3339, transition)
+		}
+		d.Conditions = append(d.Conditions, dc)
+	}
+
+	target := eventTarget{kind: obj.GetKind(), namespace: namespace, name: name}
+	list, err := newEventClient(c.clientset, namespace).List(ctx, metav1.ListOptions{FieldSelector: target.fieldSelector()})
+	if err != nil {
+		d.EventsErr = err
+		return d, nil
+	}
+	items := list.Items
+	sort.SliceStable(items, func(i, j int) bool { return eventTime(items[i]).Before(eventTime(items[j])) })
+	if maxEvents > 0 && len(items) > maxEvents {
+		items = items[len(items)-maxEvents:]
+	}
+	for _, evt := range items {
+		count := evt.DeprecatedCount
+		if evt.Series != nil && evt.Series.Count > count {
+			count = evt.Series.Count
+		}
+		d.Events = append(d.Events, DescribedEvent{
+			Type:    evt.Type,
+			Reason:  evt.Reason,
+			Message: strings.TrimSpace(evt.Note),
+			Count:   max(count, 1),
+			Last:    eventTime(evt),
+		})
+	}
+	return d, nil
+}
+
+// describeSpec returns the spec highlights of a resource: its interval, what
+// it applies or fetches, its source, dependencies and suspension
+func describeSpec(obj *unstructured.Unstructured, kind string) []DescribeField {
+	var spec []DescribeField
+	add := func(name, value string) {
+		if value != "" {
+			spec = append(spec, DescribeField{Name: name, Value: value})
+		}
+	}
+
+	for _, f := range specFields {
+		value, _, _ := unstructured.NestedString(obj.Object, f.fields...)
+		add(f.name, value)
+	}
+
+	switch kind {
+	case "helmrelease":
+		if chart, _, _ := unstructured.NestedString(obj.Object, "spec", "chart", "spec", "chart"); chart != "" {
+			version, _, _ := unstructured.NestedString(obj.Object, "spec", "chart", "spec", "version")
+			if version == "" {
+				version = "*"
+			}
+			add("Chart", chart+"@"+version)
+		}
+		if source := sourceRefString(obj, "spec", "chart", "spec", "sourceRef"); source != "" {
+			add("Source", source)
+		} else {
+			add("Source", sourceRefString(obj, "spec", "chartRef"))
+		}
+	case "helmchart":
+		chart, _, _ := unstructured.NestedString(obj.Object, "spec", "chart")
+		version, _, _ := unstructured.NestedString(obj.Object, "spec", "version")
+		if chart != "" && version != "" {
+			chart += "@" + version
+		}
+		add("Chart", chart)
+		add("Source", sourceRefString(obj, "spec", "sourceRef"))
+	case "git", "oci":
+		ref, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "ref")
+		for _, key := range []string{"name", "commit", "digest", "semver", "tag", "branch"} {
+			if ref[key] != "" {
+				add("Ref", key+" "+ref[key])
+				break
+			}
+		}
+	default:
+		add("Source", sourceRefString(obj, "spec", "sourceRef"))
+	}
+
+	var dependencies []string
+	dependsOn, _, _ := unstructured.NestedSlice(obj.Object, "spec", "dependsOn")
+	for _, item := range dependsOn {
+		dep, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := dep["name"].(string)
+		if namespace, _ := dep["namespace"].(string); namespace != "" && namespace != obj.GetNamespace() {
+			name = namespace + "/" + name
+		}
+		dependencies = append(dependencies, name)
+	}
+	add("Depends On", strings.Join(dependencies, ", "))
+
+	if prune, found, _ := unstructured.NestedBool(obj.Object, "spec", "prune"); found {
+		add("Prune", fmt.Sprint(prune))
+	}
+	suspended, _, _ := unstructured.NestedBool(obj.Object, "spec", "suspend")
+	add("Suspended", fmt.Sprint(suspended))
+	return spec
+}
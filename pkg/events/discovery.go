@@ -0,0 +1,200 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// kindGroupResources maps monitor kinds to their API group and resource; the
+// version is resolved through discovery.
+var kindGroupResources = map[string]schema.GroupResource{
+	"kustomization":  {Group: "kustomize.toolkit.fluxcd.io", Resource: "kustomizations"},
+	"helmrelease":    {Group: "helm.toolkit.fluxcd.io", Resource: "helmreleases"},
+	"git":            {Group: "source.toolkit.fluxcd.io", Resource: "gitrepositories"},
+	"gitrepository":  {Group: "source.toolkit.fluxcd.io", Resource: "gitrepositories"},
+	"oci":            {Group: "source.toolkit.fluxcd.io", Resource: "ocirepositories"},
+	"ocirepository":  {Group: "source.toolkit.fluxcd.io", Resource: "ocirepositories"},
+	"bucket":         {Group: "source.toolkit.fluxcd.io", Resource: "buckets"},
+	"helmchart":      {Group: "source.toolkit.fluxcd.io", Resource: "helmcharts"},
+	"helmrepository": {Group: "source.toolkit.fluxcd.io", Resource: "helmrepositories"},
+	"terraform":      {Group: "infra.contrib.fluxcd.io", Resource: "terraforms"},
+	// Only exported, not reconciled or watched
+	"alert":                 alertsResource,
+	"provider":              providersResource,
+	"receiver":              receiversResource,
+	"imagerepository":       {Group: "image.toolkit.fluxcd.io", Resource: "imagerepositories"},
+	"imagepolicy":           {Group: "image.toolkit.fluxcd.io", Resource: "imagepolicies"},
+	"imageupdateautomation": {Group: "image.toolkit.fluxcd.io", Resource: "imageupdateautomations"},
+}
+
+// ListOptions controls how resources are discovered across namespaces
+type ListOptions struct {
+	// Kind is a monitor kind (kustomization, helmrelease, git, oci)
+	Kind string
+	// Namespaces to search; empty means a single cluster-wide list
+	Namespaces []string
+	// LabelSelector is applied server-side
+	LabelSelector string
+	// FieldSelector is applied server-side (e.g. metadata.name=apps)
+	FieldSelector string
+	// Concurrency bounds the per-namespace queries in flight (default 10)
+	Concurrency int
+	// PageSize is the number of items fetched per page (default 250)
+	PageSize int64
+	// Progress, if set, is called as namespaces complete
+	Progress func(done, total int)
+}
+
+// discoverGVR looks up the version the API server serves for gvr's group and
+// resource, preferring the group's preferred version.
+func discoverGVR(disc discovery.DiscoveryInterface, gvr schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	groups, err := disc.ServerGroups()
+	if err != nil {
+		return gvr, err
+	}
+
+	for _, group := range groups.Groups {
+		if group.Name != gvr.Group {
+			continue
+		}
+		versions := []string{group.PreferredVersion.Version}
+		for _, v := range group.Versions {
+			if v.Version != group.PreferredVersion.Version {
+				versions = append(versions, v.Version)
+			}
+		}
+		for _, version := range versions {
+			resources, err := disc.ServerResourcesForGroupVersion(gvr.Group + "/" + version)
+			if err != nil {
+				continue
+			}
+			for _, r := range resources.APIResources {
+				if r.Name == gvr.Resource {
+					return schema.GroupVersionResource{Group: gvr.Group, Version: version, Resource: gvr.Resource}, nil
+				}
+			}
+		}
+	}
+
+	return gvr, fmt.Errorf("resource %s not served by the API server", gvr.GroupResource().String())
+}
+
+// ResolveKind returns the served GroupVersionResource for a monitor kind
+func (c *Cluster) ResolveKind(kind string) (schema.GroupVersionResource, error) {
+	gr, ok := kindGroupResources[kind]
+	if !ok {
+		return schema.GroupVersionResource{}, fmt.Errorf("unsupported resource kind: %s", kind)
+	}
+	return discoverGVR(c.clientset.Discovery(), gr.WithVersion(""))
+}
+
+// GetResource returns a resource of a monitor kind as served, status included
+func (c *Cluster) GetResource(ctx context.Context, kind, namespace, name string) (*unstructured.Unstructured, error) {
+	gvr, err := c.ResolveKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	return c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// ListResources lists resources of a kind using server-side label selectors
+// and paginated requests. When namespaces are given they are queried
+// concurrently with a bounded pool; failures in individual namespaces are
+// returned joined, alongside the items that could be listed.
+func (c *Cluster) ListResources(ctx context.Context, opts ListOptions) ([]unstructured.Unstructured, error) {
+	gvr, err := c.ResolveKind(opts.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = 250
+	}
+
+	if len(opts.Namespaces) == 0 {
+		items, err := c.listPaginated(ctx, gvr, metav1.NamespaceAll, opts)
+		if opts.Progress != nil {
+			opts.Progress(1, 1)
+		}
+		sortItems(items)
+		return items, err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		items []unstructured.Unstructured
+		errs  []error
+		done  int
+		sem   = make(chan struct{}, concurrency)
+	)
+	for _, ns := range opts.Namespaces {
+		wg.Add(1)
+		go func(ns string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				mu.Lock()
+				errs = append(errs, ctx.Err())
+				mu.Unlock()
+				return
+			}
+			defer func() { <-sem }()
+
+			nsItems, err := c.listPaginated(ctx, gvr, ns, opts)
+
+			mu.Lock()
+			defer mu.Unlock()
+			items = append(items, nsItems...)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("namespace %s: %w", ns, err))
+			}
+			done++
+			if opts.Progress != nil {
+				opts.Progress(done, len(opts.Namespaces))
+			}
+		}(ns)
+	}
+	wg.Wait()
+
+	sortItems(items)
+	return items, errors.Join(errs...)
+}
+
+func (c *Cluster) listPaginated(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts ListOptions) ([]unstructured.Unstructured, error) {
+	var items []unstructured.Unstructured
+	listOpts := metav1.ListOptions{LabelSelector: opts.LabelSelector, FieldSelector: opts.FieldSelector, Limit: opts.PageSize}
+	for {
+		list, err := c.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, listOpts)
+		if err != nil {
+			return items, err
+		}
+		items = append(items, list.Items...)
+		if list.GetContinue() == "" {
+			return items, nil
+		}
+		listOpts.Continue = list.GetContinue()
+	}
+}
+
+func sortItems(items []unstructured.Unstructured) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+}
@@ -0,0 +1,156 @@
+package events
+
+import (
+	"context"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// Dry-run actions, named like kubectl apply's output
+const (
+	DryRunCreated    = "created"
+	DryRunConfigured = "configured"
+	DryRunUnchanged  = "unchanged"
+	DryRunFailed     = "failed"
+)
+
+// kustomizeFieldManager is the field manager kustomize-controller applies with
+const kustomizeFieldManager = "kustomize-controller"
+
+// DryRunResult is the outcome of a server-side dry-run apply of one object
+type DryRunResult struct {
+	Object InventoryObject
+	Action string
+	// Changes lists the fields that would change, e.g. "spec" or
+	// "metadata.labels"
+	Changes []string
+	Err     error
+}
+
+// DryRunApply server-side applies the objects with dryRun=All, as
+// kustomize-controller would, and reports which would be created or changed.
+// Namespaces and CRDs are applied first; objects in a namespace that would
+// only be created are reported as created without a server check.
+func (c *Cluster) DryRunApply(ctx context.Context, objects []*unstructured.Unstructured) []DryRunResult {
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(c.clientset.Discovery()))
+
+	ordered := append([]*unstructured.Unstructured(nil), objects...)
+	sort.SliceStable(ordered, func(i, j int) bool { return applyStage(ordered[i]) < applyStage(ordered[j]) })
+
+	newNamespaces := map[string]bool{}
+	results := make([]DryRunResult, 0, len(ordered))
+	for _, obj := range ordered {
+		gvk := obj.GroupVersionKind()
+		result := DryRunResult{Object: InventoryObject{
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Group:     gvk.Group,
+			Kind:      gvk.Kind,
+			Version:   gvk.Version,
+		}}
+
+		if newNamespaces[obj.GetNamespace()] {
+			result.Action = DryRunCreated
+			results = append(results, result)
+			continue
+		}
+		result.Action, result.Changes, result.Err = c.dryRunApplyObject(ctx, mapper, obj)
+		if result.Err != nil {
+			result.Action = DryRunFailed
+		}
+		if gvk.Group == "" && gvk.Kind == "Namespace" && result.Action == DryRunCreated {
+			newNamespaces[obj.GetName()] = true
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// applyStage orders Namespaces and CRDs before the objects that need them
+func applyStage(obj *unstructured.Unstructured) int {
+	switch obj.GetKind() {
+	case "Namespace", "CustomResourceDefinition":
+		return 0
+	}
+	return 1
+}
+
+func (c *Cluster) dryRunApplyObject(ctx context.Context, mapper meta.RESTMapper, obj *unstructured.Unstructured) (string, []string, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return "", nil, err
+	}
+	var client dynamic.ResourceInterface = c.dynamicClient.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		client = c.dynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+	}
+
+	existing, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", nil, err
+	}
+
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return "", nil, err
+	}
+	force := true
+	applied, err := client.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		FieldManager: kustomizeFieldManager,
+		Force:        &force,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if existing == nil {
+		return DryRunCreated, nil, nil
+	}
+	if changes := changedFields(existing, applied); len(changes) > 0 {
+		return DryRunConfigured, changes, nil
+	}
+	return DryRunUnchanged, nil, nil
+}
+
+// changedFields compares an object before and after a dry-run apply. Server
+// managed metadata and status are ignored.
+func changedFields(before, after *unstructured.Unstructured) []string {
+	var changes []string
+	for _, field := range []string{"labels", "annotations"} {
+		b, _, _ := unstructured.NestedFieldNoCopy(before.Object, "metadata", field)
+		a, _, _ := unstructured.NestedFieldNoCopy(after.Object, "metadata", field)
+		if !equality.Semantic.DeepEqual(a, b) {
+			changes = append(changes, "metadata."+field)
+		}
+	}
+
+	keys := map[string]bool{}
+	for k := range before.Object {
+		keys[k] = true
+	}
+	for k := range after.Object {
+		keys[k] = true
+	}
+	var fields []string
+	for k := range keys {
+		switch k {
+		case "apiVersion", "kind", "metadata", "status":
+			continue
+		}
+		if !equality.Semantic.DeepEqual(before.Object[k], after.Object[k]) {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return append(changes, fields...)
+}
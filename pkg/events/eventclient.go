@@ -0,0 +1,107 @@
+package events
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// eventClient lists and watches the events of a namespace through the
+// events.k8s.io/v1 API, falling back to the core v1 API when that isn't
+// served or RBAC only grants access to core events. Core events are converted
+// to events.k8s.io/v1, and field selectors on regarding.* are translated to
+// involvedObject.*.
+type eventClient struct {
+	clientset kubernetes.Interface
+	namespace string
+	// core is set once events.k8s.io/v1 was refused
+	core atomic.Bool
+}
+
+func newEventClient(clientset kubernetes.Interface, namespace string) *eventClient {
+	return &eventClient{clientset: clientset, namespace: namespace}
+}
+
+// useCore reports whether err of an events.k8s.io/v1 request warrants
+// retrying with the core v1 API
+func useCore(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err)
+}
+
+// coreOptions translates the field selector of opts to core v1 events
+func coreOptions(opts metav1.ListOptions) metav1.ListOptions {
+	opts.FieldSelector = strings.ReplaceAll(opts.FieldSelector, "regarding.", "involvedObject.")
+	return opts
+}
+
+func (c *eventClient) List(ctx context.Context, opts metav1.ListOptions) (*eventsv1.EventList, error) {
+	if !c.core.Load() {
+		list, err := c.clientset.EventsV1().Events(c.namespace).List(ctx, opts)
+		if err == nil || !useCore(err) {
+			return list, err
+		}
+		c.core.Store(true)
+	}
+	coreList, err := c.clientset.CoreV1().Events(c.namespace).List(ctx, coreOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	list := &eventsv1.EventList{ListMeta: coreList.ListMeta}
+	for _, evt := range coreList.Items {
+		list.Items = append(list.Items, fromCoreEvent(evt))
+	}
+	return list, nil
+}
+
+func (c *eventClient) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	if !c.core.Load() {
+		w, err := c.clientset.EventsV1().Events(c.namespace).Watch(ctx, opts)
+		if err == nil || !useCore(err) {
+			return w, err
+		}
+		c.core.Store(true)
+	}
+	w, err := c.clientset.CoreV1().Events(c.namespace).Watch(ctx, coreOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(e watch.Event) (watch.Event, bool) {
+		if evt, ok := e.Object.(*corev1.Event); ok {
+			converted := fromCoreEvent(*evt)
+			e.Object = &converted
+		}
+		return e, true
+	}), nil
+}
+
+// fromCoreEvent converts a core v1 event to events.k8s.io/v1, like the API
+// server does for events created through either API
+func fromCoreEvent(evt corev1.Event) eventsv1.Event {
+	converted := eventsv1.Event{
+		ObjectMeta:               evt.ObjectMeta,
+		EventTime:                evt.EventTime,
+		ReportingController:      evt.ReportingController,
+		ReportingInstance:        evt.ReportingInstance,
+		Action:                   evt.Action,
+		Reason:                   evt.Reason,
+		Regarding:                evt.InvolvedObject,
+		Related:                  evt.Related,
+		Note:                     evt.Message,
+		Type:                     evt.Type,
+		DeprecatedSource:         evt.Source,
+		DeprecatedFirstTimestamp: evt.FirstTimestamp,
+		DeprecatedLastTimestamp:  evt.LastTimestamp,
+		DeprecatedCount:          evt.Count,
+	}
+	if evt.Series != nil {
+		converted.Series = &eventsv1.EventSeries{Count: evt.Series.Count, LastObservedTime: evt.Series.LastObservedTime}
+	}
+	return converted
+}
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	eventsv1 "k8s.io/api/events/v1"
+)
+
+// DefaultFailOnEvents are the event reasons that abort the wait with
+// --fail-fast unless --fail-on-events names others
+var DefaultFailOnEvents = []string{"HealthCheckFailed", "InstallFailed", "BuildFailed", "ArtifactFailed"}
+
+// EventFailureError is returned by WaitForReady when an event with one of the
+// reasons given to SetFailOnEvents occurs
+type EventFailureError struct {
+	Reason  string
+	Message string
+}
+
+func (e *EventFailureError) Error() string {
+	return fmt.Sprintf("%s event: %s", e.Reason, e.Message)
+}
+
+// SetFailOnEvents makes WaitForReady fail as soon as an event with one of the
+// reasons occurs. Events from before the call are ignored. It must be called
+// before Watch.
+func (m *Monitor) SetFailOnEvents(reasons []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failOn = map[string]bool{}
+	for _, r := range reasons {
+		m.failOn[r] = true
+	}
+	m.failOnSince = time.Now()
+}
+
+// checkFailEvent records the first event whose reason aborts the wait. On a
+// target's first listing only events newer than SetFailOnEvents count, since
+// the listing includes earlier reconciles. Callers hold m.mu.
+func (m *Monitor) checkFailEvent(t eventTarget, evt eventsv1.Event, initialized bool) {
+	if m.failEvent != nil || !m.failOn[evt.Reason] {
+		return
+	}
+	if !initialized && !eventTime(evt).After(m.failOnSince) {
+		return
+	}
+	m.failEvent = &EventFailureError{Reason: evt.Reason, Message: t.label + evt.Note}
+}
+
+// failedByEvent returns the error of an event that aborts the wait, or nil
+func (m *Monitor) failedByEvent() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failEvent == nil {
+		return nil
+	}
+	return m.failEvent
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ReadinessTransition is a change of the resource between ready and not ready
+// observed during the run
+type ReadinessTransition struct {
+	Time    time.Time
+	Ready   bool
+	Status  string
+	Message string
+}
+
+func (t ReadinessTransition) String() string {
+	if t.Ready || t.Message == "" {
+		return fmt.Sprintf("%s %s", t.Time.Format("15:04:05"), t.Status)
+	}
+	return fmt.Sprintf("%s %s (%s)", t.Time.Format("15:04:05"), t.Status, t.Message)
+}
+
+// recordReadiness adds a transition when the resource turns ready or not
+// ready. Transient states (Ready Unknown while reconciling) don't count, nor
+// does the status from before a pending reconcile request was handled.
+func (m *Monitor) recordReadiness(obj *unstructured.Unstructured, status, summary string) {
+	var ready bool
+	switch status {
+	case "ready":
+		ready = true
+	case "not ready", "failed", "terminating":
+	default:
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.requestToken != "" {
+		if handled, _, _ := unstructured.NestedString(obj.Object, "status", "lastHandledReconcileAt"); handled != m.requestToken {
+			return
+		}
+	}
+	if !ready && summary != "" && !slices.Contains(m.failing, summary) {
+		m.failing = append(m.failing, summary)
+	}
+	if n := len(m.transitions); n > 0 && m.transitions[n-1].Ready == ready {
+		return
+	}
+	m.transitions = append(m.transitions, ReadinessTransition{Time: time.Now(), Ready: ready, Status: status, Message: summary})
+}
+
+// Transitions returns the readiness changes observed during the run
+func (m *Monitor) Transitions() []ReadinessTransition {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ReadinessTransition(nil), m.transitions...)
+}
+
+// FailingConditions returns the distinct not ready condition summaries seen
+// during the run, oldest first
+func (m *Monitor) FailingConditions() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.failing...)
+}
+
+// Flapping reports whether the resource oscillated, turning not ready after it
+// was ready and then ready again
+func (m *Monitor) Flapping() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seenReady, dropped := false, false
+	for _, t := range m.transitions {
+		switch {
+		case t.Ready && dropped:
+			return true
+		case t.Ready:
+			seenReady = true
+		case seenReady:
+			dropped = true
+		}
+	}
+	return false
+}
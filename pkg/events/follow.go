@@ -0,0 +1,38 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// Follow keeps checking the resource after it became ready, printing its
+// condition changes and status changes until ctx is done. Events are printed
+// by Watch as before.
+func (m *Monitor) Follow(ctx context.Context) error {
+	out := output.FromContext(ctx)
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(m.PollInterval())
+	defer ticker.Stop()
+	status := "ready"
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			current, summary := m.getResourceStatus(gvr)
+			m.printConditionChanges(out)
+			if current == "" || current == status {
+				continue
+			}
+			out.PrintStatus(fmt.Sprintf("Status changed to %s: %s", current, output.Preview(summary)))
+			status = current
+		}
+	}
+}
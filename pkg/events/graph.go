@@ -0,0 +1,145 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Edge types of the dependency graph
+const (
+	EdgeDependsOn = "dependsOn"
+	EdgeSource    = "source"
+)
+
+// GraphNode is an object of the dependency graph
+type GraphNode struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Ready is the status of the Ready condition, empty for objects that are
+	// only referenced (sources, or dependencies that weren't listed)
+	Ready     string
+	Suspended bool
+	// Listed is false for objects only known from references
+	Listed bool
+}
+
+// ID returns Kind/namespace/name, which identifies the node in the graph
+func (n GraphNode) ID() string {
+	return fmt.Sprintf("%s/%s/%s", n.Kind, n.Namespace, n.Name)
+}
+
+// GraphEdge points from an object to one it depends on (spec.dependsOn) or
+// to its source (spec.sourceRef, spec.chart.spec.sourceRef, spec.chartRef)
+type GraphEdge struct {
+	From string
+	To   string
+	Type string
+}
+
+// Graph is the topology of Kustomizations and HelmReleases, with the sources
+// they reference. Nodes are sorted by ID, edges by source and target.
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// Node returns the node with the ID
+func (g *Graph) Node(id string) (GraphNode, bool) {
+	i := sort.Search(len(g.Nodes), func(i int) bool { return g.Nodes[i].ID() >= id })
+	if i < len(g.Nodes) && g.Nodes[i].ID() == id {
+		return g.Nodes[i], true
+	}
+	return GraphNode{}, false
+}
+
+// DependencyGraph lists the Kustomizations and HelmReleases in the namespaces
+// of opts (all when empty), filtered by its selectors, and returns their
+// dependsOn and source relationships
+func (c *Cluster) DependencyGraph(ctx context.Context, opts ListOptions) (*Graph, error) {
+	var objects []unstructured.Unstructured
+	for _, kind := range []string{"kustomization", "helmrelease"} {
+		opts.Kind = kind
+		items, err := c.ListResources(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, items...)
+	}
+	return BuildGraph(objects), nil
+}
+
+// BuildGraph returns the graph of Kustomizations and HelmReleases. Objects
+// they reference that aren't among them are added as unlisted nodes.
+func BuildGraph(objects []unstructured.Unstructured) *Graph {
+	nodes := map[string]GraphNode{}
+	var edges []GraphEdge
+	reference := func(from, kind, namespace, name, edgeType string) {
+		to := GraphNode{Kind: kind, Namespace: namespace, Name: name}
+		if _, ok := nodes[to.ID()]; !ok {
+			nodes[to.ID()] = to
+		}
+		edges = append(edges, GraphEdge{From: from, To: to.ID(), Type: edgeType})
+	}
+
+	for i := range objects {
+		obj := &objects[i]
+		node := GraphNode{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName(), Ready: "Unknown", Listed: true}
+		if ready, _, _ := conditionStatus(obj, "Ready"); ready != "" {
+			node.Ready = ready
+		}
+		node.Suspended, _, _ = unstructured.NestedBool(obj.Object, "spec", "suspend")
+		nodes[node.ID()] = node
+	}
+
+	for i := range objects {
+		obj := &objects[i]
+		from := GraphNode{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}.ID()
+		dependencies, _, _ := unstructured.NestedSlice(obj.Object, "spec", "dependsOn")
+		for _, d := range dependencies {
+			dep, ok := d.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := dep["name"].(string)
+			namespace, _ := dep["namespace"].(string)
+			if name == "" {
+				continue
+			}
+			if namespace == "" {
+				namespace = obj.GetNamespace()
+			}
+			// dependsOn only refers to objects of the same kind
+			reference(from, obj.GetKind(), namespace, name, EdgeDependsOn)
+		}
+
+		for _, fields := range [][]string{{"spec", "sourceRef"}, {"spec", "chart", "spec", "sourceRef"}, {"spec", "chartRef"}} {
+			ref, found, _ := unstructured.NestedStringMap(obj.Object, fields...)
+			if !found || ref["kind"] == "" || ref["name"] == "" {
+				continue
+			}
+			namespace := ref["namespace"]
+			if namespace == "" {
+				namespace = obj.GetNamespace()
+			}
+			reference(from, ref["kind"], namespace, ref["name"], EdgeSource)
+			break
+		}
+	}
+
+	g := &Graph{Nodes: make([]GraphNode, 0, len(nodes)), Edges: edges}
+	for _, node := range nodes {
+		g.Nodes = append(g.Nodes, node)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID() < g.Nodes[j].ID() })
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g
+}
@@ -0,0 +1,216 @@
+package events
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// HelmReleaseSnapshot is an entry of a HelmRelease's status.history, newest
+// first
+type HelmReleaseSnapshot struct {
+	Revision     int64
+	Status       string
+	ChartName    string
+	ChartVersion string
+	AppVersion   string
+	Deployed     string
+}
+
+// HelmHistory returns the release history, newest first. It is read from
+// status.history (helm.toolkit.fluxcd.io/v2), falling back to the Helm
+// release secrets for older APIs.
+func (m *Monitor) HelmHistory(ctx context.Context) ([]HelmReleaseSnapshot, error) {
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return nil, err
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	history, found, err := unstructured.NestedSlice(obj.Object, "status", "history")
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return m.helmSecretHistory(ctx, obj)
+	}
+	return historySnapshots(history), nil
+}
+
+// historySnapshots parses the entries of a HelmRelease's status.history
+func historySnapshots(history []interface{}) []HelmReleaseSnapshot {
+	snapshots := make([]HelmReleaseSnapshot, 0, len(history))
+	for _, h := range history {
+		entry, ok := h.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		s := HelmReleaseSnapshot{}
+		s.Revision, _, _ = unstructured.NestedInt64(entry, "version")
+		s.Status, _, _ = unstructured.NestedString(entry, "status")
+		s.ChartName, _, _ = unstructured.NestedString(entry, "chartName")
+		s.ChartVersion, _, _ = unstructured.NestedString(entry, "chartVersion")
+		s.AppVersion, _, _ = unstructured.NestedString(entry, "appVersion")
+		s.Deployed, _, _ = unstructured.NestedString(entry, "lastDeployed")
+		snapshots = append(snapshots, s)
+	}
+	return snapshots
+}
+
+// RollBackTo puts the HelmRelease back to the chart version and values of
+// the release revision target, the values read from its Helm storage secret,
+// and requests a reconcile, so helm-controller upgrades the release to them.
+// current is the release's newest revision. It returns the request token.
+func (m *Monitor) RollBackTo(ctx context.Context, target, current HelmReleaseSnapshot) (string, error) {
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return "", err
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	_, pinnable, _ := unstructured.NestedMap(obj.Object, "spec", "chart", "spec")
+	if !pinnable && target.ChartVersion != current.ChartVersion {
+		return "", fmt.Errorf("%s/%s uses chartRef, its chart version can't be rolled back to %s", m.namespace, m.name, target.ChartVersion)
+	}
+
+	releaseName, storageNamespace := helmStorage(obj, m.name, m.namespace)
+	secretName := fmt.Sprintf("sh.helm.release.v1.%s.v%d", releaseName, target.Revision)
+	secret, err := m.clientset.CoreV1().Secrets(storageNamespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to read the values of revision %d: %w", target.Revision, err)
+	}
+	rel, err := decodeHelmRelease(secret.Data["release"])
+	if err != nil {
+		return "", fmt.Errorf("failed to decode revision %d: %w", target.Revision, err)
+	}
+
+	// The values are replaced as a whole, which a merge patch can't do for
+	// keys added since
+	if len(rel.Config) > 0 {
+		err = unstructured.SetNestedField(obj.Object, rel.Config, "spec", "values")
+	} else {
+		unstructured.RemoveNestedField(obj.Object, "spec", "values")
+	}
+	if err == nil && pinnable {
+		err = unstructured.SetNestedField(obj.Object, target.ChartVersion, "spec", "chart", "spec", "version")
+	}
+	if err != nil {
+		return "", err
+	}
+	token := time.Now().Format(time.RFC3339Nano)
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[RequestedAtAnnotation] = token
+	obj.SetAnnotations(annotations)
+	// The resourceVersion makes a concurrent change conflict
+	_, err = m.dynamicClient.Resource(gvr).Namespace(m.namespace).Update(ctx, obj, metav1.UpdateOptions{})
+	return token, err
+}
+
+// helmStorage returns the Helm release name and storage namespace of a
+// HelmRelease
+func helmStorage(obj *unstructured.Unstructured, name, namespace string) (string, string) {
+	releaseName, _, _ := unstructured.NestedString(obj.Object, "spec", "releaseName")
+	if releaseName == "" {
+		releaseName = name
+		if target, _, _ := unstructured.NestedString(obj.Object, "spec", "targetNamespace"); target != "" {
+			releaseName = target + "-" + name
+		}
+	}
+	storageNamespace, _, _ := unstructured.NestedString(obj.Object, "spec", "storageNamespace")
+	if storageNamespace == "" {
+		storageNamespace = namespace
+	}
+	return releaseName, storageNamespace
+}
+
+// helmSecretHistory reads the history from the Helm storage secrets
+// (sh.helm.release.v1.<release>.v<revision>) of the HelmRelease
+func (m *Monitor) helmSecretHistory(ctx context.Context, obj *unstructured.Unstructured) ([]HelmReleaseSnapshot, error) {
+	releaseName, storageNamespace := helmStorage(obj, m.name, m.namespace)
+	secrets, err := m.clientset.CoreV1().Secrets(storageNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "owner=helm,name=" + releaseName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Helm release secrets: %w", err)
+	}
+
+	var snapshots []HelmReleaseSnapshot
+	for _, secret := range secrets.Items {
+		rel, err := decodeHelmRelease(secret.Data["release"])
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, HelmReleaseSnapshot{
+			Revision:     rel.Version,
+			Status:       rel.Info.Status,
+			ChartName:    rel.Chart.Metadata.Name,
+			ChartVersion: rel.Chart.Metadata.Version,
+			AppVersion:   rel.Chart.Metadata.AppVersion,
+			Deployed:     rel.Info.LastDeployed,
+		})
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("no release history found for %s/%s", m.namespace, m.name)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Revision > snapshots[j].Revision })
+	return snapshots, nil
+}
+
+// helmRelease holds the fields of a Helm release record used for history
+// and rollbacks
+type helmRelease struct {
+	Version int64 `json:"version"`
+	// Config holds the values the release was installed or upgraded with
+	Config map[string]interface{} `json:"config"`
+	Info   struct {
+		Status       string `json:"status"`
+		LastDeployed string `json:"last_deployed"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// decodeHelmRelease decodes a Helm storage record: base64 of gzipped JSON
+func decodeHelmRelease(data []byte) (*helmRelease, error) {
+	raw, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > 2 && raw[0] == 0x1f && raw[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		if raw, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+	rel := &helmRelease{}
+	if err := json.Unmarshal(raw, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
@@ -0,0 +1,94 @@
+package events
+
+import (
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// reconcilingActionRegex extracts the action from the Reconciling condition
+// message, e.g. "Running 'upgrade' action with timeout of 5m0s"
+var reconcilingActionRegex = regexp.MustCompile(`Running '([a-z-]+)' action`)
+
+// helmActionPhases maps helm-controller actions to the phase shown while they run
+var helmActionPhases = map[string]string{
+	"install":   "installing",
+	"upgrade":   "upgrading",
+	"test":      "testing",
+	"rollback":  "rolling back",
+	"uninstall": "uninstalling",
+}
+
+// helmHistoryPhases maps pending Helm release statuses from status.history
+var helmHistoryPhases = map[string]string{
+	"pending-install":  "installing",
+	"pending-upgrade":  "upgrading",
+	"pending-rollback": "rolling back",
+	"uninstalling":     "uninstalling",
+}
+
+// helmReleasePhase derives the lifecycle phase of a HelmRelease from its
+// Reconciling, Released, TestSuccess and Remediated conditions and the latest
+// entry of status.history.
+func helmReleasePhase(obj *unstructured.Unstructured) string {
+	conditions := map[string]map[string]interface{}{}
+	list, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range list {
+		if cond, ok := c.(map[string]interface{}); ok {
+			condType, _, _ := unstructured.NestedString(cond, "type")
+			conditions[condType] = cond
+		}
+	}
+	status := func(condType string) (string, string, string) {
+		cond, ok := conditions[condType]
+		if !ok {
+			return "", "", ""
+		}
+		s, _, _ := unstructured.NestedString(cond, "status")
+		reason, _, _ := unstructured.NestedString(cond, "reason")
+		message, _, _ := unstructured.NestedString(cond, "message")
+		return s, reason, message
+	}
+
+	if s, _, _ := status("Ready"); s == "True" {
+		return "released"
+	}
+	if s, _, message := status("Reconciling"); s == "True" {
+		if m := reconcilingActionRegex.FindStringSubmatch(message); m != nil {
+			if phase, ok := helmActionPhases[m[1]]; ok {
+				return phase
+			}
+		}
+	}
+	if history, _, _ := unstructured.NestedSlice(obj.Object, "status", "history"); len(history) > 0 {
+		if latest, ok := history[0].(map[string]interface{}); ok {
+			s, _, _ := unstructured.NestedString(latest, "status")
+			if phase, ok := helmHistoryPhases[s]; ok {
+				return phase
+			}
+		}
+	}
+	if s, reason, _ := status("Remediated"); s == "True" {
+		if strings.Contains(reason, "Uninstall") {
+			return "remediated (uninstalled)"
+		}
+		return "remediated (rolled back)"
+	}
+	if s, _, _ := status("TestSuccess"); s == "False" {
+		return "tests failed"
+	}
+	switch s, reason, _ := status("Released"); s {
+	case "False":
+		if strings.HasPrefix(reason, "Upgrade") {
+			return "upgrade failed"
+		}
+		return "install failed"
+	case "True":
+		if s, _, _ := status("TestSuccess"); s == "Unknown" {
+			return "testing"
+		}
+		return "released"
+	}
+	return ""
+}
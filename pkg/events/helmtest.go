@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// HelmTestHook is a test hook of the latest Helm release, from
+// status.history[0].testHooks
+type HelmTestHook struct {
+	Namespace string
+	Name      string
+	Phase     string
+}
+
+// HelmTests is the state of a HelmRelease's Helm tests
+type HelmTests struct {
+	// Enabled reports whether spec.test.enable is set
+	Enabled bool
+	// Status is the TestSuccess condition status (True, False, Unknown), or
+	// "" when the condition is not set yet
+	Status  string
+	Message string
+	Hooks   []HelmTestHook
+}
+
+// HelmTests reads the Helm test configuration and results of the HelmRelease
+func (m *Monitor) HelmTests(ctx context.Context) (*HelmTests, error) {
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return nil, err
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	tests := &HelmTests{}
+	tests.Enabled, _, _ = unstructured.NestedBool(obj.Object, "spec", "test", "enable")
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condType, _, _ := unstructured.NestedString(cond, "type"); condType == "TestSuccess" {
+			tests.Status, _, _ = unstructured.NestedString(cond, "status")
+			tests.Message, _, _ = unstructured.NestedString(cond, "message")
+		}
+	}
+
+	// Test hooks run in the release namespace
+	releaseNamespace, _, _ := unstructured.NestedString(obj.Object, "spec", "targetNamespace")
+	if releaseNamespace == "" {
+		releaseNamespace = m.namespace
+	}
+	history, _, _ := unstructured.NestedSlice(obj.Object, "status", "history")
+	if len(history) == 0 {
+		return tests, nil
+	}
+	latest, ok := history[0].(map[string]interface{})
+	if !ok {
+		return tests, nil
+	}
+	if ns, _, _ := unstructured.NestedString(latest, "namespace"); ns != "" {
+		releaseNamespace = ns
+	}
+	hooks, _, _ := unstructured.NestedMap(latest, "testHooks")
+	for key, value := range hooks {
+		hook := HelmTestHook{Namespace: releaseNamespace, Name: key}
+		// Keys are either "<name>" or "<namespace>/<name>"
+		if ns, name, found := strings.Cut(key, "/"); found {
+			hook.Namespace, hook.Name = ns, name
+		}
+		if status, ok := value.(map[string]interface{}); ok {
+			hook.Phase, _, _ = unstructured.NestedString(status, "phase")
+		}
+		tests.Hooks = append(tests.Hooks, hook)
+	}
+	sort.Slice(tests.Hooks, func(i, j int) bool { return tests.Hooks[i].Name < tests.Hooks[j].Name })
+	return tests, nil
+}
+
+// HelmTestLogs returns the last lines of a test hook pod's log
+func (m *Monitor) HelmTestLogs(ctx context.Context, hook HelmTestHook, lines int64) ([]string, error) {
+	return m.podLogTail(ctx, hook.Namespace, hook.Name, "", false, lines)
+}
@@ -0,0 +1,141 @@
+package events
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// HintRule maps a failure message pattern to remediation advice
+type HintRule struct {
+	Pattern *regexp.Regexp
+	// Hint is the advice; $1 or ${group} insert capture groups of Pattern and
+	// ${kind} the kind of the resource
+	Hint string
+	// Kinds limits the rule to these kinds (kustomization, helmrelease,
+	// source, ...); it applies to all kinds when empty
+	Kinds []string
+}
+
+// NewHintRule compiles a rule from a regular expression
+func NewHintRule(pattern, hint string, kinds []string) (HintRule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return HintRule{}, fmt.Errorf("invalid hint pattern %q: %w", pattern, err)
+	}
+	if hint == "" {
+		return HintRule{}, fmt.Errorf("hint pattern %q has no hint", pattern)
+	}
+	return HintRule{Pattern: re, Hint: hint, Kinds: kinds}, nil
+}
+
+// DefaultHintRules are the built-in rules for common Flux failures. Rules
+// configured by the user are checked first.
+var DefaultHintRules = []HintRule{
+	{
+		Pattern: regexp.MustCompile(`dependency '([^'/]+)/([^']+)' is not ready`),
+		Hint:    "dependency $1/$2 not ready, consider reconciling it (flux-enhanced-cli ${kind} $2 --namespace $1)",
+	},
+	{
+		Pattern: regexp.MustCompile(`dependency '[^']+' .*not ready|DependencyNotReady`),
+		Hint:    "a dependency is not ready, reconcile the objects in spec.dependsOn first",
+	},
+	{
+		Pattern: regexp.MustCompile(`kustomization path not found|path not found: stat`),
+		Hint:    "spec.path does not exist in the source artifact, check the path and that the source fetched the commit that adds it",
+		Kinds:   []string{"kustomization"},
+	},
+	{
+		Pattern: regexp.MustCompile(`chart "([^"]+)" version "([^"]+)" not found`),
+		Hint:    "version $2 of chart $1 is not in the repository index, check spec.chart.spec.version and reconcile the HelmRepository to refresh its index",
+	},
+	{
+		Pattern: regexp.MustCompile(`chart "([^"]+)" not found|no chart name found|chart not found`),
+		Hint:    "the chart is not in the repository, check spec.chart.spec.chart and the HelmRepository URL",
+	},
+	{
+		Pattern: regexp.MustCompile(`([A-Z]\w*/[\w.-]+(?:/[\w.-]+)?) dry-run failed.*field is immutable`),
+		Hint:    "$1 changed a field that can't be updated in place, delete it so it is recreated, or set spec.force on the Kustomization",
+	},
+	{
+		Pattern: regexp.MustCompile(`field is immutable`),
+		Hint:    "an object changed a field that can't be updated in place (e.g. a selector or a Job template), delete it so it is recreated",
+	},
+	{
+		Pattern: regexp.MustCompile(`no matches for kind "([^"]+)" in version "([^"]+)"`),
+		Hint:    "the CRD of $1 ($2) is not installed, install it first or depend on the Kustomization that does",
+	},
+	{
+		Pattern: regexp.MustCompile(`(?i)authentication required|authorization failed|401 Unauthorized`),
+		Hint:    "the source was denied access, check the credentials in its spec.secretRef",
+		Kinds:   []string{"source", "helmrelease"},
+	},
+}
+
+// applies reports whether the rule is meant for a monitor kind
+func (r HintRule) applies(kind string) bool {
+	if len(r.Kinds) == 0 || slices.Contains(r.Kinds, kind) {
+		return true
+	}
+	if !slices.Contains(r.Kinds, "source") {
+		return false
+	}
+	for _, sourceKind := range sourceKinds {
+		if sourceKind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// advise returns the rule's hint for a message it matches
+func (r HintRule) advise(kind, message string) (string, bool) {
+	match := r.Pattern.FindStringSubmatchIndex(message)
+	if match == nil {
+		return "", false
+	}
+	template := r.Hint
+	if !slices.Contains(r.Pattern.SubexpNames(), "kind") {
+		template = strings.ReplaceAll(template, "${kind}", kind)
+	}
+	return string(r.Pattern.ExpandString(nil, template, message, match)), true
+}
+
+// SetHintRules adds rules that are checked before DefaultHintRules. It must
+// be called before WaitForReady.
+func (m *Monitor) SetHintRules(rules []HintRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hintRules = rules
+}
+
+// matchHints returns the hints for the messages, from the first rule that
+// matches each, without duplicates
+func (m *Monitor) matchHints(messages []string) []string {
+	m.mu.Lock()
+	rules := append(append([]HintRule(nil), m.hintRules...), DefaultHintRules...)
+	m.mu.Unlock()
+
+	var hints []string
+	for _, message := range messages {
+		for _, rule := range rules {
+			if !rule.applies(m.kind) {
+				continue
+			}
+			if hint, ok := rule.advise(m.kind, message); ok {
+				if !slices.Contains(hints, hint) {
+					hints = append(hints, hint)
+				}
+				break
+			}
+		}
+	}
+	return hints
+}
+
+// FailureHints returns the hints for the failing conditions and warning
+// events seen during the run
+func (m *Monitor) FailureHints() []string {
+	return m.matchHints(append(m.FailingConditions(), m.WarningEvents()...))
+}
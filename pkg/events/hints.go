@@ -0,0 +1,128 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// DefaultHintThresholds are the percentages of the timeout at which hints
+// are printed while waiting
+var DefaultHintThresholds = []int{50, 80}
+
+// ParseHintThresholds parses comma-separated percentages of the timeout
+// ("50,80"); an empty list or "none" disables hints
+func ParseHintThresholds(spec string) ([]int, error) {
+	if spec == "none" {
+		return nil, nil
+	}
+	var percentages []int
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSuffix(strings.TrimSpace(field), "%")
+		if field == "" {
+			continue
+		}
+		percent, err := strconv.Atoi(field)
+		if err != nil || percent <= 0 || percent >= 100 {
+			return nil, fmt.Errorf("invalid hint threshold %q: must be a percentage between 1 and 99", field)
+		}
+		if !slices.Contains(percentages, percent) {
+			percentages = append(percentages, percent)
+		}
+	}
+	slices.Sort(percentages)
+	return percentages, nil
+}
+
+// SetHintThresholds sets the percentages of the timeout at which
+// WaitForReady prints hints about what blocks the resource; nil disables
+// them. It must be called before WaitForReady.
+func (m *Monitor) SetHintThresholds(percentages []int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hintThresholds = percentages
+}
+
+// printHints explains why the resource is still not ready: the blocking
+// condition, the most recent warning event and suggestions derived from them
+func (m *Monitor) printHints(ctx context.Context, gvr schema.GroupVersionResource, elapsed, timeout time.Duration, percent int) {
+	out := output.FromContext(ctx)
+	out.PrintWarning(fmt.Sprintf("%d%% of the timeout used (%s of %s) and %s/%s is not ready yet",
+		percent, formatDuration(elapsed), formatDuration(timeout), m.kind, m.name))
+
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		out.PrintSublog(fmt.Sprintf("  Unable to read the resource: %v", err))
+		return
+	}
+	_, _, message := conditionStatus(obj, "Ready")
+	messages := []string{message}
+	if _, summary := m.readiness(obj); summary != "" {
+		out.PrintSublog("  Blocking: " + output.Preview(summary))
+	}
+	if warnings := m.WarningEvents(); len(warnings) > 0 {
+		out.PrintSublog("  Last warning: " + output.Preview(warnings[len(warnings)-1]))
+		messages = append(messages, warnings[len(warnings)-1])
+	}
+
+	m.mu.Lock()
+	token := m.requestToken
+	idle := time.Since(m.lastActivity)
+	m.mu.Unlock()
+	for _, hint := range waitHints(obj, m.kind, token, idle, m.matchHints(messages)) {
+		out.PrintSublog("  💡 " + hint)
+	}
+}
+
+// waitHints suggests what to do about a resource that is not ready, based on
+// its spec, conditions and how long nothing happened. matched are the hints
+// of the hint rules matching its messages.
+func waitHints(obj *unstructured.Unstructured, kind, token string, idle time.Duration, matched []string) []string {
+	if suspended, _, _ := unstructured.NestedBool(obj.Object, "spec", "suspend"); suspended {
+		return []string{"the resource is suspended and won't reconcile, resume it (flux resume ...)"}
+	}
+
+	var hints []string
+	handled, _, _ := unstructured.NestedString(obj.Object, "status", "lastHandledReconcileAt")
+	observed, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if (token != "" && handled != token) || observed < obj.GetGeneration() {
+		hints = append(hints, "the controller has not picked up the change yet, check that it is running (kubectl -n flux-system get pods)")
+	}
+
+	hints = append(hints, matched...)
+	_, reason, message := conditionStatus(obj, "Ready")
+	// The rules give more specific advice than the reason
+	if len(matched) == 0 {
+		switch reason {
+		case "ArtifactFailed", "SourceNotReady", "GitOperationFailed", "OCIOperationFailed", "ChartPullFailed":
+			hints = append(hints, "the source has no usable artifact, check its URL and credentials (flux-enhanced-cli source ... --namespace ...)")
+		case "BuildFailed":
+			if decryptionPattern.MatchString(message) {
+				hints = append(hints, "SOPS decryption failed, check spec.decryption and the decryption Secret (details are printed if the wait fails)")
+			} else {
+				hints = append(hints, "kustomize build failed, validate the sources locally with --path <dir> --dry-run server")
+			}
+		case "HealthCheckFailed":
+			hints = append(hints, "workloads are failing the health checks, the Ready message names them, check their pods (kubectl describe pod)")
+		case "InstallFailed", "UpgradeFailed", "RetriesExceeded":
+			hints = append(hints, "the Helm action failed, fix the chart or values and retry with --force")
+		}
+	}
+	if kind == "terraform" && terraformAwaitingApproval(obj) {
+		pending, _, _ := unstructured.NestedString(obj.Object, "status", "plan", "pending")
+		hints = append(hints, fmt.Sprintf("the plan awaits approval, set spec.approvePlan to %q", pending))
+	}
+	if len(hints) == 0 && idle > time.Minute {
+		hints = append(hints, fmt.Sprintf("no events or status changes for %s, consider --force-after to re-trigger stalled reconciles", formatDuration(idle)))
+	}
+	return hints
+}
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ResourceStatus is the observed state of a Flux resource
+type ResourceStatus struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Status is ready, not ready, progressing, checking, no conditions or
+	// unknown
+	Status                 string `json:"status"`
+	Conditions             string `json:"conditions,omitempty"`
+	Revision               string `json:"revision,omitempty"`
+	LastHandledReconcileAt string `json:"lastHandledReconcileAt,omitempty"`
+	Generation             int64  `json:"generation"`
+	ObservedGeneration     int64  `json:"observedGeneration"`
+}
+
+// Informers keeps Flux resources of several kinds in shared informer caches,
+// so their status can be read without a request per lookup.
+type Informers struct {
+	factory   dynamicinformer.DynamicSharedInformerFactory
+	informers map[string]informers.GenericInformer
+}
+
+// InformerOptions selects the resources cached by Informers
+type InformerOptions struct {
+	// Namespace to watch; empty watches all namespaces
+	Namespace     string
+	LabelSelector string
+	FieldSelector string
+}
+
+// NewInformers sets up informers for the monitor kinds. Start must be called
+// before use.
+func (c *Cluster) NewInformers(opts InformerOptions, kinds []string) (*Informers, error) {
+	tweak := func(o *metav1.ListOptions) {
+		o.LabelSelector = opts.LabelSelector
+		o.FieldSelector = opts.FieldSelector
+	}
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(c.dynamicClient, 0, opts.Namespace, tweak)
+	byKind := make(map[string]informers.GenericInformer, len(kinds))
+	for _, kind := range kinds {
+		gvr, err := c.ResolveKind(kind)
+		if err != nil {
+			return nil, err
+		}
+		byKind[kind] = factory.ForResource(gvr)
+	}
+	return &Informers{factory: factory, informers: byKind}, nil
+}
+
+// OnChange calls fn whenever a cached resource is added, updated or deleted.
+// It must be called before Start.
+func (i *Informers) OnChange(fn func()) error {
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { fn() },
+		UpdateFunc: func(interface{}, interface{}) { fn() },
+		DeleteFunc: func(interface{}) { fn() },
+	}
+	for _, informer := range i.informers {
+		if _, err := informer.Informer().AddEventHandler(handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns the cached resources of a monitor kind
+func (i *Informers) List(kind string) ([]*unstructured.Unstructured, error) {
+	informer, ok := i.informers[kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource kind: %s", kind)
+	}
+	cached, err := informer.Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]*unstructured.Unstructured, 0, len(cached))
+	for _, c := range cached {
+		if obj, ok := c.(*unstructured.Unstructured); ok {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+// Start runs the informers until ctx is done and waits for the initial sync
+func (i *Informers) Start(ctx context.Context) error {
+	i.factory.Start(ctx.Done())
+	for gvr, synced := range i.factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("failed to sync the %s cache", gvr.Resource)
+		}
+	}
+	return nil
+}
+
+// Serves reports whether the informers cache the monitor kind
+func (i *Informers) Serves(kind string) bool {
+	_, ok := i.informers[kind]
+	return ok
+}
+
+// Status returns the cached status of a resource. It returns a NotFound
+// error when the resource doesn't exist.
+func (i *Informers) Status(kind, namespace, name string) (*ResourceStatus, error) {
+	informer, ok := i.informers[kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource kind: %s", kind)
+	}
+	cached, err := informer.Lister().ByNamespace(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := cached.(*unstructured.Unstructured)
+	if !ok {
+		return nil, apierrors.NewNotFound(kindGroupResources[kind], name)
+	}
+
+	status, conditions := summarizeConditions(obj)
+	handled, _, _ := unstructured.NestedString(obj.Object, "status", "lastHandledReconcileAt")
+	observed, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	return &ResourceStatus{
+		Kind:                   kind,
+		Namespace:              namespace,
+		Name:                   name,
+		Status:                 status,
+		Conditions:             conditions,
+		Revision:               objectRevision(obj, kind),
+		LastHandledReconcileAt: handled,
+		Generation:             obj.GetGeneration(),
+		ObservedGeneration:     observed,
+	}, nil
+}
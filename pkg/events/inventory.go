@@ -0,0 +1,363 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// Object states, following the kstatus conventions
+const (
+	StatusCurrent    = "Current"
+	StatusInProgress = "InProgress"
+	StatusFailed     = "Failed"
+	StatusNotFound   = "NotFound"
+)
+
+// InventoryObject is an object applied by a Kustomization, as recorded in
+// its status.inventory
+type InventoryObject struct {
+	Namespace string
+	Name      string
+	Group     string
+	Kind      string
+	Version   string
+}
+
+// ID returns the inventory entry ID, <namespace>_<name>_<group>_<kind>
+func (o InventoryObject) ID() string {
+	return fmt.Sprintf("%s_%s_%s_%s", o.Namespace, o.Name, o.Group, o.Kind)
+}
+
+func (o InventoryObject) String() string {
+	if o.Namespace == "" {
+		return fmt.Sprintf("%s/%s", o.Kind, o.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", o.Kind, o.Namespace, o.Name)
+}
+
+// WorkloadStatus is the kstatus state of an inventory object: the rollout of
+// a Deployment, StatefulSet or DaemonSet, or the status of any other object
+type WorkloadStatus struct {
+	Object  InventoryObject
+	Status  string
+	Message string
+}
+
+// Inventory returns the objects recorded in the Kustomization's
+// status.inventory.entries. Entry IDs have the form
+// <namespace>_<name>_<group>_<kind>.
+func (m *Monitor) Inventory() ([]InventoryObject, error) {
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return nil, err
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return inventoryObjects(obj)
+}
+
+// inventoryObjects parses the status.inventory.entries of a Kustomization
+func inventoryObjects(obj *unstructured.Unstructured) ([]InventoryObject, error) {
+	entries, _, err := unstructured.NestedSlice(obj.Object, "status", "inventory", "entries")
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []InventoryObject
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _, _ := unstructured.NestedString(entry, "id")
+		version, _, _ := unstructured.NestedString(entry, "v")
+		parts := strings.Split(id, "_")
+		if len(parts) != 4 {
+			continue
+		}
+		objects = append(objects, InventoryObject{
+			Namespace: parts[0],
+			Name:      parts[1],
+			Group:     parts[2],
+			Kind:      parts[3],
+			Version:   version,
+		})
+	}
+	return objects, nil
+}
+
+// PruneEnabled reports whether the Kustomization has spec.prune set
+func (m *Monitor) PruneEnabled() (bool, error) {
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return false, err
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	prune, _, err := unstructured.NestedBool(obj.Object, "spec", "prune")
+	return prune, err
+}
+
+// IsWorkload reports whether the object is a Deployment, StatefulSet or
+// DaemonSet, whose pods are inspected when its rollout stalls
+func (o InventoryObject) IsWorkload() bool {
+	if o.Group != "apps" {
+		return false
+	}
+	return o.Kind == "Deployment" || o.Kind == "StatefulSet" || o.Kind == "DaemonSet"
+}
+
+// statuslessKinds have no status to wait for, so they are always Current
+var statuslessKinds = map[string]bool{
+	"/ConfigMap":                      true,
+	"/Secret":                         true,
+	"/ServiceAccount":                 true,
+	"/Endpoints":                      true,
+	"/LimitRange":                     true,
+	"/ResourceQuota":                  true,
+	"networking.k8s.io/NetworkPolicy": true,
+	"scheduling.k8s.io/PriorityClass": true,
+	"storage.k8s.io/StorageClass":     true,
+	"admissionregistration.k8s.io/MutatingWebhookConfiguration":   true,
+	"admissionregistration.k8s.io/ValidatingWebhookConfiguration": true,
+}
+
+func isStatusless(o InventoryObject) bool {
+	return o.Group == "rbac.authorization.k8s.io" || statuslessKinds[o.Group+"/"+o.Kind]
+}
+
+// inventoryHealthConcurrency bounds the object reads of InventoryHealth in
+// flight, so large inventories aren't read one GET after the other
+const inventoryHealthConcurrency = 10
+
+// InventoryHealth computes the kstatus state of every object in the
+// Kustomization's inventory, in inventory order. Objects without a status
+// (ConfigMaps, RBAC, ...) are skipped.
+func (m *Monitor) InventoryHealth(ctx context.Context) ([]WorkloadStatus, error) {
+	objects, err := m.Inventory()
+	if err != nil {
+		return nil, err
+	}
+
+	var checked []InventoryObject
+	for _, o := range objects {
+		if !isStatusless(o) {
+			checked = append(checked, o)
+		}
+	}
+	var (
+		wg       sync.WaitGroup
+		statuses = make([]WorkloadStatus, len(checked))
+		errs     = make([]error, len(checked))
+		sem      = make(chan struct{}, inventoryHealthConcurrency)
+	)
+	for i, o := range checked {
+		wg.Add(1)
+		go func(i int, o InventoryObject) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			var status, message string
+			var err error
+			if o.IsWorkload() {
+				status, message, err = m.workloadStatus(ctx, o)
+			} else {
+				status, message, err = m.objectStatus(ctx, o)
+			}
+			statuses[i], errs[i] = WorkloadStatus{Object: o, Status: status, Message: message}, err
+		}(i, o)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return statuses, nil
+}
+
+// objectStatus reads any inventory object through the dynamic client and
+// computes its kstatus state
+func (m *Monitor) objectStatus(ctx context.Context, o InventoryObject) (string, string, error) {
+	obj, missing, err := m.inventoryObject(ctx, o)
+	if err != nil {
+		return "", "", err
+	}
+	if obj == nil {
+		return StatusNotFound, missing, nil
+	}
+	status, message := ComputeStatus(obj)
+	return status, message, nil
+}
+
+// inventoryObject reads an inventory object through the dynamic client. It
+// returns a nil object and the reason when the object or its kind is gone.
+func (m *Monitor) inventoryObject(ctx context.Context, o InventoryObject) (*unstructured.Unstructured, string, error) {
+	m.mu.Lock()
+	if m.mapper == nil {
+		m.mapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(m.clientset.Discovery()))
+	}
+	mapper := m.mapper
+	m.mu.Unlock()
+
+	mapping, err := mapper.RESTMapping(schema.GroupKind{Group: o.Group, Kind: o.Kind}, o.Version)
+	if err != nil {
+		// The kind may be gone with its CRD, or not yet discovered
+		mapper.Reset()
+		if mapping, err = mapper.RESTMapping(schema.GroupKind{Group: o.Group, Kind: o.Kind}); err != nil {
+			return nil, fmt.Sprintf("unknown kind: %v", err), nil
+		}
+	}
+	obj, err := m.dynamicClient.Resource(mapping.Resource).Namespace(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, "object not found", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return obj, "", nil
+}
+
+func (m *Monitor) workloadStatus(ctx context.Context, o InventoryObject) (string, string, error) {
+	apps := m.clientset.AppsV1()
+	var err error
+	switch o.Kind {
+	case "Deployment":
+		var d *appsv1.Deployment
+		if d, err = apps.Deployments(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{}); err == nil {
+			status, message := deploymentStatus(d)
+			return status, message, nil
+		}
+	case "StatefulSet":
+		var sts *appsv1.StatefulSet
+		if sts, err = apps.StatefulSets(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{}); err == nil {
+			status, message := statefulSetStatus(sts)
+			return status, message, nil
+		}
+	case "DaemonSet":
+		var ds *appsv1.DaemonSet
+		if ds, err = apps.DaemonSets(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{}); err == nil {
+			status, message := daemonSetStatus(ds)
+			return status, message, nil
+		}
+	}
+	if err != nil && apierrors.IsNotFound(err) {
+		return StatusNotFound, "object not found", nil
+	}
+	return "", "", err
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// deploymentStatus mirrors kstatus' Deployment rules
+func deploymentStatus(d *appsv1.Deployment) (string, string) {
+	if d.Status.ObservedGeneration < d.Generation {
+		return StatusInProgress, "rollout not yet observed by the controller"
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" {
+			return StatusFailed, fmt.Sprintf("progress deadline exceeded: %s", c.Message)
+		}
+	}
+
+	desired := replicasOrDefault(d.Spec.Replicas)
+	switch {
+	case d.Status.UpdatedReplicas < desired:
+		return StatusInProgress, fmt.Sprintf("updated: %d/%d", d.Status.UpdatedReplicas, desired)
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		return StatusInProgress, fmt.Sprintf("pending termination: %d", d.Status.Replicas-d.Status.UpdatedReplicas)
+	case d.Status.AvailableReplicas < desired:
+		return StatusInProgress, fmt.Sprintf("available: %d/%d", d.Status.AvailableReplicas, desired)
+	case d.Status.ReadyReplicas < desired:
+		return StatusInProgress, fmt.Sprintf("ready: %d/%d", d.Status.ReadyReplicas, desired)
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionFalse {
+			return StatusInProgress, fmt.Sprintf("not available: %s", c.Message)
+		}
+	}
+	return StatusCurrent, fmt.Sprintf("%d/%d replicas ready", d.Status.ReadyReplicas, desired)
+}
+
+// statefulSetStatus mirrors kstatus' StatefulSet rules
+func statefulSetStatus(sts *appsv1.StatefulSet) (string, string) {
+	if sts.Status.ObservedGeneration < sts.Generation {
+		return StatusInProgress, "rollout not yet observed by the controller"
+	}
+	if sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return StatusCurrent, "OnDelete update strategy"
+	}
+
+	desired := replicasOrDefault(sts.Spec.Replicas)
+	switch {
+	case sts.Status.Replicas < desired:
+		return StatusInProgress, fmt.Sprintf("replicas: %d/%d", sts.Status.Replicas, desired)
+	case sts.Status.ReadyReplicas < desired:
+		return StatusInProgress, fmt.Sprintf("ready: %d/%d", sts.Status.ReadyReplicas, desired)
+	}
+
+	partition := int32(0)
+	if ru := sts.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+		partition = *ru.Partition
+	}
+	if partition > 0 {
+		if sts.Status.UpdatedReplicas < desired-partition {
+			return StatusInProgress, fmt.Sprintf("partitioned rollout: %d/%d updated", sts.Status.UpdatedReplicas, desired-partition)
+		}
+		return StatusCurrent, fmt.Sprintf("partitioned rollout complete (partition %d)", partition)
+	}
+	if sts.Status.CurrentReplicas < desired {
+		return StatusInProgress, fmt.Sprintf("current: %d/%d", sts.Status.CurrentReplicas, desired)
+	}
+	if sts.Status.UpdateRevision != "" && sts.Status.CurrentRevision != sts.Status.UpdateRevision {
+		return StatusInProgress, "waiting for the update revision to roll out"
+	}
+	return StatusCurrent, fmt.Sprintf("%d/%d replicas ready", sts.Status.ReadyReplicas, desired)
+}
+
+// daemonSetStatus mirrors kstatus' DaemonSet rules
+func daemonSetStatus(ds *appsv1.DaemonSet) (string, string) {
+	if ds.Status.ObservedGeneration < ds.Generation {
+		return StatusInProgress, "rollout not yet observed by the controller"
+	}
+
+	desired := ds.Status.DesiredNumberScheduled
+	switch {
+	case ds.Status.CurrentNumberScheduled < desired:
+		return StatusInProgress, fmt.Sprintf("scheduled: %d/%d", ds.Status.CurrentNumberScheduled, desired)
+	case ds.Status.UpdatedNumberScheduled < desired:
+		return StatusInProgress, fmt.Sprintf("updated: %d/%d", ds.Status.UpdatedNumberScheduled, desired)
+	case ds.Status.NumberAvailable < desired:
+		return StatusInProgress, fmt.Sprintf("available: %d/%d", ds.Status.NumberAvailable, desired)
+	case ds.Status.NumberReady < desired:
+		return StatusInProgress, fmt.Sprintf("ready: %d/%d", ds.Status.NumberReady, desired)
+	}
+	return StatusCurrent, fmt.Sprintf("%d/%d pods ready", ds.Status.NumberReady, desired)
+}
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// WatchInventoryEvents shows the Warning events of the inventory objects, and
+// of the pods and ReplicaSets of its workloads (FailedScheduling, Unhealthy,
+// BackOff, ...), until ctx is done. Events from before the run are skipped.
+func (m *Monitor) WatchInventoryEvents(ctx context.Context, objects []InventoryObject) {
+	byNamespace := map[string][]InventoryObject{}
+	for _, o := range objects {
+		if o.Namespace != "" {
+			byNamespace[o.Namespace] = append(byNamespace[o.Namespace], o)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for namespace, objects := range byNamespace {
+		wg.Add(1)
+		go func(namespace string, objects []InventoryObject) {
+			defer wg.Done()
+			m.watchWarningEvents(ctx, namespace, objects)
+		}(namespace, objects)
+	}
+	wg.Wait()
+}
+
+// watchWarningEvents lists and watches the namespace's Warning events like
+// watchObjectEvents, showing those about the objects
+func (m *Monitor) watchWarningEvents(ctx context.Context, namespace string, objects []InventoryObject) {
+	m.mu.Lock()
+	interval := m.intervals.Events
+	m.mu.Unlock()
+
+	client := newEventClient(m.clientset, namespace)
+	opts := metav1.ListOptions{FieldSelector: "type=" + corev1.EventTypeWarning}
+	for {
+		if list, err := client.List(ctx, opts); err != nil {
+			m.warnEventsError(ctx, err)
+		} else {
+			for _, evt := range list.Items {
+				m.showInventoryEvent(ctx, evt, objects)
+			}
+
+			watchOpts := opts
+			watchOpts.ResourceVersion = list.ResourceVersion
+			if w, err := client.Watch(ctx, watchOpts); err != nil {
+				m.warnEventsError(ctx, err)
+			} else {
+				m.consumeInventoryEvents(ctx, w, objects)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (m *Monitor) consumeInventoryEvents(ctx context.Context, w watch.Interface, objects []InventoryObject) {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			switch e.Type {
+			case watch.Added, watch.Modified:
+				if evt, ok := e.Object.(*eventsv1.Event); ok {
+					m.showInventoryEvent(ctx, *evt, objects)
+				}
+			case watch.Error:
+				return
+			}
+		}
+	}
+}
+
+// showInventoryEvent prints a new or recurring event of this run when it is
+// about one of the objects
+func (m *Monitor) showInventoryEvent(ctx context.Context, evt eventsv1.Event, objects []InventoryObject) {
+	if !concernsInventory(evt.Regarding, objects) || eventTime(evt).Before(m.createdAt.Truncate(time.Second)) {
+		return
+	}
+	m.mu.Lock()
+	if m.seenEvents[string(evt.UID)] == evt.ResourceVersion {
+		m.mu.Unlock()
+		return
+	}
+	m.seenEvents[string(evt.UID)] = evt.ResourceVersion
+	m.lastActivity = time.Now()
+	m.mu.Unlock()
+
+	output.RedactNames(evt.Regarding.Name)
+	label := evt.Regarding.Kind + "/" + evt.Regarding.Name + ": "
+	output.FromContext(ctx).PrintEvent(evt.Reason, label+output.Preview(evt.Note)+eventAge(evt), true)
+	m.recordWarning(evt.Reason + ": " + label + evt.Note)
+}
+
+// concernsInventory reports whether ref is one of the objects, or a pod or
+// ReplicaSet of one of its workloads, going by the generated name prefix
+func concernsInventory(ref corev1.ObjectReference, objects []InventoryObject) bool {
+	for _, o := range objects {
+		if ref.Kind == o.Kind && ref.Name == o.Name {
+			return true
+		}
+		if o.IsWorkload() && (ref.Kind == "Pod" || ref.Kind == "ReplicaSet") && strings.HasPrefix(ref.Name, o.Name+"-") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// StatusTerminating is the kstatus state of an object being deleted
+const StatusTerminating = "Terminating"
+
+// ComputeStatus computes the kstatus state of an object, following the rules
+// of sigs.k8s.io/cli-utils/pkg/kstatus: built-in types by their specific
+// status fields, everything else by the standard Ready, Reconciling and
+// Stalled conditions. The rules are reimplemented rather than imported, as
+// cli-utils isn't a dependency of this module.
+func ComputeStatus(obj *unstructured.Unstructured) (string, string) {
+	if obj.GetDeletionTimestamp() != nil {
+		return StatusTerminating, "resource scheduled for deletion"
+	}
+
+	gk := obj.GroupVersionKind().GroupKind()
+	switch gk.Group + "/" + gk.Kind {
+	case "apps/Deployment":
+		var d appsv1.Deployment
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &d); err == nil {
+			return deploymentStatus(&d)
+		}
+	case "apps/StatefulSet":
+		var sts appsv1.StatefulSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &sts); err == nil {
+			return statefulSetStatus(&sts)
+		}
+	case "apps/DaemonSet":
+		var ds appsv1.DaemonSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ds); err == nil {
+			return daemonSetStatus(&ds)
+		}
+	case "apps/ReplicaSet":
+		return replicaSetStatus(obj)
+	case "/Pod":
+		return podStatus(obj)
+	case "/PersistentVolumeClaim":
+		if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase != "Bound" {
+			return StatusInProgress, "PVC is not Bound"
+		}
+		return StatusCurrent, "PVC is Bound"
+	case "/Service":
+		serviceType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+		ingress, _, _ := unstructured.NestedSlice(obj.Object, "status", "loadBalancer", "ingress")
+		if serviceType == "LoadBalancer" && len(ingress) == 0 {
+			return StatusInProgress, "waiting for the load balancer"
+		}
+		return StatusCurrent, "Service is ready"
+	case "batch/Job":
+		if s, _, message := conditionStatus(obj, "Failed"); s == "True" {
+			return StatusFailed, fmt.Sprintf("Job failed: %s", message)
+		}
+		if s, _, _ := conditionStatus(obj, "Complete"); s == "True" {
+			return StatusCurrent, "Job completed"
+		}
+		return StatusInProgress, "Job in progress"
+	case "policy/PodDisruptionBudget":
+		if stale, message := generationStale(obj); stale {
+			return StatusInProgress, message
+		}
+		current, _, _ := unstructured.NestedInt64(obj.Object, "status", "currentHealthy")
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredHealthy")
+		if current < desired {
+			return StatusInProgress, fmt.Sprintf("healthy: %d/%d", current, desired)
+		}
+		return StatusCurrent, "budget is satisfied"
+	case "apiextensions.k8s.io/CustomResourceDefinition":
+		if s, _, message := conditionStatus(obj, "NamesAccepted"); s == "False" {
+			return StatusFailed, fmt.Sprintf("names not accepted: %s", message)
+		}
+		if s, _, _ := conditionStatus(obj, "Established"); s != "True" {
+			return StatusInProgress, "CRD is not established"
+		}
+		return StatusCurrent, "CRD is established"
+	}
+	return genericStatus(obj)
+}
+
+// genericStatus applies the kstatus rules for resources following the
+// standard conditions: Stalled=True means failed, Reconciling=True or
+// Ready!=True means in progress
+func genericStatus(obj *unstructured.Unstructured) (string, string) {
+	if stale, message := generationStale(obj); stale {
+		return StatusInProgress, message
+	}
+	if s, reason, message := conditionStatus(obj, "Stalled"); s == "True" {
+		return StatusFailed, conditionMessage(reason, message)
+	}
+	if s, reason, message := conditionStatus(obj, "Reconciling"); s == "True" {
+		return StatusInProgress, conditionMessage(reason, message)
+	}
+	if s, reason, message := conditionStatus(obj, "Ready"); s == "False" || s == "Unknown" {
+		return StatusInProgress, conditionMessage(reason, message)
+	}
+	return StatusCurrent, "resource is current"
+}
+
+// generationStale reports whether the controller has not yet observed the
+// latest spec
+func generationStale(obj *unstructured.Unstructured) (bool, string) {
+	observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if found && observed < obj.GetGeneration() {
+		return true, fmt.Sprintf("waiting for the controller to observe generation %d", obj.GetGeneration())
+	}
+	return false, ""
+}
+
+func conditionMessage(reason, message string) string {
+	if message == "" {
+		return reason
+	}
+	return message
+}
+
+func replicaSetStatus(obj *unstructured.Unstructured) (string, string) {
+	if stale, message := generationStale(obj); stale {
+		return StatusInProgress, message
+	}
+	desired, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		desired = 1
+	}
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	switch {
+	case ready < desired:
+		return StatusInProgress, fmt.Sprintf("ready: %d/%d", ready, desired)
+	case available < desired:
+		return StatusInProgress, fmt.Sprintf("available: %d/%d", available, desired)
+	}
+	return StatusCurrent, fmt.Sprintf("%d/%d replicas ready", ready, desired)
+}
+
+func podStatus(obj *unstructured.Unstructured) (string, string) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Succeeded":
+		return StatusCurrent, "Pod has completed successfully"
+	case "Failed":
+		return StatusFailed, "Pod has failed"
+	}
+	statuses, _, _ := unstructured.NestedSlice(obj.Object, "status", "containerStatuses")
+	for _, cs := range statuses {
+		reason, _, _ := unstructured.NestedString(cs.(map[string]interface{}), "state", "waiting", "reason")
+		if crashReasons[reason] {
+			return StatusFailed, fmt.Sprintf("container is in %s", reason)
+		}
+	}
+	if s, _, _ := conditionStatus(obj, "Ready"); phase == "Running" && s == "True" {
+		return StatusCurrent, "Pod is Ready"
+	}
+	return StatusInProgress, fmt.Sprintf("Pod is %s", phase)
+}
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ResourceSummary is a Flux resource as shown by "get"
+type ResourceSummary struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Ready is the status of the Ready condition: True, False or Unknown
+	Ready string
+	// Message is the message of the Ready condition
+	Message   string
+	Revision  string
+	Suspended bool
+	Created   time.Time
+	// Source is the source reference (Kustomizations, HelmReleases and
+	// HelmCharts) or URL (sources)
+	Source   string
+	Interval string
+}
+
+// Summarize extracts the columns shown by "get" from a resource of a
+// monitor kind
+func Summarize(obj *unstructured.Unstructured, kind string) ResourceSummary {
+	summary := ResourceSummary{
+		Kind:      obj.GetKind(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Ready:     "Unknown",
+		Revision:  objectRevision(obj, kind),
+		Created:   obj.GetCreationTimestamp().Time,
+	}
+	summary.Suspended, _, _ = unstructured.NestedBool(obj.Object, "spec", "suspend")
+	summary.Interval, _, _ = unstructured.NestedString(obj.Object, "spec", "interval")
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != "Ready" {
+			continue
+		}
+		summary.Ready, _, _ = unstructured.NestedString(cond, "status")
+		summary.Message, _, _ = unstructured.NestedString(cond, "message")
+	}
+	// A Ready condition from before the latest spec change is stale
+	if observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration"); found && observed < obj.GetGeneration() {
+		summary.Ready = "Unknown"
+		summary.Message = fmt.Sprintf("waiting for the controller to observe generation %d", obj.GetGeneration())
+	}
+
+	switch kind {
+	case "kustomization", "helmchart":
+		summary.Source = sourceRefString(obj, "spec", "sourceRef")
+	case "helmrelease":
+		summary.Source = sourceRefString(obj, "spec", "chart", "spec", "sourceRef")
+		if summary.Source == "" {
+			summary.Source = sourceRefString(obj, "spec", "chartRef")
+		}
+	default:
+		summary.Source, _, _ = unstructured.NestedString(obj.Object, "spec", "url")
+	}
+	return summary
+}
+
+// sourceRefString formats the reference at fields as Kind/[namespace/]name
+func sourceRefString(obj *unstructured.Unstructured, fields ...string) string {
+	ref, found, _ := unstructured.NestedStringMap(obj.Object, fields...)
+	if !found || ref["name"] == "" {
+		return ""
+	}
+	if ref["namespace"] != "" && ref["namespace"] != obj.GetNamespace() {
+		return fmt.Sprintf("%s/%s/%s", ref["kind"], ref["namespace"], ref["name"])
+	}
+	return fmt.Sprintf("%s/%s", ref["kind"], ref["name"])
+}
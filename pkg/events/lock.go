@@ -0,0 +1,216 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// lockRetryInterval is how often a Lease held by someone else is checked
+const lockRetryInterval = 2 * time.Second
+
+// LockOptions configures AcquireLock
+type LockOptions struct {
+	// Holder identifies the process in the Lease; it defaults to
+	// <hostname>-<pid>-<random>, since containers often share pids
+	Holder string
+	// TTL is how long the Lease stays valid without being renewed. It is
+	// renewed every third of it while held, and taken over once it expired.
+	TTL time.Duration
+	// Wait bounds how long to wait for a Lease held by someone else
+	Wait time.Duration
+	// Progress, if set, is called once when the Lease is held by someone else
+	Progress func(message string)
+	// Lost, if set, is called once when the held Lease was taken over or
+	// deleted, or couldn't be renewed for TTL so others may take it over
+	Lost func(message string)
+}
+
+// Lock is a held Lease, renewed until Release
+type Lock struct {
+	cluster   *Cluster
+	namespace string
+	name      string
+	holder    string
+	lost      func(message string)
+	stop      chan struct{}
+	done      chan struct{}
+	once      sync.Once
+}
+
+// LockName returns the name of the Lease serializing reconciles of a
+// resource of a monitor kind
+func LockName(kind, name string) string {
+	return strings.ToLower("flux-enhanced-cli-" + kind + "-" + name)
+}
+
+// AcquireLock takes the Lease namespace/name, creating it when missing,
+// waiting while another holder renews it and taking it over once it expired.
+// It fails when the Lease is still held after opts.Wait.
+func (c *Cluster) AcquireLock(ctx context.Context, namespace, name string, opts LockOptions) (*Lock, error) {
+	if opts.Holder == "" {
+		hostname, _ := os.Hostname()
+		opts.Holder = fmt.Sprintf("%s-%d-%06x", hostname, os.Getpid(), rand.Intn(1<<24))
+	}
+	leases := c.clientset.CoordinationV1().Leases(namespace)
+	deadline := time.Now().Add(opts.Wait)
+	reported := false
+	for {
+		now := metav1.NewMicroTime(time.Now())
+		lease, err := leases.Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			lease = &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    map[string]string{"app.kubernetes.io/managed-by": "flux-enhanced-cli"},
+				},
+				Spec: leaseSpec(opts, now),
+			}
+			_, err = leases.Create(ctx, lease, metav1.CreateOptions{})
+		case err != nil:
+			return nil, fmt.Errorf("failed to read lock %s/%s: %w", namespace, name, err)
+		case lockFree(lease, opts.Holder, now.Time):
+			transitions := ptr.Deref(lease.Spec.LeaseTransitions, 0)
+			if ptr.Deref(lease.Spec.HolderIdentity, "") != opts.Holder {
+				transitions++
+			}
+			lease.Spec = leaseSpec(opts, now)
+			lease.Spec.LeaseTransitions = &transitions
+			// The resourceVersion makes a concurrent takeover conflict
+			_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+		default:
+			if time.Now().After(deadline) {
+				return nil, fmt.Errorf("lock %s/%s is still held by %s after %s", namespace, name, ptr.Deref(lease.Spec.HolderIdentity, ""), opts.Wait)
+			}
+			if !reported && opts.Progress != nil {
+				opts.Progress(fmt.Sprintf("Waiting for lock %s/%s held by %s", namespace, name, ptr.Deref(lease.Spec.HolderIdentity, "")))
+				reported = true
+			}
+			err = apierrors.NewConflict(coordinationv1.Resource("leases"), name, nil)
+		}
+		if err == nil {
+			break
+		}
+		if !apierrors.IsConflict(err) && !apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("failed to acquire lock %s/%s: %w", namespace, name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+
+	l := &Lock{cluster: c, namespace: namespace, name: name, holder: opts.Holder, lost: opts.Lost, stop: make(chan struct{}), done: make(chan struct{})}
+	go l.renew(opts.TTL)
+	return l, nil
+}
+
+// leaseSpec returns the spec of a Lease acquired now
+func leaseSpec(opts LockOptions, now metav1.MicroTime) coordinationv1.LeaseSpec {
+	return coordinationv1.LeaseSpec{
+		HolderIdentity:       ptr.To(opts.Holder),
+		LeaseDurationSeconds: ptr.To(int32((opts.TTL + time.Second - 1) / time.Second)),
+		AcquireTime:          &now,
+		RenewTime:            &now,
+	}
+}
+
+// lockFree reports whether the Lease may be taken by holder: it is released,
+// already held by holder, or expired since it wasn't renewed in time
+func lockFree(lease *coordinationv1.Lease, holder string, now time.Time) bool {
+	current := ptr.Deref(lease.Spec.HolderIdentity, "")
+	if current == "" || current == holder {
+		return true
+	}
+	renewed := lease.Spec.RenewTime
+	if renewed == nil {
+		renewed = lease.Spec.AcquireTime
+	}
+	if renewed == nil {
+		return true
+	}
+	ttl := time.Duration(ptr.Deref(lease.Spec.LeaseDurationSeconds, 0)) * time.Second
+	return now.After(renewed.Add(ttl))
+}
+
+// renew keeps the Lease's renewTime fresh until Release. It stops once the
+// Lease was taken over or deleted, and reports that, or renewals failing for
+// longer than ttl, through l.lost.
+func (l *Lock) renew(ttl time.Duration) {
+	defer close(l.done)
+	leases := l.cluster.clientset.CoordinationV1().Leases(l.namespace)
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	renewed := time.Now()
+	expiryReported := false
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+		// A failed renewal is retried on the next tick, the Lease only
+		// expires after three
+		ctx, cancel := context.WithTimeout(context.Background(), ttl/3)
+		lease, err := leases.Get(ctx, l.name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			cancel()
+			l.reportLost(fmt.Sprintf("Lock %s/%s was deleted, concurrent runs are no longer kept apart", l.namespace, l.name))
+			return
+		case err == nil && ptr.Deref(lease.Spec.HolderIdentity, "") != l.holder:
+			cancel()
+			l.reportLost(fmt.Sprintf("Lock %s/%s was taken over by %s, concurrent runs are no longer kept apart", l.namespace, l.name, ptr.Deref(lease.Spec.HolderIdentity, "")))
+			return
+		case err == nil:
+			now := metav1.NewMicroTime(time.Now())
+			lease.Spec.RenewTime = &now
+			if _, err = leases.Update(ctx, lease, metav1.UpdateOptions{}); err == nil {
+				renewed = now.Time
+			}
+		}
+		cancel()
+		if err != nil && !expiryReported && time.Since(renewed) > ttl {
+			l.reportLost(fmt.Sprintf("Lock %s/%s couldn't be renewed for %s, other runs may take it over: %v", l.namespace, l.name, ttl, err))
+			expiryReported = true
+		}
+	}
+}
+
+// reportLost passes a message about losing the Lease to LockOptions.Lost
+func (l *Lock) reportLost(message string) {
+	if l.lost != nil {
+		l.lost(message)
+	}
+}
+
+// Release stops renewing the Lease and frees it for the next holder, unless
+// it was taken over meanwhile
+func (l *Lock) Release() {
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		leases := l.cluster.clientset.CoordinationV1().Leases(l.namespace)
+		lease, err := leases.Get(ctx, l.name, metav1.GetOptions{})
+		if err != nil || ptr.Deref(lease.Spec.HolderIdentity, "") != l.holder {
+			return
+		}
+		_ = leases.Delete(ctx, l.name, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion},
+		})
+	})
+}
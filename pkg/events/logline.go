@@ -0,0 +1,98 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LogLine is a structured (zap JSON) log line of a Flux controller
+type LogLine struct {
+	// Time is when the line was logged, zero when the line has no timestamp
+	Time  time.Time
+	Level string
+	// Kind, Namespace and Name identify the object the line is about, when
+	// logged
+	Kind      string
+	Namespace string
+	Name      string
+	Message   string
+	Error     string
+}
+
+// ParseLogLine reads a JSON log line of a Flux controller. It reports false
+// for lines that aren't JSON or carry no message.
+func ParseLogLine(line []byte) (LogLine, bool) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return LogLine{}, false
+	}
+	str := func(m map[string]interface{}, key string) string {
+		s, _ := m[key].(string)
+		return s
+	}
+
+	l := LogLine{
+		Level:     str(fields, "level"),
+		Kind:      str(fields, "controllerKind"),
+		Namespace: str(fields, "namespace"),
+		Name:      str(fields, "name"),
+		Message:   strings.TrimSpace(str(fields, "msg")),
+		Error:     strings.TrimSpace(str(fields, "error")),
+	}
+	if l.Message == "" {
+		return LogLine{}, false
+	}
+	switch ts := fields["ts"].(type) {
+	case string:
+		l.Time, _ = time.Parse(time.RFC3339Nano, ts)
+	case float64:
+		l.Time = time.Unix(0, int64(ts*float64(time.Second)))
+	}
+
+	// controller-runtime logs the object under its kind, e.g.
+	// "Kustomization":{"name":"apps","namespace":"flux-system"}; the
+	// notification-controller as involvedObject
+	if obj, ok := fields[l.Kind].(map[string]interface{}); ok && l.Kind != "" && l.Name == "" {
+		l.Namespace, l.Name = str(obj, "namespace"), str(obj, "name")
+	}
+	if obj, ok := fields["involvedObject"].(map[string]interface{}); ok && l.Name == "" {
+		l.Kind, l.Namespace, l.Name = str(obj, "kind"), str(obj, "namespace"), str(obj, "name")
+	}
+	return l, true
+}
+
+// Object returns the object as Kind/namespace/name, or "" when the line isn't
+// about one
+func (l LogLine) Object() string {
+	if l.Name == "" {
+		return ""
+	}
+	return strings.Join([]string{l.Kind, l.Namespace, l.Name}, "/")
+}
+
+// Text returns the message followed by the error, if any
+func (l LogLine) Text() string {
+	if l.Error == "" {
+		return l.Message
+	}
+	return l.Message + ": " + l.Error
+}
+
+// Format renders the line as "[level] object: message: error", leaving out
+// the level when it is info and the object unless withObject is set
+func (l LogLine) Format(withObject bool) string {
+	s := l.Text()
+	if obj := l.Object(); withObject && obj != "" {
+		s = fmt.Sprintf("%s: %s", obj, s)
+	}
+	if l.Level != "" && l.Level != "info" {
+		s = fmt.Sprintf("[%s] %s", l.Level, s)
+	}
+	return s
+}
+
+func (l LogLine) String() string {
+	return l.Format(true)
+}
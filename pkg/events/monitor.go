@@ -2,25 +2,41 @@ package events
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"sync"
 	"time"
 
-	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/restmapper"
 
 	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
 )
 
+// Intervals controls how often the monitor queries the API server
+type Intervals struct {
+	// Poll is the interval between readiness checks
+	Poll time.Duration
+	// Events is the delay before events are listed and watched again after
+	// a watch ends
+	Events time.Duration
+	// Status is the interval between "Still waiting" status lines
+	Status time.Duration
+}
+
+// DefaultIntervals are used for intervals that are not set
+var DefaultIntervals = Intervals{
+	Poll:   2 * time.Second,
+	Events: 3 * time.Second,
+	Status: 10 * time.Second,
+}
+
 type Monitor struct {
 	kind          string
 	name          string
@@ -30,23 +46,67 @@ type Monitor struct {
 	ctx           context.Context
 	cancel        context.CancelFunc
 	mu            sync.Mutex
-	lastHash      string
+	warnings      []string
+	conditions    string
+	phase         string
+	// related are further objects whose events are shown (e.g. sources)
+	related []SourceRef
+	// seenEvents maps the UIDs of printed events to their resourceVersion
+	seenEvents map[string]string
+	// seenObjects records the objects whose events were listed before, by
+	// eventTarget key
+	seenObjects map[string]bool
+	// lastActivity is when the last new event or condition change was seen
+	lastActivity time.Time
+	intervals    Intervals
+	// requestToken is a reconcile request that must be handled before the
+	// resource counts as ready
+	requestToken string
+	// gvr is set for custom resources (see NewCustomMonitor)
+	gvr *schema.GroupVersionResource
+	// readyConditions replace Ready=True as the readiness criteria
+	readyConditions []ConditionRequirement
+	// waitFor are JSONPath checks that must hold as well; without explicit
+	// readyConditions they replace Ready=True
+	waitFor []WaitExpression
+	// hintThresholds are the percentages of the timeout at which hints are
+	// printed while waiting
+	hintThresholds []int
+	// hintRules are checked before DefaultHintRules
+	hintRules []HintRule
+	// acknowledgedAt is when the request token was first seen handled
+	acknowledgedAt time.Time
+	// transitions are the readiness changes seen during the run
+	transitions []ReadinessTransition
+	// failing are the distinct not ready condition summaries seen
+	failing []string
+	// conditionStates are the conditions of the last check, and
+	// conditionChanges the changes not yet printed
+	conditionStates  []conditionState
+	conditionChanges []conditionChange
+	// failOn are the event reasons that abort the wait, counted from
+	// failOnSince; failEvent is the first such event seen
+	failOn      map[string]bool
+	failOnSince time.Time
+	failEvent   *EventFailureError
+	// mapper resolves the kinds of inventory objects, created on first use
+	mapper *restmapper.DeferredDiscoveryRESTMapper
+	// createdAt is when the monitor was created, before the trigger
+	createdAt time.Time
+	// changeSet are the objects the controller reported applying
+	changeSet     []ChangeSetEntry
+	changeSetSeen map[ChangeSetEntry]bool
+	// eventsWarning reports the first failure to list or watch events
+	eventsWarning sync.Once
 }
 
-func NewMonitor(ctx context.Context, kind, name, namespace string) (*Monitor, error) {
-	config, err := getKubeConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
-	}
-
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create clientset: %w", err)
+func NewMonitor(ctx context.Context, clientOpts ClientOptions, kind, name, namespace string) (*Monitor, error) {
+	if clientOpts.Printer == nil {
+		clientOpts.Printer = output.FromContext(ctx)
 	}
-
-	dynamicClient, err := dynamic.NewForConfig(config)
+	clientset, dynamicClient, err := newClients(clientOpts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+		return nil, err
 	}
 
 	monitorCtx, cancel := context.WithCancel(ctx)
@@ -59,96 +119,247 @@ func NewMonitor(ctx context.Context, kind, name, namespace string) (*Monitor, er
 		dynamicClient: dynamicClient,
 		ctx:           monitorCtx,
 		cancel:        cancel,
+		lastActivity:  time.Now(),
+		createdAt:     time.Now(),
+		changeSetSeen: map[ChangeSetEntry]bool{},
+		intervals:     DefaultIntervals,
+		seenEvents:    map[string]string{},
+		seenObjects:   map[string]bool{},
+
+		hintThresholds: DefaultHintThresholds,
 	}, nil
 }
 
-func getKubeConfig() (*rest.Config, error) {
-	// Try in-cluster config first
-	config, err := rest.InClusterConfig()
-	if err == nil {
-		return config, nil
+// SetIntervals overrides the polling intervals; zero fields keep their
+// defaults. It must be called before Watch.
+func (m *Monitor) SetIntervals(intervals Intervals) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if intervals.Poll > 0 {
+		m.intervals.Poll = intervals.Poll
 	}
-
-	// Check for KUBECONFIG environment variable
-	kubeconfig := os.Getenv("KUBECONFIG")
-	if kubeconfig == "" {
-		kubeconfig = clientcmd.RecommendedHomeFile
+	if intervals.Events > 0 {
+		m.intervals.Events = intervals.Events
 	}
+	if intervals.Status > 0 {
+		m.intervals.Status = intervals.Status
+	}
+}
+
+// SetReadyConditions makes readiness require every condition instead of
+// Ready=True. It must be called before WaitForReady.
+func (m *Monitor) SetReadyConditions(conditions []ConditionRequirement) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readyConditions = conditions
+}
+
+// SetWaitExpressions makes readiness require every expression to hold. Unless
+// SetReadyConditions is also called, conditions are no longer checked. It
+// must be called before WaitForReady.
+func (m *Monitor) SetWaitExpressions(expressions []WaitExpression) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.waitFor = expressions
+}
+
+// PollInterval returns the interval between readiness checks
+func (m *Monitor) PollInterval() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.intervals.Poll
+}
 
-	// Fall back to kubeconfig file
-	config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+// WatchSources adds the resource's sources (a Kustomization's sourceRef, a
+// HelmRelease's HelmChart and chart source) to the objects whose events are
+// shown. It must be called before Watch.
+func (m *Monitor) WatchSources() error {
+	gvr, err := m.getResourceGVR()
 	if err != nil {
-		return nil, err
+		return err
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	refs, err := sourceRefs(obj, m.kind)
+	if err != nil {
+		return err
 	}
-	return config, nil
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.related = append(m.related, refs...)
+	return nil
 }
 
-func (m *Monitor) Watch() {
-	ticker := time.NewTicker(3 * time.Second)
-	defer ticker.Stop()
+// Sources returns the related source objects added by WatchSources
+func (m *Monitor) Sources() []SourceRef {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]SourceRef(nil), m.related...)
+}
 
-	for {
-		select {
-		case <-m.ctx.Done():
+func (m *Monitor) recordWarning(warning string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.warnings {
+		if w == warning {
 			return
-		case <-ticker.C:
-			m.checkEvents()
 		}
 	}
+	m.warnings = append(m.warnings, warning)
 }
 
-func (m *Monitor) checkEvents() {
-	fieldSelector := fields.AndSelectors(
-		fields.OneTermEqualSelector("involvedObject.name", m.name),
-		fields.OneTermEqualSelector("involvedObject.namespace", m.namespace),
-	).String()
+// WarningEvents returns the distinct warning events seen during the run
+func (m *Monitor) WarningEvents() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.warnings...)
+}
 
-	events, err := m.clientset.CoreV1().Events(m.namespace).List(m.ctx, metav1.ListOptions{
-		FieldSelector: fieldSelector,
-		Limit:         10,
-	})
+// LastActivity returns when the monitor last saw a new event or a change of
+// the resource's conditions
+func (m *Monitor) LastActivity() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastActivity
+}
 
+// MarkActivity resets the no-progress timer, e.g. after a new trigger
+func (m *Monitor) MarkActivity() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastActivity = time.Now()
+}
+
+// ExpectHandled makes readiness also require status.lastHandledReconcileAt to
+// match the given reconcile request token
+func (m *Monitor) ExpectHandled(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requestToken = token
+}
+
+// PendingRequest returns the resource's reconcile request annotation when
+// the controller hasn't handled it yet, i.e. a reconcile someone else
+// requested is in flight, or "" when there is none
+func (m *Monitor) PendingRequest() (string, error) {
+	_, token, err := m.ReconcileInProgress()
+	return token, err
+}
+
+// ReconcileInProgress reports whether the resource is being reconciled: its
+// Reconciling condition is True or a reconcile request is pending. token is
+// the pending request, if any.
+func (m *Monitor) ReconcileInProgress() (inProgress bool, token string, err error) {
+	gvr, err := m.getResourceGVR()
 	if err != nil {
-		return
+		return false, "", err
 	}
-
-	// Get the most recent events
-	if len(events.Items) == 0 {
-		return
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return false, "", err
+	}
+	requested := obj.GetAnnotations()[RequestedAtAnnotation]
+	handled, _, _ := unstructured.NestedString(obj.Object, "status", "lastHandledReconcileAt")
+	if requested != handled {
+		token = requested
 	}
+	reconciling, _, _ := conditionStatus(obj, "Reconciling")
+	return token != "" || reconciling == "True", token, nil
+}
 
-	// Create a hash of recent events to detect changes
-	hash := ""
-	for i := len(events.Items) - 1; i >= 0 && i >= len(events.Items)-3; i-- {
-		evt := events.Items[i]
-		hash += fmt.Sprintf("%s:%s:%s", evt.Reason, evt.Type, evt.Message)
+// LastReconciled returns when the resource last reconciled successfully, the
+// latest of its handled reconcile request, its Ready condition turning True,
+// its artifact update and its last Helm release. It is zero when the resource
+// isn't Ready at its current generation.
+func (m *Monitor) LastReconciled() (time.Time, error) {
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return time.Time{}, err
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return time.Time{}, err
+	}
+	observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if found && observed != obj.GetGeneration() {
+		return time.Time{}, nil
 	}
 
-	m.mu.Lock()
-	if hash != m.lastHash {
-		m.lastHash = hash
-		m.mu.Unlock()
+	var times []string
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	ready := false
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != "Ready" {
+			continue
+		}
+		if cond["status"] != "True" {
+			return time.Time{}, nil
+		}
+		ready = true
+		if t, ok := cond["lastTransitionTime"].(string); ok {
+			times = append(times, t)
+		}
+	}
+	if !ready {
+		return time.Time{}, nil
+	}
+	handled, _, _ := unstructured.NestedString(obj.Object, "status", "lastHandledReconcileAt")
+	updated, _, _ := unstructured.NestedString(obj.Object, "status", "artifact", "lastUpdateTime")
+	times = append(times, handled, updated)
+	if history, _, _ := unstructured.NestedSlice(obj.Object, "status", "history"); len(history) > 0 {
+		if latest, ok := history[0].(map[string]interface{}); ok {
+			deployed, _, _ := unstructured.NestedString(latest, "lastDeployed")
+			times = append(times, deployed)
+		}
+	}
 
-		// Show the 2 most recent events
-		shown := 0
-		for i := len(events.Items) - 1; i >= 0 && shown < 2; i-- {
-			evt := events.Items[i]
-			isWarning := evt.Type == corev1.EventTypeWarning ||
-				evt.Reason == "HealthCheckFailed" ||
-				evt.Reason == "DependencyNotReady"
-			output.PrintEvent(evt.Reason, evt.Message, isWarning)
-			shown++
+	var last time.Time
+	for _, value := range times {
+		// The request annotation is any string, usually a date
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil && t.After(last) {
+			last = t
 		}
-	} else {
-		m.mu.Unlock()
 	}
+	return last, nil
+}
+
+// AcknowledgedAt returns when the controller was first seen to have handled
+// the request token passed to ExpectHandled, or the zero time
+func (m *Monitor) AcknowledgedAt() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.acknowledgedAt
+}
+
+// Conditions returns the last observed condition summary of the resource
+func (m *Monitor) Conditions() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.conditions
+}
+
+// Phase returns the last observed HelmRelease lifecycle phase (installing,
+// upgrading, testing, released, ...) or Terraform phase (planning, awaiting
+// approval, applying, ...), or "" for other kinds
+func (m *Monitor) Phase() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.phase
 }
 
 func (m *Monitor) WaitForReady(ctx context.Context, timeout time.Duration) error {
+	out := output.FromContext(ctx)
 	deadline := time.Now().Add(timeout)
 	startTime := time.Now()
-	ticker := time.NewTicker(2 * time.Second)
-	statusTicker := time.NewTicker(10 * time.Second) // Show status every 10 seconds
+	m.mu.Lock()
+	intervals := m.intervals
+	thresholds := m.hintThresholds
+	m.mu.Unlock()
+	ticker := time.NewTicker(intervals.Poll)
+	statusTicker := time.NewTicker(intervals.Status) // Show status periodically
 	defer ticker.Stop()
 	defer statusTicker.Stop()
 
@@ -159,6 +370,10 @@ func (m *Monitor) WaitForReady(ctx context.Context, timeout time.Duration) error
 	}
 
 	lastStatusTime := time.Now()
+	var lastDiscovery time.Time
+	var phases []string
+	hinted := 0
+	shownStatus := ""
 	for {
 		select {
 		case <-ctx.Done():
@@ -169,71 +384,151 @@ func (m *Monitor) WaitForReady(ctx context.Context, timeout time.Duration) error
 			remaining := time.Until(deadline)
 			status, conditions := m.getResourceStatus(gvr)
 			if status != "" {
-				output.PrintStatus(fmt.Sprintf("Still waiting... (elapsed: %s, remaining: %s)",
+				out.PrintStatus(fmt.Sprintf("Still waiting... (elapsed: %s, remaining: %s)",
 					formatDuration(elapsed), formatDuration(remaining)))
-				if conditions != "" {
-					output.PrintStatus(fmt.Sprintf("Current status: %s", conditions))
+				// Condition changes are printed as they happen; repeat the
+				// status only when the phase changes
+				if conditions != "" && status != shownStatus {
+					out.PrintStatus(fmt.Sprintf("Current status: %s", statusLine(status, conditions)))
+					shownStatus = status
 				}
+				m.printConditionChanges(out)
 			}
 		case <-ticker.C:
+			if err := m.failedByEvent(); err != nil {
+				return err
+			}
 			if time.Now().After(deadline) {
 				// Show final status before timeout
-				_, conditions := m.getResourceStatus(gvr)
+				status, conditions := m.getResourceStatus(gvr)
 				if conditions != "" {
-					output.PrintStatus(fmt.Sprintf("Timeout reached. Last known status: %s", conditions))
+					out.PrintStatus(fmt.Sprintf("Timeout reached. Last known status: %s", statusLine(status, conditions)))
 				}
 				return fmt.Errorf("timeout waiting for %s reconciliation", m.kind)
 			}
 
 			// Check if resource is ready using dynamic client
 			ready, err := m.checkResourceReady(gvr)
+			var failed *ResourceFailedError
+			if errors.As(err, &failed) {
+				return err
+			}
 			if err != nil {
+				// The served version may have changed mid-wait (e.g. a Flux upgrade);
+				// re-resolve the GVR via discovery and carry on with the new one.
+				if (apierrors.IsNotFound(err) || apierrors.IsGone(err)) && time.Since(lastDiscovery) > 10*time.Second {
+					lastDiscovery = time.Now()
+					if newGVR, derr := m.rediscoverGVR(gvr); derr == nil && newGVR != gvr {
+						out.PrintStatus(fmt.Sprintf("API version changed: %s → %s, continuing wait",
+							gvr.GroupVersion().String(), newGVR.GroupVersion().String()))
+						gvr = newGVR
+						continue
+					}
+				}
 				// Show error periodically but continue waiting
 				if time.Since(lastStatusTime) > 10*time.Second {
-					output.PrintStatus(fmt.Sprintf("Unable to check status: %v (will retry)", err))
+					out.PrintStatus(fmt.Sprintf("Unable to check status: %v (will retry)", err))
 					lastStatusTime = time.Now()
 				}
 				continue
 			}
+
+			m.printConditionChanges(out)
+
+			// Show HelmRelease lifecycle transitions (installing → testing → released)
+			if phase := m.Phase(); phase != "" && (len(phases) == 0 || phases[len(phases)-1] != phase) {
+				phases = append(phases, phase)
+				out.PrintSublog(fmt.Sprintf("🚢 %s", strings.Join(phases, " → ")))
+			}
 			if ready {
 				return nil
 			}
+
+			// Explain what blocks the resource as the timeout approaches
+			for hinted < len(thresholds) && time.Since(startTime) >= timeout*time.Duration(thresholds[hinted])/100 {
+				if hinted == len(thresholds)-1 || time.Since(startTime) < timeout*time.Duration(thresholds[hinted+1])/100 {
+					m.printHints(ctx, gvr, time.Since(startTime), timeout, thresholds[hinted])
+				}
+				hinted++
+			}
 		}
 	}
 }
 
-func (m *Monitor) checkResourceReady(gvr schema.GroupVersionResource) (bool, error) {
-	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+// IsReady checks once whether the resource currently reports Ready=True,
+// without printing anything.
+func (m *Monitor) IsReady() (bool, error) {
+	gvr, err := m.getResourceGVR()
 	if err != nil {
 		return false, err
 	}
-
-	// Check status.conditions for Ready condition
-	status, found, err := unstructured.NestedMap(obj.Object, "status")
-	if !found || err != nil {
-		return false, err
+	ready, err := m.checkResourceReady(gvr)
+	var failed *ResourceFailedError
+	if errors.As(err, &failed) {
+		return false, nil
 	}
+	return ready, err
+}
 
-	conditions, found, err := unstructured.NestedSlice(status, "conditions")
-	if !found || err != nil {
+// rediscoverGVR looks up the version the API server currently serves for the
+// resource, preferring the group's preferred version.
+func (m *Monitor) rediscoverGVR(gvr schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	return discoverGVR(m.clientset.Discovery(), gvr)
+}
+
+func (m *Monitor) checkResourceReady(gvr schema.GroupVersionResource) (bool, error) {
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
 		return false, err
 	}
 
-	for _, cond := range conditions {
-		condMap, ok := cond.(map[string]interface{})
-		if !ok {
-			continue
+	status, conditions := m.readiness(obj)
+	m.recordConditions(conditions)
+	m.recordConditionStates(obj)
+	m.recordReadiness(obj, status, conditions)
+	m.mu.Lock()
+	switch m.kind {
+	case "helmrelease":
+		if phase := helmReleasePhase(obj); phase != "" {
+			m.phase = phase
 		}
-
-		condType, _, _ := unstructured.NestedString(condMap, "type")
-		condStatus, _, _ := unstructured.NestedString(condMap, "status")
-
-		if condType == "Ready" && condStatus == "True" {
-			return true, nil
+	case "terraform":
+		if phase := terraformPhase(obj); phase != "" {
+			m.phase = phase
+		}
+	}
+	token := m.requestToken
+	m.mu.Unlock()
+
+	if token != "" {
+		// A Ready condition from before the request is stale
+		handled, _, _ := unstructured.NestedString(obj.Object, "status", "lastHandledReconcileAt")
+		if handled != token {
+			return false, nil
+		}
+		m.mu.Lock()
+		if m.acknowledgedAt.IsZero() {
+			m.acknowledgedAt = time.Now()
 		}
+		m.mu.Unlock()
 	}
+	if status == "failed" || status == "terminating" {
+		return false, &ResourceFailedError{Kind: m.kind, Status: status, Message: conditions}
+	}
+	return status == "ready", nil
+}
+
+// ResourceFailedError is returned while waiting when kstatus reports the
+// resource as Failed (e.g. Stalled=True) or Terminating, which waiting longer
+// won't resolve
+type ResourceFailedError struct {
+	Kind    string
+	Status  string
+	Message string
+}
 
-	return false, nil
+func (e *ResourceFailedError) Error() string {
+	return fmt.Sprintf("%s is %s: %s", e.Kind, e.Status, e.Message)
 }
 
 func (m *Monitor) getResourceStatus(gvr schema.GroupVersionResource) (string, string) {
@@ -242,16 +537,154 @@ func (m *Monitor) getResourceStatus(gvr schema.GroupVersionResource) (string, st
 		return "", fmt.Sprintf("error getting resource: %v", err)
 	}
 
+	status, conditions := m.readiness(obj)
+	m.recordConditions(conditions)
+	m.recordConditionStates(obj)
+	m.recordReadiness(obj, status, conditions)
+	return status, conditions
+}
+
+// recordConditions stores the latest condition summary, counting a change
+// as activity
+func (m *Monitor) recordConditions(conditions string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if conditions != "" && conditions != m.conditions {
+		m.conditions = conditions
+		m.lastActivity = time.Now()
+	}
+}
+
+// readiness returns the short status and summary of obj against the required
+// conditions and wait expressions
+func (m *Monitor) readiness(obj *unstructured.Unstructured) (string, string) {
+	m.mu.Lock()
+	expressions := m.waitFor
+	conditionsSet := len(m.readyConditions) > 0
+	m.mu.Unlock()
+
+	status, summary := "ready", ""
+	switch {
+	case conditionsSet:
+		status, summary = summarizeReadiness(obj, m.requiredConditions())
+	case len(expressions) == 0:
+		status, summary = summarizeConditions(obj)
+	}
+	var parts []string
+	if summary != "" && status != "ready" {
+		parts = append(parts, summary)
+	}
+	pending := false
+	for _, e := range expressions {
+		ok, actual, err := e.Evaluate(obj)
+		switch {
+		case err != nil:
+			parts = append(parts, fmt.Sprintf("%s: %v", e, err))
+		case !ok:
+			parts = append(parts, fmt.Sprintf("waiting for %s (currently %q)", e, actual))
+		default:
+			continue
+		}
+		pending = true
+	}
+	if !pending {
+		if len(expressions) > 0 && status == "ready" {
+			for _, e := range expressions {
+				parts = append(parts, e.String())
+			}
+			if summary != "" {
+				parts = append([]string{summary}, parts...)
+			}
+			return status, strings.Join(parts, ", ")
+		}
+		return status, summary
+	}
+	if status == "ready" {
+		status = "checking"
+	}
+	return status, strings.Join(parts, ", ")
+}
+
+// requiredConditions returns the conditions that make the resource ready
+func (m *Monitor) requiredConditions() []ConditionRequirement {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.readyConditions) > 0 {
+		return m.readyConditions
+	}
+	return DefaultReadyConditions
+}
+
+// summarizeConditions returns a short status ("ready", "not ready",
+// "failed", ...) and a human-readable summary of the object's status
+// conditions. Readiness follows the kstatus rules: Stalled=True is "failed"
+// (as is a failed signature verification of a source),
+// a deletion in progress "terminating", and Reconciling=True keeps a Ready
+// resource "progressing". Before the first Ready verdict, the Healthy and
+// Reconciling conditions give the phase: "health checking" or "reconciling".
+func summarizeConditions(obj *unstructured.Unstructured) (string, string) {
+	status, summary := summarizeReadiness(obj, DefaultReadyConditions)
+	kstatus, message := ComputeStatus(obj)
+	// Retrying won't fix a signature the source doesn't verify
+	if failure := verificationFailure(obj); failure != "" && kstatus != StatusTerminating {
+		kstatus, message = StatusFailed, failure
+	}
+	switch kstatus {
+	case StatusTerminating:
+		return "terminating", message
+	case StatusFailed:
+		return "failed", message
+	case StatusInProgress:
+		if status == "ready" {
+			return "progressing", message
+		}
+	}
+	if phase, message := reconcilePhase(obj); phase != "" && status != "progressing" {
+		return phase, message
+	}
+	return status, summary
+}
+
+// reconcilePhase returns the phase of a resource whose Ready condition is
+// Unknown or missing: "health checking" while a Kustomization's Healthy
+// condition is Unknown, "reconciling" while Reconciling is True
+func reconcilePhase(obj *unstructured.Unstructured) (string, string) {
+	if ready, _, _ := conditionStatus(obj, "Ready"); ready == "True" || ready == "False" {
+		return "", ""
+	}
+	if healthy, reason, message := conditionStatus(obj, "Healthy"); healthy == "Unknown" {
+		return "health checking", conditionMessage(reason, message)
+	}
+	if reconciling, reason, message := conditionStatus(obj, "Reconciling"); reconciling == "True" {
+		return "reconciling", conditionMessage(reason, message)
+	}
+	return "", ""
+}
+
+// summarizeReadiness is summarizeConditions with readiness given by required
+// conditions, which must all hold at once
+func summarizeReadiness(obj *unstructured.Unstructured, required []ConditionRequirement) (string, string) {
 	status, found, err := unstructured.NestedMap(obj.Object, "status")
 	if !found || err != nil {
 		return "unknown", ""
 	}
 
+	// A Ready condition from before the latest spec change is stale
+	if observed, found, _ := unstructured.NestedInt64(status, "observedGeneration"); found && observed < obj.GetGeneration() {
+		return "progressing", fmt.Sprintf("waiting for the controller to observe generation %d", obj.GetGeneration())
+	}
+
 	conditions, found, err := unstructured.NestedSlice(status, "conditions")
 	if !found || err != nil {
 		return "no conditions", ""
 	}
 
+	wanted := map[string]string{}
+	for _, r := range required {
+		wanted[r.Type] = r.Status
+	}
+	satisfied := 0
+
 	// Collect all condition statuses
 	var statusParts []string
 	for _, cond := range conditions {
@@ -264,9 +697,11 @@ func (m *Monitor) getResourceStatus(gvr schema.GroupVersionResource) (string, st
 		condStatus, _, _ := unstructured.NestedString(condMap, "status")
 		condMessage, _, _ := unstructured.NestedString(condMap, "message")
 
-		if condType == "Ready" {
-			if condStatus == "True" {
-				return "ready", "Ready=True"
+		if want, ok := wanted[condType]; ok {
+			delete(wanted, condType)
+			if condStatus == want {
+				satisfied++
+				continue
 			}
 			if condMessage != "" {
 				statusParts = append(statusParts, fmt.Sprintf("%s=%s (%s)", condType, condStatus, condMessage))
@@ -279,13 +714,40 @@ func (m *Monitor) getResourceStatus(gvr schema.GroupVersionResource) (string, st
 		}
 	}
 
+	if satisfied == len(required) {
+		names := make([]string, len(required))
+		for i, r := range required {
+			names[i] = r.String()
+		}
+		return "ready", strings.Join(names, ", ")
+	}
 	if len(statusParts) == 0 {
-		return "checking", ""
+		var pending []string
+		for _, r := range required {
+			if _, ok := wanted[r.Type]; ok {
+				pending = append(pending, r.String())
+			}
+		}
+		// A missing Ready condition is normal right after creation
+		if len(pending) == 0 || (len(required) == 1 && required[0] == DefaultReadyConditions[0]) {
+			return "checking", ""
+		}
+		return "checking", "waiting for " + strings.Join(pending, ", ")
 	}
 
 	return "not ready", strings.Join(statusParts, ", ")
 }
 
+// statusLine formats a status for display: the phase with its message while
+// reconciling or health checking, the condition summary otherwise
+func statusLine(status, summary string) string {
+	switch status {
+	case "reconciling", "health checking":
+		return fmt.Sprintf("%s (%s)", status, output.Preview(summary))
+	}
+	return output.Preview(summary)
+}
+
 func formatDuration(d time.Duration) string {
 	if d < time.Minute {
 		return fmt.Sprintf("%.0fs", d.Seconds())
@@ -303,6 +765,9 @@ func (m *Monitor) Stop() {
 // getResourceGVR determines the GroupVersionResource for the monitored resource.
 // For HelmRelease, it tries v2 first and falls back to v2beta1.
 func (m *Monitor) getResourceGVR() (schema.GroupVersionResource, error) {
+	if m.gvr != nil {
+		return *m.gvr, nil
+	}
 	switch m.kind {
 	case "kustomization":
 		return schema.GroupVersionResource{
@@ -340,6 +805,15 @@ func (m *Monitor) getResourceGVR() (schema.GroupVersionResource, error) {
 			Version:  "v1beta2",
 			Resource: "ocirepositories",
 		}, nil
+	case "bucket":
+		return schema.GroupVersionResource{
+			Group:    "source.toolkit.fluxcd.io",
+			Version:  "v1beta2",
+			Resource: "buckets",
+		}, nil
+	case "terraform":
+		// tf-controller moves between alpha versions, so ask discovery
+		return discoverGVR(m.clientset.Discovery(), kindGroupResources["terraform"].WithVersion(""))
 	default:
 		return schema.GroupVersionResource{}, fmt.Errorf("unsupported resource kind: %s", m.kind)
 	}
@@ -0,0 +1,153 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// crashReasons are container waiting reasons that won't resolve on their own
+var crashReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"CreateContainerConfigError": true,
+	"InvalidImageName":           true,
+}
+
+// PodProblem is a container of a workload's pod stuck in a crash or image
+// pull loop
+type PodProblem struct {
+	Namespace string
+	Pod       string
+	Container string
+	Reason    string
+	Message   string
+	// Restarted is set when the container has crashed before, so the logs of
+	// the previous instance hold the failure
+	Restarted bool
+}
+
+// WorkloadPodProblems returns the containers of the workload's pods that are
+// crash looping or failing to pull their image.
+func (m *Monitor) WorkloadPodProblems(ctx context.Context, o InventoryObject) ([]PodProblem, error) {
+	selector, err := m.workloadSelector(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := m.clientset.CoreV1().Pods(o.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []PodProblem
+	for _, pod := range pods.Items {
+		statuses := append(append([]corev1.ContainerStatus(nil), pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.State.Waiting == nil || !crashReasons[cs.State.Waiting.Reason] {
+				continue
+			}
+			problems = append(problems, PodProblem{
+				Namespace: pod.Namespace,
+				Pod:       pod.Name,
+				Container: cs.Name,
+				Reason:    cs.State.Waiting.Reason,
+				Message:   cs.State.Waiting.Message,
+				Restarted: cs.RestartCount > 0,
+			})
+		}
+	}
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].Pod != problems[j].Pod {
+			return problems[i].Pod < problems[j].Pod
+		}
+		return problems[i].Container < problems[j].Container
+	})
+	return problems, nil
+}
+
+func (m *Monitor) workloadSelector(ctx context.Context, o InventoryObject) (string, error) {
+	apps := m.clientset.AppsV1()
+	var selector *metav1.LabelSelector
+	switch o.Kind {
+	case "Deployment":
+		d, err := apps.Deployments(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		selector = d.Spec.Selector
+	case "StatefulSet":
+		sts, err := apps.StatefulSets(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		selector = sts.Spec.Selector
+	case "DaemonSet":
+		ds, err := apps.DaemonSets(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		selector = ds.Spec.Selector
+	default:
+		return "", fmt.Errorf("unsupported workload kind: %s", o.Kind)
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
+
+// PodLogs returns the last lines of a container's log. For restarted
+// containers the previous instance's log is used, since the current one has
+// usually not started yet.
+func (m *Monitor) PodLogs(ctx context.Context, p PodProblem, lines int64) ([]string, error) {
+	return m.podLogTail(ctx, p.Namespace, p.Pod, p.Container, p.Restarted, lines)
+}
+
+func (m *Monitor) podLogTail(ctx context.Context, namespace, pod, container string, previous bool, lines int64) ([]string, error) {
+	opts := &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &lines,
+		Previous:  previous,
+	}
+	data, err := m.clientset.CoreV1().Pods(namespace).GetLogs(pod, opts).DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimRight(string(data), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+// PodWarningEvents returns the warning events recorded for a pod, oldest first
+func (m *Monitor) PodWarningEvents(ctx context.Context, namespace, pod string) ([]string, error) {
+	fieldSelector := fields.AndSelectors(
+		fields.OneTermEqualSelector("regarding.name", pod),
+		fields.OneTermEqualSelector("regarding.kind", "Pod"),
+		fields.OneTermEqualSelector("type", corev1.EventTypeWarning),
+	).String()
+
+	list, err := newEventClient(m.clientset, namespace).List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return eventTime(list.Items[i]).Before(eventTime(list.Items[j]))
+	})
+
+	var events []string
+	for _, evt := range list.Items {
+		events = append(events, fmt.Sprintf("%s: %s", evt.Reason, strings.TrimSpace(evt.Note)))
+	}
+	return events, nil
+}
@@ -0,0 +1,227 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// testAlertPrefix names the temporary Alerts created by TestProvider, which
+// are also the names of the synthetic objects their events are about
+const testAlertPrefix = "flux-enhanced-cli-test-"
+
+// ProviderTest configures TestProvider
+type ProviderTest struct {
+	Namespace string
+	Name      string
+	// Severity of the test event, info or error
+	Severity string
+	Message  string
+	// FluxNamespace is where the notification-controller runs
+	FluxNamespace string
+	// Wait is how long the notification-controller logs are watched for the
+	// outcome of the dispatch
+	Wait time.Duration
+	// Progress, if set, is called before each step
+	Progress func(message string)
+}
+
+// ProviderTestResult is the outcome of TestProvider
+type ProviderTestResult struct {
+	// Type is the Provider's spec.type
+	Type string
+	// Object is the synthetic involved object of the event, as
+	// Kind/namespace/name
+	Object string
+	// Failed is set when the notification-controller logged a failed
+	// dispatch, with its message in Error
+	Failed bool
+	Error  string
+	// Confirmed is set when the notification-controller logged the dispatch
+	// without an error (it only does with --log-level=debug)
+	Confirmed bool
+	// LogErr is set when the notification-controller logs couldn't be read,
+	// so the outcome is unknown
+	LogErr error
+}
+
+// TestProvider sends a synthetic event through a Provider: it creates a
+// temporary Alert forwarding the events of a made-up Kustomization to the
+// Provider, posts such an event to the notification-controller's event
+// endpoint (through the API server's service proxy) and watches the
+// controller's logs for the dispatch. The Alert is deleted afterwards.
+func (c *Cluster) TestProvider(ctx context.Context, test ProviderTest) (*ProviderTestResult, error) {
+	progress := test.Progress
+	if progress == nil {
+		progress = func(string) {}
+	}
+
+	providersGVR, err := discoverGVR(c.clientset.Discovery(), providersResource.WithVersion(""))
+	if err != nil {
+		return nil, err
+	}
+	provider, err := c.dynamicClient.Resource(providersGVR).Namespace(test.Namespace).Get(ctx, test.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if suspended, _, _ := unstructured.NestedBool(provider.Object, "spec", "suspend"); suspended {
+		return nil, fmt.Errorf("provider %s/%s is suspended", test.Namespace, test.Name)
+	}
+	if err := notReady(provider); err != nil {
+		return nil, fmt.Errorf("provider %s/%s is not ready: %w", test.Namespace, test.Name, err)
+	}
+	result := &ProviderTestResult{}
+	result.Type, _, _ = unstructured.NestedString(provider.Object, "spec", "type")
+
+	name := testAlertPrefix + strconv.FormatInt(time.Now().UnixNano(), 36)
+	result.Object = fmt.Sprintf("Kustomization/%s/%s", test.Namespace, name)
+	alertsGVR := alertsResource.WithVersion(providersGVR.Version)
+	progress(fmt.Sprintf("Creating temporary Alert %s/%s", test.Namespace, name))
+	alert := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": alertsGVR.GroupVersion().String(),
+		"kind":       "Alert",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": test.Namespace,
+			"labels":    map[string]interface{}{"app.kubernetes.io/managed-by": "flux-enhanced-cli"},
+		},
+		"spec": map[string]interface{}{
+			"providerRef":   map[string]interface{}{"name": test.Name},
+			"eventSeverity": "info",
+			"eventSources":  []interface{}{map[string]interface{}{"kind": "Kustomization", "name": name}},
+		},
+	}}
+	if _, err := c.dynamicClient.Resource(alertsGVR).Namespace(test.Namespace).Create(ctx, alert, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to create the test alert: %w", err)
+	}
+	defer func() {
+		// Clean up even when interrupted
+		_ = c.dynamicClient.Resource(alertsGVR).Namespace(test.Namespace).Delete(context.WithoutCancel(ctx), name, metav1.DeleteOptions{})
+	}()
+	if err := c.waitForAlert(ctx, alertsGVR, test.Namespace, name); err != nil {
+		return nil, err
+	}
+
+	tailCtx, stopTail := context.WithCancel(ctx)
+	defer stopTail()
+	outcome := make(chan NotificationLog, 1)
+	tailDone := make(chan struct{})
+	go func() {
+		defer close(tailDone)
+		result.LogErr = c.TailNotifications(tailCtx, test.FluxNamespace, time.Now(), func(entry NotificationLog) {
+			if !strings.Contains(entry.Object, name) && !strings.Contains(entry.Message, name) {
+				return
+			}
+			select {
+			case outcome <- entry:
+			default:
+			}
+		})
+	}()
+
+	progress(fmt.Sprintf("Posting a test event to the notification-controller in %s", test.FluxNamespace))
+	if err := c.postEvent(ctx, test, name); err != nil {
+		return nil, fmt.Errorf("failed to post the test event: %w", err)
+	}
+
+	select {
+	case entry := <-outcome:
+		if entry.Error {
+			result.Failed, result.Error = true, entry.Message
+		} else {
+			result.Confirmed = true
+		}
+	case <-tailDone:
+		// Tailing failed, the outcome is unknown
+		return result, nil
+	case <-time.After(test.Wait):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	stopTail()
+	<-tailDone
+	return result, nil
+}
+
+// notReady returns the Ready condition's message when it is False. Objects
+// without conditions (Providers and Alerts of v1beta3 aren't reconciled)
+// count as ready.
+func notReady(obj *unstructured.Unstructured) error {
+	for _, c := range conditionStates(obj) {
+		if c.Type == "Ready" && c.Status == "False" {
+			return fmt.Errorf("%s: %s", c.Reason, c.Message)
+		}
+	}
+	return nil
+}
+
+// waitForAlert waits until a reconciled Alert (before v1beta3) is Ready, as
+// the notification-controller ignores Alerts that aren't
+func (c *Cluster) waitForAlert(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) error {
+	if gvr.Version != "v1beta1" && gvr.Version != "v1beta2" {
+		return nil
+	}
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		alert, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		for _, cond := range conditionStates(alert) {
+			if cond.Type != "Ready" {
+				continue
+			}
+			if cond.Status == "True" {
+				return nil
+			}
+			if cond.Status == "False" {
+				return fmt.Errorf("test alert is not ready: %s", cond.Message)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+	return fmt.Errorf("test alert %s/%s did not become ready", namespace, name)
+}
+
+// postEvent sends an event about the synthetic Kustomization to the
+// notification-controller's event endpoint, the way the Flux controllers
+// report their events
+func (c *Cluster) postEvent(ctx context.Context, test ProviderTest, name string) error {
+	event := map[string]interface{}{
+		"involvedObject": map[string]interface{}{
+			"apiVersion": "kustomize.toolkit.fluxcd.io/v1",
+			"kind":       "Kustomization",
+			"namespace":  test.Namespace,
+			"name":       name,
+		},
+		"severity":            test.Severity,
+		"timestamp":           time.Now().UTC().Format(time.RFC3339),
+		"message":             test.Message,
+		"reason":              "TestNotification",
+		"reportingController": "flux-enhanced-cli",
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return c.clientset.CoreV1().RESTClient().Post().
+		Namespace(test.FluxNamespace).
+		Resource("services").
+		Name("notification-controller:http").
+		SubResource("proxy").
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		Do(ctx).
+		Error()
+}
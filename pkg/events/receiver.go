@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Receiver is a notification-controller Receiver, resolved so its webhook
+// can be called without the Kubernetes API
+type Receiver struct {
+	Namespace string
+	Name      string
+	// Type is spec.type (generic, generic-hmac, github, ...)
+	Type string
+	// Path is the webhook path, /hook/<digest>
+	Path string
+	// Token is the secret token the webhook requests are signed with
+	Token string
+
+	obj *unstructured.Unstructured
+}
+
+// Receiver reads a Receiver with its webhook path and the token from its
+// secret. A token given by the caller is used instead, so the secret needn't
+// be readable.
+func (c *Cluster) Receiver(ctx context.Context, namespace, name, token string) (*Receiver, error) {
+	gvr, err := discoverGVR(c.clientset.Discovery(), receiversResource.WithVersion(""))
+	if err != nil {
+		return nil, err
+	}
+	obj, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Receiver{Namespace: namespace, Name: name, obj: obj}
+	r.Type, _, _ = unstructured.NestedString(obj.Object, "spec", "type")
+	r.Path, _, _ = unstructured.NestedString(obj.Object, "status", "webhookPath")
+	if r.Path == "" {
+		// Before v1 the path was only reported as status.url
+		r.Path, _, _ = unstructured.NestedString(obj.Object, "status", "url")
+	}
+	if r.Path == "" {
+		return nil, fmt.Errorf("receiver %s/%s has no webhook path yet (is it ready?)", namespace, name)
+	}
+	if suspended, _, _ := unstructured.NestedBool(obj.Object, "spec", "suspend"); suspended {
+		return nil, fmt.Errorf("receiver %s/%s is suspended", namespace, name)
+	}
+
+	if token != "" {
+		r.Token = token
+		return r, nil
+	}
+	secretName, _, _ := unstructured.NestedString(obj.Object, "spec", "secretRef", "name")
+	secret, err := c.clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the token of receiver %s/%s: %w", namespace, name, err)
+	}
+	r.Token = string(secret.Data["token"])
+	return r, nil
+}
+
+// ReceiverSelects reports whether the Receiver's resources include the
+// resource of a monitor kind
+func (c *Cluster) ReceiverSelects(ctx context.Context, r *Receiver, kind, namespace, name string) (bool, error) {
+	gvr, err := c.ResolveKind(kind)
+	if err != nil {
+		return false, err
+	}
+	obj, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	return refsSelect(r.obj, obj, "spec", "resources"), nil
+}
+
+// RequestedAt returns the resource's reconcile request annotation, which a
+// Receiver sets when it is called
+func (c *Cluster) RequestedAt(ctx context.Context, kind, namespace, name string) (string, error) {
+	gvr, err := c.ResolveKind(kind)
+	if err != nil {
+		return "", err
+	}
+	obj, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return obj.GetAnnotations()[RequestedAtAnnotation], nil
+}
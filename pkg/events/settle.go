@@ -0,0 +1,66 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// WaitSettled keeps checking the resource for d after it became ready,
+// recording every change between ready and not ready, e.g. a HelmRelease
+// whose remediation rolls back right after the upgrade. It fails when the
+// resource isn't ready when the period ends; one that dropped and recovered
+// within it is left to Flapping.
+func (m *Monitor) WaitSettled(ctx context.Context, d time.Duration) error {
+	out := output.FromContext(ctx)
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return err
+	}
+	out.PrintSublog(fmt.Sprintf("⏱️  Watching for %s to confirm %s/%s stays ready...", formatDuration(d), m.kind, m.name))
+
+	ticker := time.NewTicker(m.PollInterval())
+	defer ticker.Stop()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	start := time.Now()
+	status, conditions := "ready", ""
+	var dropped time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			switch {
+			case status != "ready" && dropped > 0:
+				return fmt.Errorf("%s became %s %s after being ready and did not recover within the settle period: %s", m.kind, status, formatDuration(dropped), conditions)
+			case status != "ready":
+				return fmt.Errorf("%s did not return to ready within the settle period: %s", m.kind, conditions)
+			case dropped > 0:
+				out.PrintSublog(fmt.Sprintf("⚠️  %s/%s is ready again after dropping %s into the settle period", m.kind, m.name, formatDuration(dropped)))
+			default:
+				out.PrintSublog(fmt.Sprintf("✅ %s/%s stayed ready for %s", m.kind, m.name, formatDuration(d)))
+			}
+			return nil
+		case <-ticker.C:
+			current, summary := m.getResourceStatus(gvr)
+			if current == "" {
+				// Errors reading the resource are retried like while waiting
+				continue
+			}
+			if current != status {
+				out.PrintStatus(fmt.Sprintf("Status changed to %s after %s: %s", current, formatDuration(time.Since(start)), output.Preview(summary)))
+			}
+			switch current {
+			case "not ready", "failed", "terminating":
+				if dropped == 0 {
+					dropped = time.Since(start)
+				}
+			}
+			status, conditions = current, summary
+		}
+	}
+}
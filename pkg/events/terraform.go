@@ -0,0 +1,103 @@
+package events
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TerraformStatus is the plan and outputs state of a tf-controller Terraform
+type TerraformStatus struct {
+	// Plan is the message of the Plan condition
+	Plan string
+	// PendingPlan is the ID of a plan that has not been applied yet
+	PendingPlan string
+	// AwaitingApproval is set when the pending plan needs spec.approvePlan
+	AwaitingApproval bool
+	DestroyPlan      bool
+	// Outputs are the names of the outputs, written to OutputsSecret
+	Outputs       []string
+	OutputsSecret string
+}
+
+// TerraformStatus returns the Terraform's plan and outputs state
+func (m *Monitor) TerraformStatus() (*TerraformStatus, error) {
+	gvr, err := m.getResourceGVR()
+	if err != nil {
+		return nil, err
+	}
+	obj, err := m.dynamicClient.Resource(gvr).Namespace(m.namespace).Get(m.ctx, m.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TerraformStatus{}
+	_, _, status.Plan = conditionStatus(obj, "Plan")
+	status.PendingPlan, _, _ = unstructured.NestedString(obj.Object, "status", "plan", "pending")
+	status.AwaitingApproval = terraformAwaitingApproval(obj)
+	status.DestroyPlan, _, _ = unstructured.NestedBool(obj.Object, "status", "plan", "isDestroyPlan")
+	status.Outputs, _, _ = unstructured.NestedStringSlice(obj.Object, "status", "availableOutputs")
+	status.OutputsSecret, _, _ = unstructured.NestedString(obj.Object, "spec", "writeOutputsToSecret", "name")
+	return status, nil
+}
+
+// terraformAwaitingApproval reports whether the Terraform has a pending plan
+// that spec.approvePlan ("auto", or the plan ID or a prefix of it) doesn't
+// approve
+func terraformAwaitingApproval(obj *unstructured.Unstructured) bool {
+	pending, _, _ := unstructured.NestedString(obj.Object, "status", "plan", "pending")
+	if pending == "" {
+		return false
+	}
+	approve, _, _ := unstructured.NestedString(obj.Object, "spec", "approvePlan")
+	return approve != "auto" && (approve == "" || !strings.HasPrefix(pending, approve))
+}
+
+// conditionStatus returns the status, reason and message of a condition
+func conditionStatus(obj *unstructured.Unstructured, condType string) (string, string, string) {
+	list, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range list {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if t, _, _ := unstructured.NestedString(cond, "type"); t != condType {
+			continue
+		}
+		status, _, _ := unstructured.NestedString(cond, "status")
+		reason, _, _ := unstructured.NestedString(cond, "reason")
+		message, _, _ := unstructured.NestedString(cond, "message")
+		return status, reason, message
+	}
+	return "", "", ""
+}
+
+// terraformPhase derives the plan/apply phase of a Terraform from its Ready,
+// Plan and Apply conditions and status.plan
+func terraformPhase(obj *unstructured.Unstructured) string {
+	_, planReason, _ := conditionStatus(obj, "Plan")
+	if s, _, _ := conditionStatus(obj, "Ready"); s == "True" {
+		if planReason == "TerraformPlannedNoChanges" {
+			return "no changes"
+		}
+		return "applied"
+	}
+	applyStatus, _, _ := conditionStatus(obj, "Apply")
+	planStatus, _, _ := conditionStatus(obj, "Plan")
+	switch {
+	case applyStatus == "False":
+		return "apply failed"
+	case planStatus == "False":
+		return "plan failed"
+	case terraformAwaitingApproval(obj):
+		return "awaiting approval"
+	case applyStatus == "Unknown":
+		return "applying"
+	case planStatus == "Unknown":
+		return "planning"
+	case planReason == "TerraformPlannedWithChanges":
+		return "planned"
+	}
+	return ""
+}
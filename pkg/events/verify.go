@@ -0,0 +1,77 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Verification is the signature verification of a source's artifact,
+// configured with spec.verify
+type Verification struct {
+	// Provider is cosign or notation for OCI sources, openpgp for git
+	Provider string
+	// Verified is set once the SourceVerified condition is True
+	Verified bool
+	Message  string
+	// Identity describes what the signature was checked against: the OIDC
+	// identities of keyless cosign, or the secret holding the keys
+	Identity string
+}
+
+// sourceVerification returns the verification of a source, or nil when it
+// has no spec.verify
+func sourceVerification(obj *unstructured.Unstructured) *Verification {
+	verify, found, _ := unstructured.NestedMap(obj.Object, "spec", "verify")
+	if !found {
+		return nil
+	}
+
+	v := &Verification{}
+	v.Provider, _, _ = unstructured.NestedString(verify, "provider")
+	if v.Provider == "" {
+		v.Provider = "cosign"
+		if obj.GetKind() == "GitRepository" {
+			v.Provider = "openpgp"
+		}
+	}
+	status, reason, message := conditionStatus(obj, "SourceVerified")
+	v.Verified = status == "True"
+	v.Message = conditionMessage(reason, message)
+
+	identities, _, _ := unstructured.NestedSlice(verify, "matchOIDCIdentity")
+	var matches []string
+	for _, i := range identities {
+		identity, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		issuer, _, _ := unstructured.NestedString(identity, "issuer")
+		subject, _, _ := unstructured.NestedString(identity, "subject")
+		matches = append(matches, fmt.Sprintf("issuer %s, subject %s", issuer, subject))
+	}
+	secret, _, _ := unstructured.NestedString(verify, "secretRef", "name")
+	switch {
+	case len(matches) > 0:
+		v.Identity = strings.Join(matches, " or ")
+	case secret != "":
+		v.Identity = "keys in secret " + secret
+	case v.Provider == "cosign":
+		v.Identity = "any keyless identity"
+	}
+	return v
+}
+
+// verificationFailure returns why the signature verification of a source
+// failed, or "" unless SourceVerified is False
+func verificationFailure(obj *unstructured.Unstructured) string {
+	if _, found, _ := unstructured.NestedMap(obj.Object, "spec", "verify"); !found {
+		return ""
+	}
+	status, reason, message := conditionStatus(obj, "SourceVerified")
+	if status != "False" {
+		return ""
+	}
+	return "signature verification failed: " + conditionMessage(reason, message)
+}
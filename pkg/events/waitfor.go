@@ -0,0 +1,200 @@
+package events
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// WaitExpression is a readiness check on the live object. It is a CEL
+// expression evaluating to a bool over the object's top-level fields, like
+// Flux's healthCheckExprs, e.g.
+//
+//	status.conditions.exists(c, c.type == "Ready" && c.status == "True")
+//
+// or else a JSONPath: either a path that must be set (and not false), or a
+// path compared with ==, != or =~ (regular expression) to a value, e.g.
+//
+//	status.lastAppliedRevision =~ "^main@"
+type WaitExpression struct {
+	raw     string
+	program cel.Program
+	path    *jsonpath.JSONPath
+	op      string
+	value   string
+	regex   *regexp.Regexp
+}
+
+// celFields are the top-level fields of an object CEL expressions refer to
+var celFields = []string{"apiVersion", "kind", "metadata", "spec", "status"}
+
+// celEnv is the CEL environment of wait expressions, with the object's
+// top-level fields as dynamic variables
+var celEnv = sync.OnceValues(func() (*cel.Env, error) {
+	options := make([]cel.EnvOption, 0, len(celFields))
+	for _, field := range celFields {
+		options = append(options, cel.Variable(field, cel.DynType))
+	}
+	return cel.NewEnv(options...)
+})
+
+// compileCEL compiles expr as a CEL expression evaluating to a bool
+func compileCEL(expr string) (cel.Program, error) {
+	env, err := celEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expr)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("evaluates to %s, not bool", ast.OutputType())
+	}
+	return env.Program(ast)
+}
+
+func (e WaitExpression) String() string {
+	return e.raw
+}
+
+// ParseWaitExpression parses a --wait-for expression, as CEL when it is a
+// valid boolean CEL expression and as JSONPath otherwise
+func ParseWaitExpression(expr string) (WaitExpression, error) {
+	e := WaitExpression{raw: strings.TrimSpace(expr)}
+	program, celErr := compileCEL(e.raw)
+	if celErr == nil {
+		e.program = program
+		return e, nil
+	}
+	// Only CEL combines conditions
+	if strings.Contains(e.raw, "&&") || strings.Contains(e.raw, "||") {
+		return e, fmt.Errorf("invalid CEL wait expression %q: %v", expr, celErr)
+	}
+
+	path := e.raw
+	if i, op := findOperator(e.raw); i >= 0 {
+		path, e.op = strings.TrimSpace(e.raw[:i]), op
+		e.value = unquote(strings.TrimSpace(e.raw[i+len(op):]))
+		if e.op == "=~" {
+			re, err := regexp.Compile(e.value)
+			if err != nil {
+				return e, fmt.Errorf("invalid wait expression %q: %w", expr, err)
+			}
+			e.regex = re
+		}
+	}
+	if path == "" {
+		return e, fmt.Errorf("invalid wait expression %q: missing JSONPath", expr)
+	}
+	if !strings.HasPrefix(path, "{") {
+		path = "{." + strings.TrimPrefix(path, ".") + "}"
+	}
+	e.path = jsonpath.New("wait-for").AllowMissingKeys(true)
+	if err := e.path.Parse(path); err != nil {
+		return e, fmt.Errorf("invalid wait expression %q: %w", expr, err)
+	}
+	return e, nil
+}
+
+// findOperator returns the position of the first ==, != or =~ outside of
+// quotes, brackets and parentheses (JSONPath filters contain their own ==)
+func findOperator(expr string) (int, string) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(expr)-1; i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '(' || c == '{':
+			depth++
+		case c == ']' || c == ')' || c == '}':
+			depth--
+		case depth == 0:
+			for _, op := range []string{"==", "!=", "=~"} {
+				if strings.HasPrefix(expr[i:], op) {
+					return i, op
+				}
+			}
+		}
+	}
+	return -1, ""
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1]
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+// Evaluate checks the expression against obj, returning whether it holds and
+// the value found at the path (values of several matches joined by spaces),
+// or the result of a CEL expression
+func (e WaitExpression) Evaluate(obj *unstructured.Unstructured) (bool, string, error) {
+	if e.program != nil {
+		return e.evaluateCEL(obj)
+	}
+	results, err := e.path.FindResults(obj.Object)
+	if err != nil {
+		return false, "", err
+	}
+	var values []string
+	for _, result := range results {
+		for _, v := range result {
+			if v.IsValid() && v.CanInterface() {
+				values = append(values, fmt.Sprint(v.Interface()))
+			}
+		}
+	}
+	actual := strings.Join(values, " ")
+
+	switch e.op {
+	case "==":
+		return actual == e.value, actual, nil
+	case "!=":
+		return actual != e.value, actual, nil
+	case "=~":
+		return e.regex.MatchString(actual), actual, nil
+	}
+	return len(values) > 0 && actual != "" && actual != "false", actual, nil
+}
+
+// evaluateCEL evaluates a CEL expression against obj. Fields the object
+// doesn't have yet, e.g. before the first status update, make it not hold
+// rather than fail.
+func (e WaitExpression) evaluateCEL(obj *unstructured.Unstructured) (bool, string, error) {
+	vars := make(map[string]interface{}, len(celFields))
+	for _, field := range celFields {
+		vars[field] = map[string]interface{}{}
+		if v, ok := obj.Object[field]; ok {
+			vars[field] = v
+		}
+	}
+	result, _, err := e.program.Eval(vars)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "no such key") {
+			return false, err.Error(), nil
+		}
+		return false, "", err
+	}
+	holds, ok := result.Value().(bool)
+	if !ok {
+		return false, "", fmt.Errorf("evaluates to %v, not a bool", result.Value())
+	}
+	return holds, strconv.FormatBool(holds), nil
+}
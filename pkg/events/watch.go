@@ -0,0 +1,208 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/tracing"
+)
+
+// eventTarget is an object whose events are shown. Related objects' messages
+// are prefixed with label.
+type eventTarget struct {
+	kind      string
+	namespace string
+	name      string
+	label     string
+}
+
+func (t eventTarget) key() string {
+	return t.kind + "/" + t.namespace + "/" + t.name
+}
+
+// fieldSelector selects the target's events in the events.k8s.io/v1 API
+func (t eventTarget) fieldSelector() string {
+	selectors := []fields.Selector{
+		fields.OneTermEqualSelector("regarding.name", t.name),
+		fields.OneTermEqualSelector("regarding.namespace", t.namespace),
+	}
+	if t.kind != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("regarding.kind", t.kind))
+	}
+	return fields.AndSelectors(selectors...).String()
+}
+
+// Watch shows the events of the resource and its related sources until the
+// monitor is stopped.
+func (m *Monitor) Watch() {
+	targets := []eventTarget{{namespace: m.namespace, name: m.name}}
+	for _, r := range m.Sources() {
+		targets = append(targets, eventTarget{
+			kind:      r.APIKind,
+			namespace: r.Namespace,
+			name:      r.Name,
+			label:     r.APIKind + "/" + r.Name + ": ",
+		})
+	}
+
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Add(1)
+		go func(t eventTarget) {
+			defer wg.Done()
+			m.watchObjectEvents(t)
+		}(t)
+	}
+	wg.Wait()
+}
+
+// watchObjectEvents lists the target's events and watches them from the
+// listed resourceVersion. When the watch ends (e.g. its resourceVersion
+// expired) the events are listed again after the event interval, so nothing
+// emitted in between is missed.
+func (m *Monitor) watchObjectEvents(t eventTarget) {
+	m.mu.Lock()
+	interval := m.intervals.Events
+	m.mu.Unlock()
+
+	client := newEventClient(m.clientset, t.namespace)
+	opts := metav1.ListOptions{FieldSelector: t.fieldSelector()}
+	for {
+		if list, err := client.List(m.ctx, opts); err != nil {
+			m.warnEventsError(m.ctx, err)
+		} else {
+			m.showEvents(t, list.Items)
+
+			watchOpts := opts
+			watchOpts.ResourceVersion = list.ResourceVersion
+			if w, err := client.Watch(m.ctx, watchOpts); err != nil {
+				m.warnEventsError(m.ctx, err)
+			} else {
+				m.consumeEvents(t, w)
+			}
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// warnEventsError prints the first error listing or watching events, since
+// the events are missing from the output from then on
+func (m *Monitor) warnEventsError(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	m.eventsWarning.Do(func() {
+		output.FromContext(ctx).PrintWarning(fmt.Sprintf("Could not watch events, they may be missing: %v", err))
+	})
+}
+
+// consumeEvents shows the events received from w until it ends
+func (m *Monitor) consumeEvents(t eventTarget, w watch.Interface) {
+	defer w.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			switch e.Type {
+			case watch.Added, watch.Modified:
+				if evt, ok := e.Object.(*eventsv1.Event); ok {
+					m.showEvents(t, []eventsv1.Event{*evt})
+				}
+			case watch.Error:
+				return
+			}
+		}
+	}
+}
+
+// showEvents prints the events that are new or recurred (their series count
+// went up), tracked by UID and resourceVersion. On the target's first listing
+// only the 2 most recent earlier events are shown.
+func (m *Monitor) showEvents(t eventTarget, items []eventsv1.Event) {
+	sort.SliceStable(items, func(i, j int) bool { return eventTime(items[i]).Before(eventTime(items[j])) })
+
+	m.mu.Lock()
+	initialized := m.seenObjects[t.key()]
+	m.seenObjects[t.key()] = true
+	var fresh []eventsv1.Event
+	for i, evt := range items {
+		if m.seenEvents[string(evt.UID)] == evt.ResourceVersion {
+			continue
+		}
+		m.seenEvents[string(evt.UID)] = evt.ResourceVersion
+		m.checkFailEvent(t, evt, initialized)
+		m.recordChangeSet(t, evt)
+		if !initialized && i < len(items)-2 {
+			continue
+		}
+		fresh = append(fresh, evt)
+	}
+	if len(fresh) > 0 {
+		m.lastActivity = time.Now()
+	}
+	m.mu.Unlock()
+
+	out := output.FromContext(m.ctx)
+	for _, evt := range fresh {
+		isWarning := evt.Type == corev1.EventTypeWarning ||
+			evt.Reason == "HealthCheckFailed" ||
+			evt.Reason == "DependencyNotReady"
+		out.PrintEvent(evt.Reason, t.label+output.Preview(evt.Note)+eventAge(evt), isWarning)
+		tracing.SpanFromContext(m.ctx).AddEvent(evt.Reason, "event.type", evt.Type, "event.message", evt.Note)
+		if isWarning {
+			m.recordWarning(fmt.Sprintf("%s: %s%s", evt.Reason, t.label, evt.Note))
+		}
+	}
+}
+
+// eventTime is when the event last occurred
+func eventTime(evt eventsv1.Event) time.Time {
+	switch {
+	case evt.Series != nil && !evt.Series.LastObservedTime.IsZero():
+		return evt.Series.LastObservedTime.Time
+	case !evt.DeprecatedLastTimestamp.IsZero():
+		return evt.DeprecatedLastTimestamp.Time
+	case !evt.EventTime.IsZero():
+		return evt.EventTime.Time
+	}
+	return evt.CreationTimestamp.Time
+}
+
+// eventAge describes the event's age and repetitions like kubectl describe:
+// " (12s ago)" or " (x5 over 2m)"
+func eventAge(evt eventsv1.Event) string {
+	count := evt.DeprecatedCount
+	if evt.Series != nil && evt.Series.Count > count {
+		count = evt.Series.Count
+	}
+	first := evt.DeprecatedFirstTimestamp.Time
+	if first.IsZero() {
+		first = evt.EventTime.Time
+	}
+	if count > 1 && !first.IsZero() {
+		return fmt.Sprintf(" (x%d over %s)", count, formatDuration(time.Since(first)))
+	}
+	if last := eventTime(evt); !last.IsZero() {
+		return fmt.Sprintf(" (%s ago)", formatDuration(time.Since(last)))
+	}
+	return ""
+}
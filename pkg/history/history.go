@@ -0,0 +1,179 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+)
+
+// maxSize bounds the store: once it grows past it, its older runs are dropped
+// down to half of it, so compactions are rare
+const maxSize = 4 << 20
+
+// Run is a recorded reconcile run
+type Run struct {
+	StartedAt time.Time `json:"startedAt"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Context   string    `json:"context,omitempty"`
+	// Revision is the revision the resource reconciled to
+	Revision string        `json:"revision,omitempty"`
+	Success  bool          `json:"success"`
+	Duration time.Duration `json:"duration"`
+	Message  string        `json:"message,omitempty"`
+	// Notified is set when the outcome was posted to the notification
+	// webhook
+	Notified bool `json:"notified,omitempty"`
+}
+
+// Filter selects runs; empty fields match everything
+type Filter struct {
+	Kind      string
+	Name      string
+	Namespace string
+	// Since drops runs started before it, unless zero
+	Since time.Time
+	// Limit is the maximum number of runs returned; 0 means all
+	Limit int
+}
+
+func (f Filter) matches(r Run) bool {
+	return (f.Kind == "" || f.Kind == r.Kind) &&
+		!r.StartedAt.Before(f.Since) &&
+		(f.Name == "" || f.Name == r.Name) &&
+		(f.Namespace == "" || f.Namespace == r.Namespace)
+}
+
+// DefaultPath returns the run history location under XDG_STATE_HOME (or
+// ~/.local/state when unset).
+func DefaultPath() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(os.TempDir(), "flux-enhanced-cli", "runs.jsonl")
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "flux-enhanced-cli", "runs.jsonl")
+}
+
+// FromResult builds the run record of a reconcile result
+func FromResult(result report.Result) Run {
+	return Run{
+		StartedAt: result.StartedAt,
+		Kind:      result.Kind,
+		Name:      result.Name,
+		Namespace: result.Namespace,
+		Context:   result.Context,
+		Revision:  result.Revision,
+		Success:   result.Success,
+		Duration:  result.Duration,
+		Message:   result.Message,
+	}
+}
+
+// Append adds runs to the store at path, one JSON object per line. Appends
+// are small single writes, so concurrent runs don't corrupt the file. A store
+// grown past maxSize is cut down to its newest runs.
+func Append(path string, runs ...Run) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	for _, r := range runs {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > maxSize {
+		return compact(path)
+	}
+	return nil
+}
+
+// compact rewrites the store at path with only its newest lines, about half
+// of maxSize of them. The
+// new store replaces the old one by a rename, so readers never see it half
+// written; a run appended concurrently with the rewrite may be lost.
+func compact(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	keep := len(data)
+	for keep > 0 && len(data)-keep < maxSize/2 {
+		keep = bytes.LastIndexByte(data[:keep-1], '\n') + 1
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".runs-*.jsonl")
+	if err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data[keep:]); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	return nil
+}
+
+// Load returns the runs in the store at path matching filter, newest first.
+// A missing store has no runs; unreadable lines are skipped.
+func Load(path string, filter Filter) ([]Run, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Run
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		if filter.matches(r) {
+			runs = append(runs, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+	if filter.Limit > 0 && len(runs) > filter.Limit {
+		runs = runs[:filter.Limit]
+	}
+	return runs, nil
+}
@@ -0,0 +1,100 @@
+package history
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ResourceStats aggregates the recorded runs of one resource
+type ResourceStats struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Context   string
+	Runs      int
+	Failures  int
+	// P50, P95 and Max are over the durations of successful runs
+	P50 time.Duration
+	P95 time.Duration
+	Max time.Duration
+	// Trend is the relative change of the median duration between the older
+	// and the newer half of the successful runs (0.2 is 20% slower); it is
+	// only set with at least 4 successful runs
+	Trend    float64
+	HasTrend bool
+}
+
+// FailureRate is the share of runs that failed
+func (s ResourceStats) FailureRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Runs)
+}
+
+// Summarize aggregates runs per resource, slowest (by p95) first. The
+// second result aggregates all runs together.
+func Summarize(runs []Run) ([]ResourceStats, ResourceStats) {
+	type key struct{ context, kind, namespace, name string }
+	grouped := map[key][]Run{}
+	var order []key
+	for _, r := range runs {
+		k := key{r.Context, r.Kind, r.Namespace, r.Name}
+		if _, ok := grouped[k]; !ok {
+			order = append(order, k)
+		}
+		grouped[k] = append(grouped[k], r)
+	}
+
+	stats := make([]ResourceStats, 0, len(order))
+	for _, k := range order {
+		s := summarize(grouped[k])
+		s.Context, s.Kind, s.Namespace, s.Name = k.context, k.kind, k.namespace, k.name
+		stats = append(stats, s)
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].P95 > stats[j].P95 })
+	return stats, summarize(runs)
+}
+
+func summarize(runs []Run) ResourceStats {
+	s := ResourceStats{Runs: len(runs)}
+	ordered := append([]Run(nil), runs...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].StartedAt.Before(ordered[j].StartedAt) })
+
+	var durations []time.Duration
+	for _, r := range ordered {
+		if !r.Success {
+			s.Failures++
+			continue
+		}
+		durations = append(durations, r.Duration)
+	}
+	if len(durations) == 0 {
+		return s
+	}
+
+	if len(durations) >= 4 {
+		older := percentile(durations[:len(durations)/2], 0.5)
+		newer := percentile(durations[len(durations)/2:], 0.5)
+		if older > 0 {
+			s.Trend = float64(newer-older) / float64(older)
+			s.HasTrend = true
+		}
+	}
+	s.P50 = percentile(durations, 0.5)
+	s.P95 = percentile(durations, 0.95)
+	s.Max = percentile(durations, 1)
+	return s
+}
+
+// percentile returns the nearest-rank percentile p (0-1] of durations
+func percentile(durations []time.Duration, p float64) time.Duration {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
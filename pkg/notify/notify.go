@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/history"
+)
+
+// Notifier sends a webhook notification only when a resource's outcome
+// changes, so repeated runs (cron jobs, CI schedules) don't spam a channel.
+// The previous outcomes are read from the run history.
+type Notifier struct {
+	URL string
+	// FailureThreshold is the number of consecutive failures required
+	// before a failure is alerted. Values below 1 are treated as 1.
+	FailureThreshold int
+}
+
+// Notify posts the outcome of run when it warrants a notification, given the
+// previous runs of the resource, newest first. run.Notified is only set once
+// the post succeeded, so a failed post is retried by the next run. It returns
+// whether a notification was sent.
+func (n *Notifier) Notify(ctx context.Context, key string, previous []history.Run, run *history.Run) (bool, error) {
+	send, failures := Decide(previous, run.Success, n.FailureThreshold)
+	if !send || n.URL == "" {
+		return false, nil
+	}
+	if err := n.post(ctx, key, run.Success, failures, run.Message); err != nil {
+		return false, err
+	}
+	run.Notified = true
+	return true, nil
+}
+
+// Decide tells whether the outcome of a run should be alerted, given the
+// previous runs of the resource, newest first, and returns the length of the
+// failure streak the run ends or extends. A failure is alerted once the
+// streak reaches threshold unless a run of the streak was already alerted; a
+// success only when it ends an alerted streak.
+func Decide(previous []history.Run, success bool, threshold int) (bool, int) {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	failures := 0
+	alerted := false
+	for _, r := range previous {
+		if r.Success {
+			break
+		}
+		failures++
+		alerted = alerted || r.Notified
+	}
+	if success {
+		return alerted, failures
+	}
+	failures++
+	return !alerted && failures >= threshold, failures
+}
+
+func (n *Notifier) post(ctx context.Context, key string, success bool, failures int, message string) error {
+	outcome := "success"
+	text := fmt.Sprintf("✅ %s recovered: reconciliation succeeded", key)
+	if !success {
+		outcome = "failure"
+		text = fmt.Sprintf("❌ %s failed (%d consecutive failures)", key, failures)
+	}
+	if message != "" {
+		text += ": " + message
+	}
+
+	// "text" keeps the payload compatible with Slack/Mattermost incoming webhooks
+	payload, err := json.Marshal(map[string]interface{}{
+		"text":                text,
+		"resource":            key,
+		"outcome":             outcome,
+		"consecutiveFailures": failures,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package output
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// yamlKeyLine splits a YAML line into indentation (with any list dash), key
+// and the rest
+var yamlKeyLine = regexp.MustCompile(`^(\s*(?:- )*)([^\s#'"][^:#]*?|"[^"]*"|'[^']*'):(\s.*|)$`)
+
+// StdoutIsTerminal reports whether stdout is a terminal and colors are
+// enabled, e.g. to highlight results that would otherwise be piped on
+func StdoutIsTerminal() bool {
+	if colorsDisabled {
+		return false
+	}
+	fileInfo, err := os.Stdout.Stat()
+	return err == nil && (fileInfo.Mode()&os.ModeCharDevice) != 0
+}
+
+// WriteYAML writes YAML to w, with keys, values, comments and document
+// separators in color when highlight is set
+func WriteYAML(w io.Writer, yaml string, highlight bool) error {
+	if !highlight {
+		_, err := io.WriteString(w, yaml)
+		return err
+	}
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(yaml, "\n") {
+		b.WriteString(highlightYAMLLine(line))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func highlightYAMLLine(line string) string {
+	text := strings.TrimRight(line, "\n")
+	newline := line[len(text):]
+	trimmed := strings.TrimSpace(text)
+	switch {
+	case trimmed == "":
+		return line
+	case trimmed == "---":
+		return ColorBold + text + ColorReset + newline
+	case strings.HasPrefix(trimmed, "#"):
+		return ColorSubLog + text + ColorReset + newline
+	}
+	if m := yamlKeyLine.FindStringSubmatch(text); m != nil {
+		return m[1] + ColorCyan + m[2] + ColorReset + ":" + yamlValue(m[3]) + newline
+	}
+	if indent, item, ok := strings.Cut(text, "- "); ok && strings.TrimSpace(indent) == "" {
+		return indent + "- " + yamlValue(item) + newline
+	}
+	return yamlValue(text) + newline
+}
+
+// yamlValue colors a scalar: strings green, numbers, booleans and null
+// magenta; block scalar indicators and nested content are left as they are
+func yamlValue(s string) string {
+	value := strings.TrimSpace(s)
+	switch {
+	case value == "", value == "|", value == ">", strings.HasPrefix(value, "|-"), strings.HasPrefix(value, ">-"):
+		return s
+	case value == "true", value == "false", value == "null", value == "~", isNumber(value):
+		return strings.Replace(s, value, ColorMagenta+value+ColorReset, 1)
+	default:
+		return strings.Replace(s, value, ColorGreen+value+ColorReset, 1)
+	}
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	dot := false
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+		case r == '-' && i == 0 && len(s) > 1:
+		case r == '.' && !dot:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WriteDiff writes the output of a diff to w, with additions green, removals
+// red and the "►" object headers in color when highlight is set
+func WriteDiff(w io.Writer, diff string, highlight bool) error {
+	if !highlight {
+		_, err := io.WriteString(w, diff)
+		return err
+	}
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		trimmed := strings.TrimSpace(line)
+		color := ""
+		switch {
+		case strings.HasPrefix(trimmed, "►"):
+			color = ColorBold
+		case strings.HasPrefix(trimmed, "+"), strings.HasPrefix(trimmed, "✚"):
+			color = ColorGreen
+		case strings.HasPrefix(trimmed, "-"), strings.HasPrefix(trimmed, "✗"):
+			color = ColorRed
+		case strings.HasPrefix(trimmed, "±"):
+			color = ColorYellow
+		}
+		if color == "" {
+			b.WriteString(line)
+			continue
+		}
+		text := strings.TrimRight(line, "\n")
+		b.WriteString(color + text + ColorReset + line[len(text):])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
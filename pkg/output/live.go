@@ -0,0 +1,46 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Interactive reports whether the log output is a terminal on which a
+// LiveTable can redraw itself
+func Interactive() bool {
+	fileInfo, err := logOut.Stat()
+	return err == nil && (fileInfo.Mode()&os.ModeCharDevice) != 0
+}
+
+// LiveTable is a table redrawn in place on an interactive terminal, e.g. for
+// watching resources change
+type LiveTable struct {
+	// lines is how many lines the last draw wrote
+	lines int
+}
+
+// Draw replaces the previously drawn table with rows. Rows that don't fit
+// the terminal height are left out and counted in a last line.
+func (t *LiveTable) Draw(headers []string, rows [][]string) {
+	hidden := 0
+	if _, height, err := term.GetSize(int(logOut.Fd())); err == nil && height > 2 && len(rows) > height-2 {
+		hidden = len(rows) - (height - 2)
+		rows = rows[:height-2]
+	}
+	table := renderTable(headers, rows, terminalWidth())
+	if hidden > 0 {
+		table += fmt.Sprintf("… %d more\n", hidden)
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	if t.lines > 0 {
+		// Move to the start of the previous table and clear everything below
+		fmt.Fprintf(logOut, "\033[%dA\r\033[J", t.lines)
+	}
+	fmt.Fprint(logOut, table)
+	t.lines = strings.Count(table, "\n")
+}
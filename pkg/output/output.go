@@ -1,9 +1,11 @@
 package output
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"text/tabwriter"
 )
 
 // Color codes
@@ -21,6 +23,14 @@ var (
 
 var colorsDisabled = false
 
+// CI modes that change how warnings, errors and sections are emitted
+const (
+	CIModeNone   = ""
+	CIModeGitHub = "github"
+)
+
+var ciMode = CIModeNone
+
 // DisableColors turns off colored output
 func DisableColors() {
 	colorsDisabled = true
@@ -35,91 +45,310 @@ func DisableColors() {
 	ColorSubLog = ""
 }
 
+// SetCIMode switches output to the workflow command syntax of a CI system.
+// In "github" mode errors and warnings become annotations and sections are
+// wrapped in collapsible groups.
+func SetCIMode(mode string) error {
+	switch mode {
+	case CIModeNone, CIModeGitHub:
+		ciMode = mode
+		return nil
+	default:
+		return fmt.Errorf("unsupported CI mode '%s'. Valid modes: github", mode)
+	}
+}
+
+type printerKey struct{}
+
+// WithPrinter returns a context whose output goes through p
+func WithPrinter(ctx context.Context, p *Printer) context.Context {
+	return context.WithValue(ctx, printerKey{}, p)
+}
+
+// FromContext returns the printer stored in ctx, or the default printer
+func FromContext(ctx context.Context) *Printer {
+	if p, ok := ctx.Value(printerKey{}).(*Printer); ok && p != nil {
+		return p
+	}
+	return std
+}
+
+// StartGroup opens a collapsible log section in CI modes that support it
+func StartGroup(title string) { std.StartGroup(title) }
+
+// EndGroup closes the section opened by StartGroup
+func EndGroup() { std.EndGroup() }
+
+// escapeWorkflowData escapes a GitHub workflow command message
+func escapeWorkflowData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeWorkflowProperty escapes a GitHub workflow command property value
+func escapeWorkflowProperty(s string) string {
+	s = escapeWorkflowData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
+
 func isTerminal() bool {
 	if colorsDisabled {
 		return false
 	}
-	fileInfo, _ := os.Stdout.Stat()
+	fileInfo, _ := logOut.Stat()
 	return (fileInfo.Mode() & os.ModeCharDevice) != 0
 }
 
-func PrintCommand(args ...string) {
+// PrintProgress shows a progress line that is overwritten in place. It is
+// only shown when stderr is an interactive terminal, and is cut to the
+// terminal width (re-rendered on resize) so it never wraps.
+func PrintProgress(message string) {
+	if !stderrIsTerminal() {
+		return
+	}
+	watchResize()
+	widthMu.Lock()
+	lastProgress = message
+	width := stderrWidth
+	widthMu.Unlock()
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	writeProgress(fitWidth(redact(message), width-1), false)
+}
+
+// ClearProgress removes the line written by PrintProgress
+func ClearProgress() {
+	if !stderrIsTerminal() {
+		return
+	}
+	widthMu.Lock()
+	lastProgress = ""
+	widthMu.Unlock()
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	writeProgress("", true)
+}
+
+// writeProgress rewrites the progress line; clearBelow also erases any
+// rows a previously wrapped line spilled into. Callers hold writeMu.
+func writeProgress(message string, clearBelow bool) {
+	if clearBelow {
+		fmt.Fprintf(os.Stderr, "\r\033[J%s", message)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", message)
+}
+
+func stderrIsTerminal() bool {
+	fileInfo, err := os.Stderr.Stat()
+	return err == nil && (fileInfo.Mode()&os.ModeCharDevice) != 0
+}
+
+func PrintCommand(args ...string) { std.PrintCommand(args...) }
+
+func PrintSublog(message string) { std.PrintSublog(message) }
+
+func PrintWaiting(kind, name string) { std.PrintWaiting(kind, name) }
+
+func PrintSuccess(kind, name string) { std.PrintSuccess(kind, name) }
+
+func PrintError(message string) { std.PrintError(message) }
+
+func PrintEvent(reason, message string, isWarning bool) { std.PrintEvent(reason, message, isWarning) }
+
+func PrintMain(emoji, message string, color string) { std.PrintMain(emoji, message, color) }
+
+func PrintWarning(message string) { std.PrintWarning(message) }
+
+func PrintStatus(message string) { std.PrintStatus(message) }
+
+// PrintTable prints rows aligned in columns under a header row
+func PrintTable(headers []string, rows [][]string) { std.PrintTable(headers, rows) }
+
+func (p *Printer) StartGroup(title string) {
+	// Groups cannot interleave, so prefixed (concurrent) printers skip them
+	if ciMode == CIModeGitHub && p.prefix == "" {
+		p.raw(fmt.Sprintf("::group::%s\n", escapeWorkflowData(title)))
+	}
+}
+
+func (p *Printer) EndGroup() {
+	if ciMode == CIModeGitHub && p.prefix == "" {
+		p.raw("::endgroup::\n")
+	}
+}
+
+func (p *Printer) PrintCommand(args ...string) {
 	if !isTerminal() {
-		fmt.Printf("│ %s\n", strings.Join(args, " "))
+		p.printf("│ %s\n", strings.Join(args, " "))
 		return
 	}
-	fmt.Printf("%s│ %s%s\n", ColorSubLog, strings.Join(args, " "), ColorReset)
+	p.printf("%s│ %s%s\n", ColorSubLog, strings.Join(args, " "), ColorReset)
 }
 
-func PrintSublog(message string) {
+func (p *Printer) PrintSublog(message string) {
 	if !isTerminal() {
-		fmt.Printf("│ %s\n", message)
+		p.printf("│ %s\n", message)
 		return
 	}
-	fmt.Printf("%s│ %s%s\n", ColorSubLog, message, ColorReset)
+	p.printf("%s│ %s%s\n", ColorSubLog, message, ColorReset)
 }
 
-func PrintWaiting(kind, name string) {
+func (p *Printer) PrintWaiting(kind, name string) {
 	if !isTerminal() {
-		fmt.Printf("⏳ Waiting for %s reconciliation...\n", kind)
+		p.printf("⏳ Waiting for %s reconciliation...\n", kind)
 		return
 	}
-	fmt.Printf("%s│ ⏳ Waiting for %s reconciliation...%s\n", ColorSubLog, kind, ColorReset)
+	p.printf("%s│ ⏳ Waiting for %s reconciliation...%s\n", ColorSubLog, kind, ColorReset)
 }
 
-func PrintSuccess(kind, name string) {
+func (p *Printer) PrintSuccess(kind, name string) {
 	if !isTerminal() {
-		fmt.Printf("✅ %s reconciliation completed successfully\n", kind)
+		p.printf("✅ %s reconciliation completed successfully\n", kind)
 		return
 	}
-	fmt.Printf("%s│ ✅ %s reconciliation completed successfully%s\n", ColorSubLog, kind, ColorReset)
+	p.printf("%s│ ✅ %s reconciliation completed successfully%s\n", ColorSubLog, kind, ColorReset)
 }
 
-func PrintError(message string) {
+func (p *Printer) PrintError(message string) {
+	if ciMode == CIModeGitHub {
+		p.raw(fmt.Sprintf("::error::%s\n", escapeWorkflowData(p.label()+message)))
+		return
+	}
 	if !isTerminal() {
-		fmt.Printf("❌ %s\n", message)
+		p.printf("❌ %s\n", message)
 		return
 	}
-	fmt.Printf("%s│ %s❌ %s%s\n", ColorSubLog, ColorRed, message, ColorReset)
+	p.printf("%s│ %s❌ %s%s\n", ColorSubLog, ColorRed, message, ColorReset)
 }
 
-func PrintEvent(reason, message string, isWarning bool) {
+func (p *Printer) PrintEvent(reason, message string, isWarning bool) {
+	if ciMode == CIModeGitHub && isWarning {
+		p.raw(fmt.Sprintf("::warning title=%s::%s\n", escapeWorkflowProperty(p.label()+reason), escapeWorkflowData(message)))
+		return
+	}
 	if !isTerminal() {
 		if isWarning {
-			fmt.Printf("│ ⚠️  [%s] %s\n", reason, message)
+			p.printf("│ ⚠️  [%s] %s\n", reason, message)
 		} else {
-			fmt.Printf("│ ℹ️  [%s] %s\n", reason, message)
+			p.printf("│ ℹ️  [%s] %s\n", reason, message)
 		}
 		return
 	}
 
 	if isWarning || reason == "HealthCheckFailed" || reason == "DependencyNotReady" {
-		fmt.Printf("%s│ %s⚠️  [%s] %s%s\n", ColorSubLog, ColorYellow, reason, message, ColorReset)
+		p.printf("%s│ %s⚠️  [%s] %s%s\n", ColorSubLog, ColorYellow, reason, message, ColorReset)
 	} else {
-		fmt.Printf("%s│ ℹ️  [%s] %s\n", ColorSubLog, reason, message)
+		p.printf("%s│ ℹ️  [%s] %s\n", ColorSubLog, reason, message)
+	}
+}
+
+func (p *Printer) PrintMain(emoji, message string, color string) {
+	if !isTerminal() {
+		p.printf("%s %s\n", emoji, message)
+		return
+	}
+	p.printf("%s%s%s %s%s\n", color, emoji, ColorReset, message, ColorReset)
+}
+
+func (p *Printer) PrintWarning(message string) {
+	if ciMode == CIModeGitHub {
+		p.raw(fmt.Sprintf("::warning::%s\n", escapeWorkflowData(p.label()+message)))
+		return
 	}
+	if !isTerminal() {
+		p.printf("│ ⚠️  %s\n", message)
+		return
+	}
+	p.printf("%s│ %s⚠️  %s%s\n", ColorSubLog, ColorYellow, message, ColorReset)
 }
 
-func PrintMain(emoji, message string, color string) {
+func (p *Printer) PrintStatus(message string) {
 	if !isTerminal() {
-		fmt.Printf("%s %s\n", emoji, message)
+		p.printf("│ ℹ️  %s\n", message)
 		return
 	}
-	fmt.Printf("%s%s%s %s%s\n", color, emoji, ColorReset, message, ColorReset)
+	p.printf("%s│ ℹ️  %s%s\n", ColorSubLog, message, ColorReset)
 }
 
-func PrintWarning(message string) {
+// PrintChange prints a change between two checks, "subject: from → to", with
+// the new value in color
+func (p *Printer) PrintChange(subject, from, to, color string) {
 	if !isTerminal() {
-		fmt.Printf("│ ⚠️  %s\n", message)
+		p.printf("│ 🔄 %s: %s → %s\n", subject, from, to)
 		return
 	}
-	fmt.Printf("%s│ %s⚠️  %s%s\n", ColorSubLog, ColorYellow, message, ColorReset)
+	p.printf("%s│ 🔄 %s: %s → %s%s%s\n", ColorSubLog, subject, from, color, to, ColorReset)
 }
 
-func PrintStatus(message string) {
+// PrintLogLine prints a log line of another component, "[source] message",
+// with the source in color so interleaved logs stay apart
+func (p *Printer) PrintLogLine(source, color, message string, isError bool) {
 	if !isTerminal() {
-		fmt.Printf("│ ℹ️  %s\n", message)
+		p.printf("│ 📜 [%s] %s\n", source, message)
 		return
 	}
-	fmt.Printf("%s│ ℹ️  %s%s\n", ColorSubLog, message, ColorReset)
+	if isError {
+		p.printf("%s│ 📜 %s[%s]%s %s%s%s\n", ColorSubLog, color, source, ColorReset, ColorRed, message, ColorReset)
+		return
+	}
+	p.printf("%s│ 📜 %s[%s]%s%s %s%s\n", ColorSubLog, color, source, ColorReset, ColorSubLog, message, ColorReset)
+}
+
+// fitRows shortens the last column on interactive terminals so rows don't
+// wrap at the current terminal width.
+func fitRows(headers []string, rows [][]string, width int) [][]string {
+	if !isTerminal() || width <= 0 || len(headers) == 0 {
+		return rows
+	}
+	last := len(headers) - 1
+	used := 0
+	for col := 0; col < last; col++ {
+		colWidth := len([]rune(headers[col]))
+		for _, row := range rows {
+			if col < len(row) && len([]rune(row[col])) > colWidth {
+				colWidth = len([]rune(row[col]))
+			}
+		}
+		used += colWidth + 3 // tabwriter padding
+	}
+	available := width - used
+	if available < 10 {
+		return rows
+	}
+
+	fitted := make([][]string, len(rows))
+	for i, row := range rows {
+		fitted[i] = row
+		if len(row) > last {
+			fitted[i] = append(append([]string(nil), row[:last]...), fitWidth(row[last], available))
+		}
+	}
+	return fitted
+}
+
+func (p *Printer) PrintTable(headers []string, rows [][]string) {
+	p.printf("%s", renderTable(headers, rows, terminalWidth()-len(p.label())))
+}
+
+// renderTable aligns rows in columns under a bold header row, fitting them to
+// width on interactive terminals
+func renderTable(headers []string, rows [][]string, width int) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 3, ' ', 0)
+	if isTerminal() {
+		fmt.Fprintf(w, "%s%s%s\n", ColorBold, strings.Join(headers, "\t"), ColorReset)
+	} else {
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+	}
+	for _, row := range fitRows(headers, rows, width) {
+		// Redact cells before aligning, since tokens differ in length
+		fmt.Fprintln(w, redact(strings.Join(row, "\t")))
+	}
+	w.Flush()
+	return b.String()
 }
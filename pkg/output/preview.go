@@ -0,0 +1,42 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Long messages (e.g. kustomize build errors in conditions) are cut to a
+// preview unless expanded
+const (
+	previewLines = 5
+	previewChars = 400
+)
+
+var expandErrors = false
+
+// ExpandErrors prints long condition and event messages in full
+func ExpandErrors() {
+	expandErrors = true
+}
+
+// Preview shortens a long message to a few lines, noting how much was left
+// out. Messages are returned unchanged when ExpandErrors is set.
+func Preview(message string) string {
+	if expandErrors {
+		return message
+	}
+
+	preview := message
+	if lines := strings.SplitN(preview, "\n", previewLines+1); len(lines) > previewLines {
+		preview = strings.Join(lines[:previewLines], "\n")
+	}
+	if runes := []rune(preview); len(runes) > previewChars {
+		preview = string(runes[:previewChars])
+	}
+	if len(preview) == len(message) {
+		return message
+	}
+	left := utf8.RuneCountInString(message) - utf8.RuneCountInString(preview)
+	return fmt.Sprintf("%s … [%d more characters, use --expand-errors to show all]", preview, left)
+}
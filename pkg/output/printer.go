@@ -0,0 +1,144 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// writeMu serializes writes so lines from concurrent printers never interleave
+var writeMu sync.Mutex
+
+// logOut receives all log output; stdout unless LogToStderr was called
+var logOut = os.Stdout
+
+// LogToStderr sends log output to stderr, keeping stdout free for results
+// printed by the caller (e.g. with --output)
+func LogToStderr() {
+	logOut = os.Stderr
+}
+
+// Println writes an empty line to the log output
+func Println() {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	fmt.Fprintln(logOut)
+}
+
+// Printer writes formatted output, optionally prefixing every line (e.g. with
+// the cluster name when several clusters are reconciled concurrently).
+type Printer struct {
+	prefix string
+	color  string
+}
+
+var std = &Printer{}
+
+// prefixColors are assigned round-robin to prefixed printers
+var prefixColors = []*string{&ColorCyan, &ColorMagenta, &ColorBlue, &ColorYellow, &ColorGreen}
+
+var prefixCount int
+
+// NewPrinter returns a printer that prefixes every line with "[prefix] "
+func NewPrinter(prefix string) *Printer {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	color := *prefixColors[prefixCount%len(prefixColors)]
+	prefixCount++
+	return &Printer{prefix: prefix, color: color}
+}
+
+// label is the prefix as plain text, for messages that can't carry colors
+func (p *Printer) label() string {
+	if p.prefix == "" {
+		return ""
+	}
+	return "[" + p.prefix + "] "
+}
+
+func (p *Printer) linePrefix() string {
+	if p.prefix == "" {
+		return ""
+	}
+	if isTerminal() {
+		return p.color + "[" + p.prefix + "]" + ColorReset + " "
+	}
+	return p.label()
+}
+
+// printf formats a message and writes it with every line prefixed
+func (p *Printer) printf(format string, args ...interface{}) {
+	p.write(logOut, fmt.Sprintf(format, args...))
+}
+
+// raw writes s without any line prefix (used for CI workflow commands)
+func (p *Printer) raw(s string) {
+	s = redact(s)
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	fmt.Fprint(logOut, s)
+}
+
+// write prefixes every line of s and redacts the result, so a context named
+// in the prefix is redacted as well
+func (p *Printer) write(w io.Writer, s string) {
+	if prefix := p.linePrefix(); prefix != "" {
+		lines := strings.SplitAfter(s, "\n")
+		var b strings.Builder
+		for _, line := range lines {
+			if line == "" {
+				continue
+			}
+			b.WriteString(prefix)
+			b.WriteString(line)
+		}
+		s = b.String()
+	}
+	s = redact(s)
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	fmt.Fprint(w, s)
+}
+
+// Stdout returns a writer for passing through a child process' stdout. Its
+// lines are prefixed and redacted like all other output.
+func (p *Printer) Stdout() io.Writer {
+	if p.prefix == "" && !Redacting() {
+		return logOut
+	}
+	return &lineWriter{printer: p, out: logOut}
+}
+
+// Stderr returns a writer for passing through a child process' stderr
+func (p *Printer) Stderr() io.Writer {
+	if p.prefix == "" && !Redacting() {
+		return os.Stderr
+	}
+	return &lineWriter{printer: p, out: os.Stderr}
+}
+
+// lineWriter buffers partial lines so each complete line is prefixed once
+type lineWriter struct {
+	printer *Printer
+	out     io.Writer
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (w *lineWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(data)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(data), nil
+		}
+		line := string(w.buf.Next(i + 1))
+		w.printer.write(w.out, line)
+	}
+}
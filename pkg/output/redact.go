@@ -0,0 +1,85 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sync"
+)
+
+// Redaction replaces resource names, namespaces and URLs in all output with
+// stable hashed tokens, e.g. for screenshots of real incidents. Names must be
+// registered; URLs are detected.
+var (
+	redactMu      sync.RWMutex
+	redactEnabled bool
+	redactTokens  = map[string]string{}
+)
+
+var (
+	// wordRegex matches name-like tokens (DNS labels, dotted names)
+	wordRegex = regexp.MustCompile(`[A-Za-z0-9](?:[A-Za-z0-9_.-]*[A-Za-z0-9])?`)
+	urlRegex  = regexp.MustCompile(`(?:[a-z][a-z0-9+.-]*://|git@)[^\s'"<>]*[^\s'"<>.,;:)]`)
+)
+
+// EnableRedaction turns on redaction of registered names and all URLs
+func EnableRedaction() {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	redactEnabled = true
+}
+
+// Redacting reports whether redaction is enabled
+func Redacting() bool {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	return redactEnabled
+}
+
+// RedactNames registers resource (or context) names to be redacted
+func RedactNames(names ...string) { register("name", names) }
+
+// RedactNamespaces registers namespaces to be redacted
+func RedactNamespaces(namespaces ...string) { register("ns", namespaces) }
+
+func register(prefix string, values []string) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	if !redactEnabled {
+		return
+	}
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		if _, ok := redactTokens[v]; !ok {
+			redactTokens[v] = prefix + "-" + shortHash(v)
+		}
+	}
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:3])
+}
+
+// redact applies redaction to s; it is a no-op unless enabled
+func redact(s string) string {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	if !redactEnabled {
+		return s
+	}
+	s = urlRegex.ReplaceAllStringFunc(s, func(url string) string {
+		return "url-" + shortHash(url)
+	})
+	if len(redactTokens) == 0 {
+		return s
+	}
+	return wordRegex.ReplaceAllStringFunc(s, func(word string) string {
+		if token, ok := redactTokens[word]; ok {
+			return token
+		}
+		return word
+	})
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package output
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize calls fn whenever the terminal window is resized
+func notifyResize(fn func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	go func() {
+		for range ch {
+			fn()
+		}
+	}()
+}
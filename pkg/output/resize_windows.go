@@ -0,0 +1,7 @@
+//go:build windows
+
+package output
+
+// notifyResize is a no-op on Windows, which has no SIGWINCH; widths are
+// read once at startup.
+func notifyResize(fn func()) {}
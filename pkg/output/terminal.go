@@ -0,0 +1,80 @@
+package output
+
+import (
+	"os"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+var (
+	resizeOnce sync.Once
+	// widths caches the column count of the log output and stderr; refreshed
+	// on resize
+	widthMu      sync.Mutex
+	stdoutWidth  int
+	stderrWidth  int
+	lastProgress string
+)
+
+// watchResize starts tracking terminal size changes (once). On resize the
+// cached widths are refreshed and the progress line is redrawn for the new
+// width, so it never wraps and tears.
+func watchResize() {
+	resizeOnce.Do(func() {
+		refreshWidths()
+		notifyResize(func() {
+			refreshWidths()
+			redrawProgress()
+		})
+	})
+}
+
+func refreshWidths() {
+	widthMu.Lock()
+	defer widthMu.Unlock()
+	stdoutWidth = fdWidth(logOut)
+	stderrWidth = fdWidth(os.Stderr)
+}
+
+func fdWidth(f *os.File) int {
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// terminalWidth returns the column count of the log output, or 0 when unknown
+func terminalWidth() int {
+	watchResize()
+	widthMu.Lock()
+	defer widthMu.Unlock()
+	return stdoutWidth
+}
+
+// fitWidth cuts s to at most width columns (runes), marking the cut with "…"
+func fitWidth(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func redrawProgress() {
+	widthMu.Lock()
+	message, width := lastProgress, stderrWidth
+	widthMu.Unlock()
+	if message == "" {
+		return
+	}
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	// After shrinking, the old line may have wrapped; clear everything below
+	// the cursor's line start as well
+	writeProgress(fitWidth(redact(message), width-1), true)
+}
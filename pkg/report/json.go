@@ -0,0 +1,25 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// WriteJSON writes the results as a JSON array, one object per reconciled
+// resource in the schema of -o json, for later CI steps to consume
+func WriteJSON(path string, results []Result) error {
+	if results == nil {
+		results = []Result{}
+	}
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write the result file: %w", err)
+	}
+	return nil
+}
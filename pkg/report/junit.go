@@ -0,0 +1,118 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as a JUnit XML report, one test case per
+// reconciled resource, so CI systems can show them in their test views.
+func WriteJUnit(path, suiteName string, results []Result) error {
+	suite := junitTestSuite{Name: suiteName, Tests: len(results)}
+
+	var total float64
+	for _, r := range results {
+		className := fmt.Sprintf("%s.%s", r.Kind, r.Namespace)
+		if r.Context != "" {
+			className = r.Context + "." + className
+		}
+		tc := junitTestCase{
+			ClassName: className,
+			Name:      r.Name,
+			Time:      fmt.Sprintf("%.3f", r.Duration.Seconds()),
+		}
+		total += r.Duration.Seconds()
+
+		if r.Skipped {
+			suite.Skipped++
+			tc.Skipped = &junitSkipped{Message: r.Message}
+		} else if !r.Success {
+			suite.Failures++
+			tc.Failure = &junitFailure{
+				Message: r.Message,
+				Type:    "ReconciliationFailed",
+				Body:    failureDetails(r),
+			}
+		}
+		if r.Flapping && tc.Failure == nil {
+			tc.SystemOut = "Flapping readiness:\n" + timeline(r)
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total)
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+func failureDetails(r Result) string {
+	var b strings.Builder
+	b.WriteString(r.Message)
+	b.WriteString("\n")
+	if r.Conditions != "" {
+		fmt.Fprintf(&b, "\nConditions: %s\n", r.Conditions)
+	}
+	if len(r.WarningEvents) > 0 {
+		b.WriteString("\nWarning events:\n")
+		for _, evt := range r.WarningEvents {
+			fmt.Fprintf(&b, "  - %s\n", evt)
+		}
+	}
+	if len(r.Transitions) > 0 {
+		b.WriteString("\nReadiness transitions:\n")
+		b.WriteString(timeline(r))
+	}
+	return b.String()
+}
+
+func timeline(r Result) string {
+	var b strings.Builder
+	for _, t := range r.Transitions {
+		fmt.Fprintf(&b, "  - %s\n", t)
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package report
+
+import (
+	"time"
+)
+
+// Result describes the outcome of reconciling a single resource
+type Result struct {
+	Kind      string        `json:"kind"`
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace"`
+	Context   string        `json:"context,omitempty"`
+	Success   bool          `json:"success"`
+	Skipped   bool          `json:"skipped,omitempty"`
+	ExitCode  int           `json:"exitCode"`
+	Duration  time.Duration `json:"duration"`
+	// StartedAt is when reconciling the resource started
+	StartedAt time.Time `json:"startedAt"`
+	// TriggeredAt is when the reconcile request was made
+	TriggeredAt time.Time `json:"triggeredAt,omitempty"`
+	// AcknowledgedAfter is how long after the trigger the controller was seen
+	// handling the request, when known
+	AcknowledgedAfter time.Duration `json:"acknowledgedAfter,omitempty"`
+	// ReadyAfter is how long after the trigger the resource became ready
+	ReadyAfter time.Duration `json:"readyAfter,omitempty"`
+	// Message is a one-line failure (or skip) summary
+	Message string `json:"message,omitempty"`
+	// Revision is the revision the resource reconciled to, when known
+	Revision string `json:"revision,omitempty"`
+	// Conditions is the last observed condition summary of the resource
+	Conditions string `json:"conditions,omitempty"`
+	// WarningEvents lists the warning events observed during the run
+	WarningEvents []string `json:"warningEvents,omitempty"`
+	// Flapping is set when the resource turned not ready after being ready,
+	// and then ready again, during the run
+	Flapping bool `json:"flapping,omitempty"`
+	// Transitions is the timeline of readiness changes observed during the run
+	Transitions []string `json:"transitions,omitempty"`
+}
@@ -0,0 +1,147 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed five-field cron expression (minute hour day-of-month month
+// day-of-week), evaluated in the local time zone
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record a "*" day field; as in cron, when both day
+	// fields are restricted a time matching either one is due
+	domAny, dowAny bool
+}
+
+// macros are the supported @-shorthands
+var macros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Parse parses a cron expression such as "0 2 * * 1-5" or "@daily". Fields
+// accept "*", numbers, ranges ("1-5"), lists ("1,15") and steps ("*/10").
+func Parse(expr string) (*Cron, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := macros[spec]; ok {
+		spec = macro
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid cron expression '%s': expected 5 fields", expr)
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		b, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression '%s': %w", expr, err)
+		}
+		bits[i] = b
+	}
+	// Sunday is both 0 and 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &Cron{
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domAny: parts[2] == "*", dowAny: parts[4] == "*",
+	}, nil
+}
+
+func parseField(spec string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(spec, ",") {
+		rangeSpec, stepSpec, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepSpec)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step '%s' in %s", stepSpec, f.name)
+			}
+			step = n
+		}
+
+		low, high := f.min, f.max
+		if rangeSpec != "*" {
+			lowSpec, highSpec, isRange := strings.Cut(rangeSpec, "-")
+			var err error
+			if low, err = strconv.Atoi(lowSpec); err != nil {
+				return 0, fmt.Errorf("invalid %s '%s'", f.name, item)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highSpec); err != nil {
+					return 0, fmt.Errorf("invalid %s '%s'", f.name, item)
+				}
+			} else if hasStep {
+				// "5/15" means every 15 starting at 5
+				high = f.max
+			}
+		}
+		if low < f.min || high > f.max || low > high {
+			return 0, fmt.Errorf("%s '%s' out of range %d-%d", f.name, item, f.min, f.max)
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first time after t the schedule is due, or the zero time
+// when it never is (e.g. "0 0 31 2 *")
+func (c *Cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Any valid schedule fires within 4 years (leap days)
+	limit := t.AddDate(4, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,201 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// otlpExporter sends spans to an OTLP/HTTP endpoint using the JSON encoding
+type otlpExporter struct {
+	endpoint string
+	headers  map[string]string
+	resource map[string]string
+	version  string
+	client   *http.Client
+}
+
+// newExporterFromEnv builds an exporter from the standard OTEL_EXPORTER_OTLP_*
+// variables. It returns nil when no endpoint is configured.
+func newExporterFromEnv(serviceName, version string) (*otlpExporter, error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if base == "" {
+			return nil, nil
+		}
+		endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	if protocol != "" && protocol != "http/json" {
+		return nil, fmt.Errorf("unsupported OTLP protocol '%s'. Only http/json is supported", protocol)
+	}
+
+	headers := parseKeyValues(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	for k, v := range parseKeyValues(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS")) {
+		headers[k] = v
+	}
+
+	resource := parseKeyValues(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"))
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		resource["service.name"] = name
+	} else if _, ok := resource["service.name"]; !ok {
+		resource["service.name"] = serviceName
+	}
+	resource["service.version"] = version
+
+	timeout := 10 * time.Second
+	if ms, err := strconv.Atoi(os.Getenv("OTEL_EXPORTER_OTLP_TIMEOUT")); err == nil && ms > 0 {
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	return &otlpExporter{
+		endpoint: endpoint,
+		headers:  headers,
+		resource: resource,
+		version:  version,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// parseKeyValues parses the "k1=v1,k2=v2" format used by OTEL_* variables
+func parseKeyValues(value string) map[string]string {
+	result := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if decoded, err := url.QueryUnescape(strings.TrimSpace(v)); err == nil {
+			v = decoded
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
+
+type otlpKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	} `json:"status"`
+}
+
+func toKeyValues(attrs map[string]string) []otlpKeyValue {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		kv := otlpKeyValue{Key: k}
+		kv.Value.StringValue = attrs[k]
+		kvs = append(kvs, kv)
+	}
+	return kvs
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func (e *otlpExporter) export(ctx context.Context, spans []*Span) error {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		span := otlpSpan{
+			TraceID:           s.traceID,
+			SpanID:            s.spanID,
+			ParentSpanID:      s.parentID,
+			Name:              s.name,
+			Kind:              1, // SPAN_KIND_INTERNAL
+			StartTimeUnixNano: unixNano(s.start),
+			EndTimeUnixNano:   unixNano(s.end),
+			Attributes:        toKeyValues(s.attributes),
+		}
+		span.Status.Code = s.statusCode
+		span.Status.Message = s.statusMsg
+		for _, evt := range s.events {
+			span.Events = append(span.Events, otlpEvent{
+				TimeUnixNano: unixNano(evt.time),
+				Name:         evt.name,
+				Attributes:   toKeyValues(evt.attributes),
+			})
+		}
+		s.mu.Unlock()
+		otlpSpans = append(otlpSpans, span)
+	}
+
+	payload := map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{"attributes": toKeyValues(e.resource)},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]string{"name": "flux-enhanced-cli", "version": e.version},
+						"spans": otlpSpans,
+					},
+				},
+			},
+		},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export traces: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("OTLP endpoint returned %s", resp.Status)
+	}
+	return nil
+}
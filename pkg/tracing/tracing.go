@@ -0,0 +1,207 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Span is a single timed operation within a trace
+type Span struct {
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	start    time.Time
+
+	mu         sync.Mutex
+	end        time.Time
+	attributes map[string]string
+	events     []spanEvent
+	statusCode int
+	statusMsg  string
+}
+
+type spanEvent struct {
+	name       string
+	time       time.Time
+	attributes map[string]string
+}
+
+// Status codes as defined by OTLP; a span's zero status code is Unset
+const (
+	statusOK    = 1
+	statusError = 2
+)
+
+type spanKey struct{}
+
+// tracer collects finished spans until they are exported on Shutdown
+type tracer struct {
+	mu       sync.Mutex
+	exporter *otlpExporter
+	spans    []*Span
+	// remote parent taken from TRACEPARENT, if any
+	parentTraceID string
+	parentSpanID  string
+}
+
+var global *tracer
+
+// Init configures tracing from the standard OTEL_* environment variables.
+// Tracing stays disabled unless an OTLP endpoint is configured. The returned
+// function flushes all recorded spans and must be called before exiting.
+func Init(serviceName, version string) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") || os.Getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return noop, nil
+	}
+
+	exporter, err := newExporterFromEnv(serviceName, version)
+	if err != nil || exporter == nil {
+		return noop, err
+	}
+
+	t := &tracer{exporter: exporter}
+	// Join an existing trace (e.g. from a CI pipeline) when TRACEPARENT is set
+	if traceID, spanID, ok := parseTraceparent(os.Getenv("TRACEPARENT")); ok {
+		t.parentTraceID = traceID
+		t.parentSpanID = spanID
+	}
+	global = t
+
+	return func(ctx context.Context) error {
+		t.mu.Lock()
+		spans := t.spans
+		t.spans = nil
+		t.mu.Unlock()
+		if len(spans) == 0 {
+			return nil
+		}
+		return t.exporter.export(ctx, spans)
+	}, nil
+}
+
+// Start begins a span as a child of the span in ctx. When tracing is disabled
+// it returns ctx unchanged and a nil span, whose methods are no-ops.
+func Start(ctx context.Context, name string, attributes ...string) (context.Context, *Span) {
+	if global == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		spanID:     randomHex(8),
+		name:       name,
+		start:      time.Now(),
+		attributes: map[string]string{},
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok && parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else if global.parentTraceID != "" {
+		span.traceID = global.parentTraceID
+		span.parentID = global.parentSpanID
+	} else {
+		span.traceID = randomHex(16)
+	}
+	span.SetAttributes(attributes...)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext returns the current span in ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SetAttributes sets key/value attribute pairs on the span
+func (s *Span) SetAttributes(keyValues ...string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		s.attributes[keyValues[i]] = keyValues[i+1]
+	}
+}
+
+// AddEvent records a timestamped event on the span
+func (s *Span) AddEvent(name string, keyValues ...string) {
+	if s == nil {
+		return
+	}
+	attrs := map[string]string{}
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		attrs[keyValues[i]] = keyValues[i+1]
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, spanEvent{name: name, time: time.Now(), attributes: attrs})
+}
+
+// SetError marks the span as failed
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusCode = statusError
+	s.statusMsg = err.Error()
+}
+
+// SetOK marks the span as successful
+func (s *Span) SetOK() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusCode = statusOK
+}
+
+// End finishes the span and queues it for export
+func (s *Span) End() {
+	if s == nil || global == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.end.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	global.mu.Lock()
+	global.spans = append(global.spans, s)
+	global.mu.Unlock()
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand never fails on supported platforms; keep IDs non-zero regardless
+		return strings.Repeat("1", n*2)
+	}
+	return hex.EncodeToString(b)
+}
+
+// parseTraceparent parses a W3C traceparent header value
+func parseTraceparent(value string) (string, string, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", false
+	}
+	if _, err := hex.DecodeString(parts[1] + parts[2]); err != nil {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
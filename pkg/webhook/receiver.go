@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// receiverBody is the payload sent to Receivers. The notification-controller
+// only validates it, the reconcile request doesn't depend on it.
+var receiverBody = []byte(`{"source":"flux-enhanced-cli"}`)
+
+// ReceiverTypes are the Receiver types TriggerReceiver can sign requests for
+var ReceiverTypes = []string{"generic", "generic-hmac", "github", "gitlab", "bitbucket", "harbor"}
+
+// ResponseError is a request to a Receiver it answered with an error status
+type ResponseError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("receiver returned %s: %s", e.Status, e.Body)
+}
+
+// TriggerReceiver POSTs to the webhook URL of a notification-controller
+// Receiver, signed the way a Receiver of that type expects, so it requests a
+// reconcile of its resources. The token is the Receiver's secret token; it
+// isn't needed for generic Receivers.
+func TriggerReceiver(ctx context.Context, url, receiverType, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(receiverBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write(receiverBody)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	switch receiverType {
+	case "generic":
+	case "generic-hmac":
+		req.Header.Set("X-Signature", signature)
+	case "github":
+		req.Header.Set("X-GitHub-Event", "push")
+		req.Header.Set("X-Hub-Signature-256", signature)
+	case "gitlab":
+		req.Header.Set("X-Gitlab-Event", "Push Hook")
+		req.Header.Set("X-Gitlab-Token", token)
+	case "bitbucket":
+		req.Header.Set("X-Event-Key", "repo:push")
+		req.Header.Set("X-Hub-Signature", signature)
+	case "harbor":
+		req.Header.Set("Authorization", token)
+	default:
+		return fmt.Errorf("receiver type %s is not supported", receiverType)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &ResponseError{StatusCode: resp.StatusCode, Status: resp.Status, Body: strings.TrimSpace(string(body))}
+	}
+	return nil
+}
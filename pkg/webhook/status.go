@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Commit status states
+const (
+	StatePending = "pending"
+	StateSuccess = "success"
+	StateFailure = "failure"
+)
+
+// gitlabStates maps states to GitLab's commit status states
+var gitlabStates = map[string]string{
+	StatePending: "running",
+	StateSuccess: "success",
+	StateFailure: "failed",
+}
+
+// StatusReporter posts commit statuses back to GitHub or GitLab. Statuses for
+// a provider without a token are skipped.
+type StatusReporter struct {
+	GitHubToken string
+	// GitHubURL is the API base URL, e.g. https://api.github.com
+	GitHubURL   string
+	GitLabToken string
+	// GitLabURL is the instance URL, e.g. https://gitlab.com
+	GitLabURL string
+}
+
+// SetStatus sets the commit status called name on the pushed commit
+func (s *StatusReporter) SetStatus(ctx context.Context, push *Push, state, name, description string) error {
+	// GitHub rejects descriptions over 140 characters
+	if runes := []rune(description); len(runes) > 140 {
+		description = string(runes[:137]) + "..."
+	}
+
+	var req *http.Request
+	var err error
+	switch push.Provider {
+	case ProviderGitHub:
+		if s.GitHubToken == "" {
+			return nil
+		}
+		payload, _ := json.Marshal(map[string]string{
+			"state":       state,
+			"context":     name,
+			"description": description,
+		})
+		endpoint := fmt.Sprintf("%s/repos/%s/statuses/%s", strings.TrimSuffix(s.GitHubURL, "/"), push.Repository, push.SHA)
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+s.GitHubToken)
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("Content-Type", "application/json")
+	case ProviderGitLab:
+		if s.GitLabToken == "" {
+			return nil
+		}
+		query := url.Values{
+			"state":       {gitlabStates[state]},
+			"name":        {name},
+			"description": {description},
+		}
+		endpoint := fmt.Sprintf("%s/api/v4/projects/%d/statuses/%s?%s", strings.TrimSuffix(s.GitLabURL, "/"), push.ProjectID, push.SHA, query.Encode())
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("PRIVATE-TOKEN", s.GitLabToken)
+	default:
+		return fmt.Errorf("unsupported provider '%s'", push.Provider)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to set commit status: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("setting the commit status returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Providers a push can come from
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// ErrIgnored is returned for valid deliveries that aren't branch pushes
+// (pings, tag pushes, branch deletions)
+var ErrIgnored = errors.New("not a branch push")
+
+// ErrUnauthorized is returned when the signature or secret token is wrong
+var ErrUnauthorized = errors.New("invalid webhook signature")
+
+// Push is a branch push delivered by a Git provider
+type Push struct {
+	Provider string
+	// Repository is the repository path, e.g. org/repo or group/subgroup/project
+	Repository string
+	Branch     string
+	SHA        string
+	// ProjectID is the GitLab project ID, used to post commit statuses
+	ProjectID int
+}
+
+// ShortSHA returns the first 7 characters of the commit SHA
+func (p *Push) ShortSHA() string {
+	if len(p.SHA) > 7 {
+		return p.SHA[:7]
+	}
+	return p.SHA
+}
+
+// ParseGitHub validates the X-Hub-Signature-256 HMAC of a GitHub delivery
+// and returns the push it describes.
+func ParseGitHub(header http.Header, body []byte, secret string) (*Push, error) {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(header.Get("X-Hub-Signature-256")), []byte(expected)) {
+		return nil, ErrUnauthorized
+	}
+	if header.Get("X-GitHub-Event") != "push" {
+		return nil, ErrIgnored
+	}
+
+	var payload struct {
+		Ref        string `json:"ref"`
+		After      string `json:"after"`
+		Deleted    bool   `json:"deleted"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid push payload: %w", err)
+	}
+	branch, ok := strings.CutPrefix(payload.Ref, "refs/heads/")
+	if !ok || payload.Deleted {
+		return nil, ErrIgnored
+	}
+	return &Push{
+		Provider:   ProviderGitHub,
+		Repository: payload.Repository.FullName,
+		Branch:     branch,
+		SHA:        payload.After,
+	}, nil
+}
+
+// ParseGitLab checks the X-Gitlab-Token secret of a GitLab delivery (GitLab
+// doesn't sign payloads) and returns the push it describes.
+func ParseGitLab(header http.Header, body []byte, secret string) (*Push, error) {
+	if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+		return nil, ErrUnauthorized
+	}
+	if header.Get("X-Gitlab-Event") != "Push Hook" {
+		return nil, ErrIgnored
+	}
+
+	var payload struct {
+		Ref         string  `json:"ref"`
+		CheckoutSHA *string `json:"checkout_sha"`
+		ProjectID   int     `json:"project_id"`
+		Project     struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"project"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid push payload: %w", err)
+	}
+	branch, ok := strings.CutPrefix(payload.Ref, "refs/heads/")
+	// checkout_sha is null when the branch was deleted
+	if !ok || payload.CheckoutSHA == nil {
+		return nil, ErrIgnored
+	}
+	return &Push{
+		Provider:   ProviderGitLab,
+		Repository: payload.Project.PathWithNamespace,
+		Branch:     branch,
+		SHA:        *payload.CheckoutSHA,
+		ProjectID:  payload.ProjectID,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// pluginPrefix is the prefix of the executables in PATH that add subcommands:
+// "flux-enhanced-cli-foo" implements "flux-enhanced-cli foo"
+const pluginPrefix = "flux-enhanced-cli-"
+
+// pluginEnvPrefix is the prefix of the environment variables passing the
+// global flags to plugins: --namespace becomes FLUX_ENHANCED_CLI_NAMESPACE
+const pluginEnvPrefix = "FLUX_ENHANCED_CLI_"
+
+// findPlugin looks for a plugin named by the first positional argument,
+// after the global flags. It returns the plugin's path and the argument's
+// index, or "" when the argument is a resource kind, a flag is unknown or no
+// such plugin is in PATH.
+func findPlugin(fs *flag.FlagSet, args []string) (string, int) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return "", 0
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if _, ok := resourceKindAliases[strings.ToLower(arg)]; ok || strings.EqualFold(arg, "source") || strings.Contains(arg, "/") {
+				return "", 0
+			}
+			path, err := exec.LookPath(pluginPrefix + arg)
+			if err != nil {
+				return "", 0
+			}
+			return path, i
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		f := fs.Lookup(name)
+		if f == nil {
+			return "", 0
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); hasValue || (ok && b.IsBoolFlag()) {
+			continue
+		}
+		i++
+	}
+	return "", 0
+}
+
+// runPlugin parses the global flags before the plugin name, exports them to
+// the plugin's environment and runs the plugin with the remaining arguments,
+// returning its exit code
+func runPlugin(path string, fs *flag.FlagSet, globalArgs, args []string) int {
+	if err := fs.Parse(globalArgs); err != nil {
+		return 1
+	}
+
+	env := os.Environ()
+	if self, err := os.Executable(); err == nil {
+		env = append(env, "FLUX_ENHANCED_CLI="+self)
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		// One-letter flags are shorthands of long ones
+		if len(f.Name) == 1 {
+			return
+		}
+		name := strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		env = append(env, fmt.Sprintf("%s%s=%s", pluginEnvPrefix, name, f.Value.String()))
+	})
+
+	cmd := exec.Command(path, args...)
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "Error: plugin %s: %v\n", path, err)
+		return 1
+	}
+	return 0
+}
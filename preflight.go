@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// checkPermissions verifies through SelfSubjectAccessReviews that the caller
+// may trigger and watch the resource (and trigger its source when it is
+// reconciled too), so a missing RBAC rule fails before the trigger instead of
+// as a Forbidden error mid-run. Missing permissions on events only warn. When
+// the reviews can't be made the check is skipped with a warning.
+func checkPermissions(ctx context.Context, opts reconcileOptions) error {
+	// The permissions of custom resources are only known for Flux kinds
+	if opts.gvr != nil {
+		return nil
+	}
+	out := output.FromContext(ctx)
+	kind := opts.kind
+	if kind == "source" {
+		kind = opts.sourceType
+	}
+
+	cluster, err := events.NewCluster(opts.client)
+	if err != nil {
+		return err
+	}
+	withSource := !opts.skipSource && (kind == "kustomization" || kind == "helmrelease" || kind == "terraform")
+	permissions, err := cluster.ReconcilePermissions(ctx, kind, opts.namespace, opts.name, withSource)
+	if err != nil {
+		out.PrintWarning(fmt.Sprintf("Skipping the permission check: %v", err))
+		return nil
+	}
+	missing, err := cluster.MissingPermissions(ctx, permissions)
+	if err != nil {
+		out.PrintWarning(fmt.Sprintf("Skipping the permission check: %v", err))
+		return nil
+	}
+	var descriptions []string
+	for _, p := range missing {
+		if p.Optional {
+			out.PrintWarning(fmt.Sprintf("Missing permission %s, events won't be shown", p))
+			continue
+		}
+		descriptions = append(descriptions, "missing permission "+p.String())
+	}
+	if len(descriptions) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(descriptions, "; "))
+}
+
+// checkTenantPermissions impersonates the service account a Kustomization
+// is applied as (spec.serviceAccountName) and verifies it may apply and
+// prune the kinds in its inventory and, with a path, in the local build, so
+// a multi-tenancy RBAC gap fails here instead of in the controller. Other
+// kinds are skipped.
+func checkTenantPermissions(ctx context.Context, opts reconcileOptions) error {
+	if opts.kind != "kustomization" {
+		return nil
+	}
+	out := output.FromContext(ctx)
+	cluster, err := events.NewCluster(opts.client)
+	if err != nil {
+		return err
+	}
+	tenant, err := cluster.KustomizationTenant(ctx, opts.namespace, opts.name)
+	if err != nil {
+		return fmt.Errorf("tenant check: %w", err)
+	}
+	if tenant.ServiceAccount == "" {
+		out.PrintStatus("Tenant check: no serviceAccountName, the Kustomization is applied with the controller's own account")
+		return nil
+	}
+	// The local build holds the objects this reconcile will apply, which
+	// may be of kinds the last apply didn't have
+	if opts.path != "" {
+		built, err := buildInventory(ctx, opts)
+		if err != nil {
+			out.PrintWarning(fmt.Sprintf("Tenant check: only checking the inventory, the local build failed: %v", err))
+		}
+		tenant.Objects = append(tenant.Objects, built...)
+	}
+	if len(tenant.Objects) == 0 {
+		out.PrintStatus("Tenant check: the Kustomization has no inventory yet, nothing to check")
+		return nil
+	}
+
+	// Only the user is impersonated, the API server adds the service
+	// account groups
+	tenantOpts := opts.client
+	tenantOpts.As, tenantOpts.AsGroups = tenant.User(opts.namespace), nil
+	tenantCluster, err := events.NewCluster(tenantOpts)
+	if err != nil {
+		return err
+	}
+	permissions, unknown := cluster.ApplyPermissions(tenant)
+	for _, kind := range unknown {
+		out.PrintWarning(fmt.Sprintf("Tenant check: %s is not served by the API server, skipped", kind))
+	}
+	out.PrintStatus(fmt.Sprintf("Tenant check: verifying that %s may apply %d object(s)", tenantOpts.As, len(tenant.Objects)))
+	missing, err := tenantCluster.MissingPermissions(ctx, permissions)
+	if err != nil {
+		return fmt.Errorf("tenant check: could not impersonate %s: %w", tenantOpts.As, err)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, len(missing))
+	for i, p := range missing {
+		descriptions[i] = "missing permission " + p.String()
+	}
+	return fmt.Errorf("service account %s/%s cannot apply the objects: %s", opts.namespace, tenant.ServiceAccount, strings.Join(descriptions, "; "))
+}
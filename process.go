@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// childGracePeriod is how long a child process may take to exit after it is
+// asked to stop, before it is killed
+const childGracePeriod = 5 * time.Second
+
+// command is exec.CommandContext, except that cancelling ctx (Ctrl+C or a
+// timeout) first asks the process to stop with SIGTERM and only kills it
+// after childGracePeriod. Wait also gives up on output the process's
+// children keep open after that period.
+func command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Cancel = func() error {
+		return terminate(cmd.Process)
+	}
+	cmd.WaitDelay = childGracePeriod
+	return cmd
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// terminate asks the process to exit
+func terminate(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// terminate kills the process; Windows can't deliver SIGTERM
+func terminate(p *os.Process) error {
+	return p.Kill()
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// inventorySet indexes inventory objects by their entry ID
+func inventorySet(objects []events.InventoryObject) map[string]events.InventoryObject {
+	set := make(map[string]events.InventoryObject, len(objects))
+	for _, o := range objects {
+		set[o.ID()] = o
+	}
+	return set
+}
+
+// missingObjects returns the objects of before that are not in after, sorted
+func missingObjects(before, after []events.InventoryObject) []events.InventoryObject {
+	remaining := inventorySet(after)
+	var missing []events.InventoryObject
+	for _, o := range before {
+		if _, ok := remaining[o.ID()]; !ok {
+			missing = append(missing, o)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].String() < missing[j].String() })
+	return missing
+}
+
+// fluxBuild runs "flux build kustomization" against a local path and returns
+// the YAML the build produces
+func fluxBuild(ctx context.Context, opts reconcileOptions) ([]byte, error) {
+	args := append([]string{"build", "kustomization", opts.name, "-n", opts.namespace, "--path", opts.path}, opts.client.FluxArgs()...)
+	cmd := command(ctx, "flux", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("flux build failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// buildObjects runs "flux build kustomization" against a local path and
+// returns the objects the build produces.
+func buildObjects(ctx context.Context, opts reconcileOptions) ([]*unstructured.Unstructured, error) {
+	built, err := fluxBuild(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(built), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("failed to parse flux build output: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+}
+
+// buildInventory returns the inventory entries of the objects a local build
+// produces
+func buildInventory(ctx context.Context, opts reconcileOptions) ([]events.InventoryObject, error) {
+	built, err := buildObjects(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]events.InventoryObject, 0, len(built))
+	for _, obj := range built {
+		gvk := obj.GroupVersionKind()
+		objects = append(objects, events.InventoryObject{
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Group:     gvk.Group,
+			Kind:      gvk.Kind,
+			Version:   gvk.Version,
+		})
+	}
+	return objects, nil
+}
+
+// previewPrune lists the objects of the current inventory that the local
+// build no longer contains. With confirm set it asks before continuing and
+// returns an error if the user declines.
+func previewPrune(ctx context.Context, opts reconcileOptions, monitor *events.Monitor, current []events.InventoryObject) error {
+	out := output.FromContext(ctx)
+	built, err := buildInventory(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	removed := missingObjects(current, built)
+	if len(removed) == 0 {
+		out.PrintSublog("🗑️  No objects would be pruned")
+		return nil
+	}
+
+	prune, err := monitor.PruneEnabled()
+	if err != nil {
+		return err
+	}
+	if prune {
+		out.PrintWarning(fmt.Sprintf("%d objects would be pruned:", len(removed)))
+	} else {
+		out.PrintWarning(fmt.Sprintf("%d objects are no longer in the build (prune is disabled, they would be left behind):", len(removed)))
+	}
+	for _, o := range removed {
+		out.PrintSublog("  - " + o.String())
+	}
+
+	if !opts.confirmPrune || !prune {
+		return nil
+	}
+	return confirm(fmt.Sprintf("Delete these %d objects?", len(removed)))
+}
+
+// changeSymbols prefix the objects of reportInventoryChanges by action, and
+// changeOrder lists the actions in that order
+var (
+	changeSymbols = map[string]string{"created": "+", "configured": "~", "deleted": "-"}
+	changeOrder   = map[string]int{"created": 0, "configured": 1, "deleted": 2}
+)
+
+// reportInventoryChanges prints what the reconcile changed: the objects that
+// appeared in and disappeared (were pruned) from the inventory, and those the
+// controller reported configuring in its change set events
+func reportInventoryChanges(ctx context.Context, before, after []events.InventoryObject, changeSet []events.ChangeSetEntry) {
+	out := output.FromContext(ctx)
+	actions := map[string]string{}
+	for _, o := range missingObjects(after, before) {
+		actions[o.String()] = "created"
+	}
+	for _, o := range missingObjects(before, after) {
+		actions[o.String()] = "deleted"
+	}
+	for _, e := range changeSet {
+		if _, ok := actions[e.Object]; !ok {
+			actions[e.Object] = e.Action
+		}
+	}
+	if len(actions) == 0 {
+		out.PrintSublog("📝 No objects changed")
+		return
+	}
+
+	counts := map[string]int{}
+	objects := make([]string, 0, len(actions))
+	for object, action := range actions {
+		counts[action]++
+		objects = append(objects, object)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		if a, b := changeOrder[actions[objects[i]]], changeOrder[actions[objects[j]]]; a != b {
+			return a < b
+		}
+		return objects[i] < objects[j]
+	})
+	out.PrintSublog(fmt.Sprintf("📝 Applied %d changes: %d created, %d configured, %d deleted",
+		len(objects), counts["created"], counts["configured"], counts["deleted"]))
+	for _, object := range objects {
+		out.PrintSublog(fmt.Sprintf("  %s %s", changeSymbols[actions[object]], object))
+	}
+}
+
+// confirm asks a yes/no question on the terminal; anything but "y" or "yes"
+// declines. Without an interactive stdin it always declines.
+func confirm(question string) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("confirmation required but stdin is not a terminal")
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("aborted by user")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/config"
+)
+
+// queuedResource is one of several resources given as arguments
+type queuedResource struct {
+	kind       string
+	sourceType string
+	name       string
+}
+
+// parseResourceQueue parses several "<kind>/<name>" arguments. It reports
+// false when the arguments aren't all references, e.g. "source git app".
+func parseResourceQueue(args []string) ([]queuedResource, bool, error) {
+	if len(args) < 2 {
+		return nil, false, nil
+	}
+	for _, arg := range args {
+		if !strings.Contains(arg, "/") {
+			return nil, false, nil
+		}
+	}
+	queue := make([]queuedResource, 0, len(args))
+	seen := map[string]bool{}
+	for _, arg := range args {
+		monitorKind, name, err := parseResourceRef(arg)
+		if err != nil {
+			return nil, true, err
+		}
+		if seen[monitorKind+"/"+name] {
+			return nil, true, fmt.Errorf("%s is given twice", arg)
+		}
+		seen[monitorKind+"/"+name] = true
+		kind, sourceType := cliKind(monitorKind)
+		queue = append(queue, queuedResource{kind: kind, sourceType: sourceType, name: name})
+	}
+	return queue, true, nil
+}
+
+// queueStage turns the resources given as arguments into a batch stage,
+// based on the command line options. With sequential they run one at a
+// time in order, and onError "stop" skips the rest after a failure;
+// otherwise they run concurrently.
+func queueStage(base reconcileOptions, queue []queuedResource, sequential bool, onError string) batchStage {
+	stage := batchStage{name: "arguments", parallel: !sequential, onFailure: onError}
+	var previous []string
+	for _, r := range queue {
+		opts := base
+		opts.kind, opts.sourceType, opts.name = r.kind, r.sourceType, r.name
+		// --force only applies to the HelmReleases of the queue
+		opts.force = base.force && opts.kind == "helmrelease"
+		id := r.kind + "/" + r.name
+		if r.kind == "source" {
+			id = r.sourceType + "/" + r.name
+		}
+		item := batchItem{id: id, opts: opts}
+		if sequential && onError == config.OnFailureStop {
+			// Depending on every earlier item names the one that failed
+			item.dependsOn = append([]string(nil), previous...)
+		}
+		stage.items = append(stage.items, item)
+		previous = append(previous, id)
+	}
+	return stage
+}
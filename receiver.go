@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/tracing"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/webhook"
+)
+
+// receiverRequestWait is how long the annotation a Receiver sets is waited
+// for, to learn the request token
+const receiverRequestWait = 30 * time.Second
+
+// receiverWebhookURL reports whether the --receiver-url is a Receiver's full
+// webhook URL, which is called without reading the Receiver, signed as
+// --receiver-type with --receiver-token
+func receiverWebhookURL(url string) bool {
+	return strings.Contains(url, "/hook/")
+}
+
+// runReceiverTrigger requests the reconcile by calling the webhook of a
+// notification-controller Receiver instead of annotating the resource. When
+// waiting, the reconcile request annotation the Receiver sets is returned as
+// the request token. The receiverType and receiverToken options override what
+// is read from the Receiver.
+func runReceiverTrigger(ctx context.Context, opts reconcileOptions) (string, error) {
+	out := output.FromContext(ctx)
+	_, span := tracing.Start(ctx, "trigger", "flux.receiver", opts.viaReceiver)
+	defer span.End()
+	fail := func(err error) (string, error) {
+		span.SetError(err)
+		return "", &triggerError{code: 1, message: fmt.Sprintf("receiver %s: %v", opts.viaReceiver, err), transient: transientError(err)}
+	}
+
+	kind := opts.kind
+	if kind == "source" {
+		kind = opts.sourceType
+	}
+	var cluster *events.Cluster
+	url, receiverType, token := opts.receiverURL, opts.receiverType, opts.receiverToken
+	if receiverType == "" {
+		receiverType = "generic"
+	}
+	if !receiverWebhookURL(url) || opts.wait {
+		var err error
+		if cluster, err = events.NewCluster(opts.client); err != nil {
+			return fail(err)
+		}
+	}
+	if !receiverWebhookURL(url) {
+		namespace, name := opts.namespace, opts.viaReceiver
+		if ns, n, ok := strings.Cut(name, "/"); ok {
+			namespace, name = ns, n
+		}
+		receiver, err := cluster.Receiver(ctx, namespace, name, token)
+		if err != nil {
+			return fail(err)
+		}
+		if selects, err := cluster.ReceiverSelects(ctx, receiver, kind, opts.namespace, opts.name); err == nil && !selects {
+			out.PrintWarning(fmt.Sprintf("Receiver %s/%s doesn't list %s/%s in its resources", namespace, name, kind, opts.name))
+		}
+		url = strings.TrimSuffix(url, "/") + receiver.Path
+		if opts.receiverType == "" {
+			receiverType = receiver.Type
+		}
+		token = receiver.Token
+	}
+
+	// The annotation is only read when waiting, so a full webhook URL with
+	// --wait=false needs no API access
+	var before string
+	if opts.wait {
+		var err error
+		if before, err = cluster.RequestedAt(ctx, kind, opts.namespace, opts.name); err != nil {
+			return fail(err)
+		}
+	}
+	// The webhook path is a secret as well
+	base, _, _ := strings.Cut(url, "/hook/")
+	out.PrintCommand("POST", base+"/hook/...", "("+receiverType+" receiver)")
+	if err := webhook.TriggerReceiver(ctx, url, receiverType, token); err != nil {
+		return fail(err)
+	}
+	if !opts.wait {
+		return "", nil
+	}
+
+	deadline := time.Now().Add(receiverRequestWait)
+	for time.Now().Before(deadline) {
+		requested, err := cluster.RequestedAt(ctx, kind, opts.namespace, opts.name)
+		if err == nil && requested != before {
+			return requested, nil
+		}
+		select {
+		case <-ctx.Done():
+			return fail(ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+	return fail(fmt.Errorf("%s/%s was not annotated within %s; check the receiver's resources and the notification-controller logs", kind, opts.name, receiverRequestWait))
+}
@@ -0,0 +1,645 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/tracing"
+)
+
+type reconcileOptions struct {
+	kind       string
+	name       string
+	namespace  string
+	sourceType string
+	wait       bool
+	timeout    time.Duration
+	client     events.ClientOptions
+	// triggerTimeout bounds each trigger attempt and waitTimeout the wait
+	// for readiness, which is bounded by timeout when unset
+	triggerTimeout time.Duration
+	waitTimeout    time.Duration
+	// healthCheck enables extra checks once the resource is Ready
+	healthCheck string
+	// logLines is the number of log lines shown for crash looping pods
+	logLines int
+	// requireNewArtifact makes a source only count as reconciled once its
+	// artifact revision has changed since the trigger
+	requireNewArtifact bool
+	// requireSourceRevision makes a Kustomization only count as reconciled
+	// once it applied its source's current artifact revision
+	requireSourceRevision bool
+	// intervals overrides the monitor's polling intervals
+	intervals events.Intervals
+	// skipSource leaves out reconciling the source of a Kustomization or
+	// HelmRelease first (--with-source=false)
+	skipSource bool
+	// retries is how often a failed trigger is retried
+	retries int
+	// forceAfter re-triggers the reconcile when nothing happened for this long
+	forceAfter time.Duration
+	// settle keeps watching this long after Ready, failing if the resource
+	// turns not ready again
+	settle time.Duration
+	// follow keeps printing events and condition changes after a successful
+	// run until Ctrl+C
+	follow bool
+	// retriggerForce makes re-triggers of HelmReleases force an upgrade
+	retriggerForce bool
+	// force triggers a HelmRelease through the forceAt annotation, upgrading
+	// it even when nothing changed
+	force bool
+	// digest is the artifact digest an oci or bucket source must report
+	digest string
+	// path is a local checkout of the Kustomization's sources, used to
+	// preview what a reconcile would prune
+	path string
+	// confirmPrune asks before reconciling when objects would be pruned
+	confirmPrune bool
+	// controllerLogs streams the log lines of the resource's controller in
+	// fluxNamespace while waiting
+	controllerLogs bool
+	// verbose streams the logs of all Flux controllers about the resource and
+	// its sources, prefixed with the controller
+	verbose bool
+	// fluxNamespace is where the Flux controllers run; runReconcile
+	// defaults it to defaultFluxNamespace
+	fluxNamespace string
+	// checkDrift dry-run applies the local build from path again after the
+	// reconcile and warns about objects that drifted
+	checkDrift bool
+	// viaReceiver triggers the reconcile by calling the webhook of this
+	// Receiver, at receiverURL, instead of annotating the resource
+	viaReceiver string
+	receiverURL string
+	// receiverType and receiverToken sign the webhook request, instead of
+	// the Receiver's type and the token of its secret
+	receiverType  string
+	receiverToken string
+	// dryRun prints the plan ("client") or only dry-run applies the local
+	// build from path ("server")
+	dryRun string
+	// preHook and postHook are shell commands run before the reconcile and
+	// after it finished (successfully or not)
+	preHook  string
+	postHook string
+	// commitInfo fetches the commit message and author of a reconciled git
+	// source from its origin
+	commitInfo bool
+	// showAlerts lists the Alerts that forward the resource's events
+	showAlerts bool
+	// tenantCheck verifies that a Kustomization's service account may apply
+	// its inventory before triggering
+	tenantCheck bool
+	// skipPermissionCheck skips the access reviews of checkPermissions
+	skipPermissionCheck bool
+	// waitOnly skips the trigger and waits for the reconcile in flight
+	waitOnly bool
+	// attachIfRunning waits for a reconcile already in progress instead of
+	// requesting another one
+	attachIfRunning bool
+	// recursive waits for the Kustomizations and HelmReleases a
+	// Kustomization applied, and theirs in turn, to be Ready
+	recursive bool
+	// lock serializes runs on the resource through a Lease, waiting up to
+	// lockTimeout for it; lockTTL is how long an unrenewed Lease is valid
+	lock        bool
+	lockTimeout time.Duration
+	lockTTL     time.Duration
+	// ifOlderThan skips the reconcile when the resource reconciled
+	// successfully more recently than this
+	ifOlderThan time.Duration
+	// gvr is set for custom resources (--gvr); kind then holds its resource
+	// name
+	gvr *schema.GroupVersionResource
+	// readyConditions replace Ready=True as the wait criteria (--condition)
+	readyConditions []events.ConditionRequirement
+	// waitFor are JSONPath checks on the live object (--wait-for)
+	waitFor []events.WaitExpression
+	// hintThresholds are the percentages of the timeout at which hints are
+	// printed (--hint-at)
+	hintThresholds []int
+	// hintRules are the custom hints checked before the built-in ones
+	hintRules []events.HintRule
+	// failOnEvents are event reasons that abort the wait (--fail-fast)
+	failOnEvents []string
+}
+
+// defaultFluxNamespace is the namespace Flux is installed in by default
+const defaultFluxNamespace = "flux-system"
+
+// runReconcile triggers the reconciliation and optionally waits for it to
+// complete, holding the --lock Lease and running the pre- and post-hooks
+// around it, and returns the outcome of the run.
+func runReconcile(ctx context.Context, opts reconcileOptions) report.Result {
+	// Callers without a --flux-namespace flag leave it unset
+	if opts.fluxNamespace == "" {
+		opts.fluxNamespace = defaultFluxNamespace
+	}
+	// API server warnings go with the resource's output
+	opts.client.Printer = output.FromContext(ctx)
+	switch opts.dryRun {
+	case dryRunClient:
+		return printPlan(ctx, opts)
+	case dryRunServer:
+		return runDryRun(ctx, opts)
+	}
+
+	startTime := time.Now()
+	fail := func(err error) report.Result {
+		output.FromContext(ctx).PrintError(err.Error())
+		return report.Result{
+			Kind: opts.kind, Name: opts.name, Namespace: opts.namespace, Context: opts.client.Context,
+			StartedAt: startTime, ExitCode: 1, Message: err.Error(), Duration: time.Since(startTime),
+		}
+	}
+	if opts.lock {
+		lock, err := acquireLock(ctx, opts)
+		if err != nil {
+			return fail(err)
+		}
+		defer lock.Release()
+	}
+	if opts.preHook != "" {
+		if err := runHook(ctx, hookPre, opts.preHook, opts, nil); err != nil {
+			return fail(err)
+		}
+	}
+	result := reconcile(ctx, opts)
+	if opts.postHook != "" {
+		// The post-hook also runs when the reconcile timed out, so it gets a
+		// timeout of its own
+		hookCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opts.timeout)
+		defer cancel()
+		if err := runHook(hookCtx, hookPost, opts.postHook, opts, &result); err != nil {
+			output.FromContext(ctx).PrintError(err.Error())
+			if result.Success {
+				result.Success = false
+				result.ExitCode = 1
+				result.Message = err.Error()
+			}
+		}
+	}
+	return result
+}
+
+// reconcile triggers the reconciliation and optionally waits for it to
+// complete
+func reconcile(ctx context.Context, opts reconcileOptions) report.Result {
+	ctx, span := tracing.Start(ctx, "reconcile",
+		"flux.kind", opts.kind, "flux.name", opts.name, "flux.namespace", opts.namespace)
+	defer span.End()
+	out := output.FromContext(ctx)
+
+	startTime := time.Now()
+	result := report.Result{
+		Kind:      opts.kind,
+		Name:      opts.name,
+		Namespace: opts.namespace,
+		Context:   opts.client.Context,
+		StartedAt: startTime,
+	}
+	fail := func(code int, message string, monitor *events.Monitor) report.Result {
+		span.SetError(errors.New(message))
+		result.ExitCode = code
+		result.Message = message
+		result.Duration = time.Since(startTime)
+		if monitor != nil {
+			result.Conditions = monitor.Conditions()
+			result.WarningEvents = monitor.WarningEvents()
+			recordTimeline(&result, monitor)
+		}
+		return result
+	}
+
+	// A Receiver reconciles with its own permissions, and without waiting the
+	// run needs no API access at all
+	apiAccess := opts.viaReceiver == "" || opts.wait
+	if opts.viaReceiver == "" && !opts.waitOnly && !opts.skipPermissionCheck {
+		if err := checkPermissions(ctx, opts); err != nil {
+			out.PrintError(err.Error())
+			return fail(1, err.Error(), nil)
+		}
+	}
+	if opts.tenantCheck {
+		if err := checkTenantPermissions(ctx, opts); err != nil {
+			out.PrintError(err.Error())
+			return fail(1, err.Error(), nil)
+		}
+	}
+	if opts.showAlerts {
+		printMatchingAlerts(ctx, opts)
+	}
+
+	// Start event monitoring (only if we have a valid kind for monitoring)
+	var eventMonitor *events.Monitor
+	if opts.gvr != nil && apiAccess {
+		var err error
+		eventMonitor, err = events.NewCustomMonitor(ctx, opts.client, *opts.gvr, opts.name, opts.namespace)
+		if err != nil {
+			fmt.Fprintf(out.Stderr(), "Warning: Could not start event monitoring: %v\n", err)
+		} else {
+			defer eventMonitor.Stop()
+			eventMonitor.SetIntervals(opts.intervals)
+			eventMonitor.SetReadyConditions(opts.readyConditions)
+			eventMonitor.SetWaitExpressions(opts.waitFor)
+			eventMonitor.SetHintThresholds(opts.hintThresholds)
+			eventMonitor.SetHintRules(opts.hintRules)
+			if len(opts.failOnEvents) > 0 {
+				eventMonitor.SetFailOnEvents(opts.failOnEvents)
+			}
+			go eventMonitor.Watch()
+		}
+	} else if apiAccess && (opts.kind == "kustomization" || opts.kind == "helmrelease" || opts.kind == "source" || opts.kind == "terraform") {
+		var err error
+		monitorKind := opts.kind
+		if opts.kind == "source" {
+			monitorKind = opts.sourceType // Pass "git", "oci" or "bucket" to monitor
+		}
+		watchCtx, watchSpan := tracing.Start(ctx, "events.watch")
+		defer watchSpan.End()
+		monitorCtx := watchCtx
+		if opts.follow {
+			// Keep watching past --timeout and the Ctrl+C that ends the run
+			monitorCtx = context.WithoutCancel(watchCtx)
+		}
+		eventMonitor, err = events.NewMonitor(monitorCtx, opts.client, monitorKind, opts.name, opts.namespace)
+		if err != nil {
+			watchSpan.SetError(err)
+			fmt.Fprintf(out.Stderr(), "Warning: Could not start event monitoring: %v\n", err)
+		} else {
+			defer eventMonitor.Stop()
+			eventMonitor.SetIntervals(opts.intervals)
+			eventMonitor.SetReadyConditions(opts.readyConditions)
+			eventMonitor.SetWaitExpressions(opts.waitFor)
+			eventMonitor.SetHintThresholds(opts.hintThresholds)
+			eventMonitor.SetHintRules(opts.hintRules)
+			if len(opts.failOnEvents) > 0 {
+				eventMonitor.SetFailOnEvents(opts.failOnEvents)
+			}
+			if output.Redacting() && opts.kind == "kustomization" {
+				redactInventory(eventMonitor)
+			}
+			// Surface source failures (e.g. chart pull errors) as well
+			if opts.kind == "helmrelease" || ((opts.kind == "kustomization" || opts.kind == "terraform") && !opts.skipSource) {
+				if err := eventMonitor.WatchSources(); err == nil && output.Redacting() {
+					redactSources(eventMonitor)
+				}
+			}
+			go eventMonitor.Watch()
+		}
+	}
+
+	if opts.ifOlderThan > 0 && !opts.waitOnly && eventMonitor != nil {
+		if age, recent := recentlyReconciled(ctx, eventMonitor, opts.ifOlderThan); recent {
+			out.PrintMain("⏭️", fmt.Sprintf("%s/%s reconciled %s ago, within --if-older-than %s, skipping", opts.kind, opts.name, age, opts.ifOlderThan), output.ColorGreen)
+			result.Success = true
+			result.Skipped = true
+			result.Message = fmt.Sprintf("reconciled %s ago", age)
+			result.Duration = time.Since(startTime)
+			result.Revision, _ = eventMonitor.Revision()
+			result.Conditions = eventMonitor.Conditions()
+			return result
+		}
+	}
+
+	// Remember the artifact revision so a new one can be required
+	var previousRevision string
+	if opts.requireNewArtifact && opts.kind == "source" && eventMonitor != nil {
+		previousRevision = currentArtifactRevision(eventMonitor)
+	}
+
+	// Remember the inventory to report the changed objects, and preview pruning
+	// against a local build when a path is given
+	var inventoryBefore []events.InventoryObject
+	inventoryRead := false
+	if opts.confirmPrune && opts.kind == "kustomization" && eventMonitor == nil {
+		// Without the inventory nothing could be confirmed, so don't reconcile
+		message := "can't preview pruning without access to the Kustomization, not reconciling with --confirm-prune"
+		out.PrintError(message)
+		return fail(1, message, nil)
+	}
+	if opts.kind == "kustomization" && eventMonitor != nil {
+		var err error
+		inventoryBefore, err = eventMonitor.Inventory()
+		inventoryRead = err == nil
+		if err != nil && opts.confirmPrune {
+			message := fmt.Sprintf("can't read the inventory to preview pruning, not reconciling with --confirm-prune: %v", err)
+			out.PrintError(message)
+			return fail(1, message, eventMonitor)
+		}
+		if opts.path != "" {
+			if err := previewPrune(ctx, opts, eventMonitor, inventoryBefore); err != nil {
+				out.PrintError(fmt.Sprintf("Prune preview: %v", err))
+				return fail(1, err.Error(), eventMonitor)
+			}
+		}
+	}
+
+	var token string
+	var err error
+	attached := false
+	if opts.attachIfRunning && !opts.waitOnly && eventMonitor != nil {
+		token, attached = runningReconcile(ctx, eventMonitor)
+	}
+	// Taken before the trigger, since flux reconcile only returns once the
+	// resource is ready
+	triggeredAt := time.Now()
+	switch {
+	case opts.waitOnly:
+		token, err = pendingRequest(ctx, eventMonitor)
+	case !attached:
+		token, err = triggerWithRetries(ctx, opts)
+	}
+	if err != nil {
+		var te *triggerError
+		if errors.As(err, &te) {
+			return fail(te.code, te.message, eventMonitor)
+		}
+		return fail(1, err.Error(), eventMonitor)
+	}
+	result.TriggeredAt = triggeredAt
+	// Custom resources whose controller ignores the annotation never
+	// record handling it
+	if token != "" && eventMonitor != nil && (opts.gvr == nil || eventMonitor.TracksReconcileRequests()) {
+		eventMonitor.ExpectHandled(token)
+	}
+
+	// Wait for reconciliation if requested
+	if opts.wait && eventMonitor != nil {
+		out.StartGroup(waitGroupTitle(opts))
+		out.PrintWaiting(opts.kind, opts.name)
+		_, waitSpan := tracing.Start(ctx, "wait")
+		waitCtx, stopWaitTasks := context.WithCancel(ctx)
+		// The re-triggers close and reopen the group, so they are done
+		// before it is closed for good
+		var retriggers sync.WaitGroup
+		if opts.forceAfter > 0 {
+			eventMonitor.MarkActivity()
+			retriggers.Add(1)
+			go func() {
+				defer retriggers.Done()
+				retriggerOnStall(waitCtx, opts, eventMonitor)
+			}()
+		}
+		switch {
+		case opts.verbose:
+			go func() {
+				if err := eventMonitor.TailAllControllerLogs(waitCtx, opts.fluxNamespace, triggeredAt); err != nil {
+					out.PrintWarning(fmt.Sprintf("Controller logs unavailable: %v", err))
+				}
+			}()
+		case opts.controllerLogs:
+			go func() {
+				if err := eventMonitor.TailControllerLogs(waitCtx, opts.fluxNamespace, triggeredAt); err != nil {
+					out.PrintWarning(fmt.Sprintf("Controller logs unavailable: %v", err))
+				}
+			}()
+		}
+		waitTimeout := opts.timeout
+		if opts.waitTimeout > 0 {
+			waitTimeout = opts.waitTimeout
+		}
+		err := eventMonitor.WaitForReady(ctx, waitTimeout)
+		if err == nil {
+			result.ReadyAfter = time.Since(result.TriggeredAt)
+		}
+		stopWaitTasks()
+		retriggers.Wait()
+		waitSpan.SetError(err)
+		waitSpan.End()
+		out.EndGroup()
+		if err != nil {
+			out.PrintError(fmt.Sprintf("Reconciliation failed or timed out: %v", err))
+			if opts.kind == "helmrelease" {
+				reportHelmTestFailures(ctx, eventMonitor, opts.logLines)
+			}
+			if opts.kind == "terraform" {
+				reportPendingPlan(ctx, eventMonitor)
+			}
+			if opts.kind == "kustomization" {
+				reportDecryptionFailure(ctx, eventMonitor, opts.fluxNamespace)
+			}
+			printFailureRecap(ctx, eventMonitor)
+			return fail(1, err.Error(), eventMonitor)
+		}
+
+		if inventoryRead {
+			if inventoryAfter, err := eventMonitor.Inventory(); err == nil {
+				reportInventoryChanges(ctx, inventoryBefore, inventoryAfter, eventMonitor.ChangeSet())
+			}
+		}
+
+		if opts.digest != "" {
+			if err := waitForArtifactDigest(ctx, eventMonitor, opts.digest); err != nil {
+				out.PrintError(fmt.Sprintf("Artifact digest mismatch: %v", err))
+				return fail(1, err.Error(), eventMonitor)
+			}
+		}
+
+		if opts.requireNewArtifact && opts.kind == "source" {
+			if err := waitForNewArtifact(ctx, eventMonitor, previousRevision); err != nil {
+				out.PrintError(fmt.Sprintf("No new artifact: %v", err))
+				return fail(1, err.Error(), eventMonitor)
+			}
+		}
+
+		if opts.requireSourceRevision && opts.kind == "kustomization" {
+			if err := waitForSourceRevision(ctx, eventMonitor); err != nil {
+				out.PrintError(fmt.Sprintf("Source revision not applied: %v", err))
+				return fail(1, err.Error(), eventMonitor)
+			}
+		}
+
+		if opts.kind == "source" {
+			printArtifactDetails(ctx, eventMonitor, opts.sourceType, opts.commitInfo)
+		}
+
+		if opts.kind == "terraform" {
+			printTerraformStatus(ctx, eventMonitor)
+		}
+
+		if opts.kind == "helmrelease" {
+			_, testSpan := tracing.Start(ctx, "helm.test")
+			err := checkHelmTests(ctx, eventMonitor, opts.logLines)
+			testSpan.SetError(err)
+			testSpan.End()
+			if err != nil {
+				out.PrintError(err.Error())
+				return fail(1, err.Error(), eventMonitor)
+			}
+		}
+
+		if opts.healthCheck == healthCheckInventory && opts.kind == "kustomization" {
+			_, healthSpan := tracing.Start(ctx, "healthcheck")
+			err := checkInventoryHealth(ctx, eventMonitor, opts.logLines)
+			healthSpan.SetError(err)
+			healthSpan.End()
+			if err != nil {
+				out.PrintError(fmt.Sprintf("Health check failed: %v", err))
+				return fail(1, err.Error(), eventMonitor)
+			}
+		}
+
+		if opts.recursive && opts.kind == "kustomization" {
+			_, childrenSpan := tracing.Start(ctx, "children")
+			// Without a trigger, children aren't expected to reconcile again
+			since := result.TriggeredAt
+			if opts.waitOnly {
+				since = time.Time{}
+			}
+			err := waitForChildren(ctx, eventMonitor, since)
+			childrenSpan.SetError(err)
+			childrenSpan.End()
+			if err != nil {
+				out.PrintError(err.Error())
+				return fail(1, err.Error(), eventMonitor)
+			}
+		}
+
+		if opts.checkDrift && opts.kind == "kustomization" {
+			_, driftSpan := tracing.Start(ctx, "drift")
+			applied, err := eventMonitor.Revision()
+			if err == nil {
+				err = checkDrift(ctx, opts, applied)
+			}
+			driftSpan.SetError(err)
+			driftSpan.End()
+			if err != nil {
+				out.PrintWarning(fmt.Sprintf("Drift check skipped: %v", err))
+			}
+		}
+
+		if opts.settle > 0 {
+			_, settleSpan := tracing.Start(ctx, "settle")
+			err := eventMonitor.WaitSettled(ctx, opts.settle)
+			settleSpan.SetError(err)
+			settleSpan.End()
+			if err != nil {
+				out.PrintError(fmt.Sprintf("Resource did not settle: %v", err))
+				if opts.kind == "helmrelease" {
+					reportHelmTestFailures(ctx, eventMonitor, opts.logLines)
+				}
+				printFailureRecap(ctx, eventMonitor)
+				return fail(1, err.Error(), eventMonitor)
+			}
+		}
+		out.PrintSuccess(opts.kind, opts.name)
+	}
+
+	span.SetOK()
+	result.Success = true
+	result.Duration = time.Since(startTime)
+	if eventMonitor != nil {
+		result.Revision, _ = eventMonitor.Revision()
+		result.Conditions = eventMonitor.Conditions()
+		result.WarningEvents = eventMonitor.WarningEvents()
+		// Following keeps recording readiness changes, so the timeline is
+		// taken once it ends
+		if opts.follow {
+			followResource(ctx, opts, eventMonitor)
+		}
+		recordTimeline(&result, eventMonitor)
+		if result.Flapping {
+			out.PrintWarning(fmt.Sprintf("%s/%s is flapping: it turned not ready after being ready during the run", opts.kind, opts.name))
+			for _, t := range result.Transitions {
+				out.PrintSublog("  " + t)
+			}
+		}
+	}
+	return result
+}
+
+// recentlyReconciled reports whether the resource reconciled successfully
+// within maxAge (--if-older-than), and how long ago. Failing to tell is a
+// reason to reconcile.
+func recentlyReconciled(ctx context.Context, monitor *events.Monitor, maxAge time.Duration) (time.Duration, bool) {
+	last, err := monitor.LastReconciled()
+	if err != nil {
+		output.FromContext(ctx).PrintWarning(fmt.Sprintf("Could not read the last reconcile time: %v", err))
+		return 0, false
+	}
+	if last.IsZero() {
+		return 0, false
+	}
+	age := time.Since(last).Round(time.Second)
+	return age, age < maxAge
+}
+
+// printFailureRecap lists every failing condition and warning event seen
+// during the run in full, since only a preview of each is shown live
+func printFailureRecap(ctx context.Context, monitor *events.Monitor) {
+	out := output.FromContext(ctx)
+	conditions := monitor.FailingConditions()
+	// A timeout may end on a status that never counted as failing
+	if last := monitor.Conditions(); last != "" && !slices.Contains(conditions, last) {
+		conditions = append(conditions, last)
+	}
+	warnings := monitor.WarningEvents()
+	if len(conditions) == 0 && len(warnings) == 0 {
+		return
+	}
+
+	out.StartGroup("Failure recap")
+	defer out.EndGroup()
+	out.PrintSublog("📋 Failure recap")
+	if len(conditions) > 0 {
+		out.PrintSublog(fmt.Sprintf("  Conditions (%d):", len(conditions)))
+		for _, c := range conditions {
+			out.PrintSublog("    - " + c)
+		}
+	}
+	if len(warnings) > 0 {
+		out.PrintSublog(fmt.Sprintf("  Warning events (%d):", len(warnings)))
+		for _, w := range warnings {
+			out.PrintSublog("    - " + w)
+		}
+	}
+	for _, hint := range monitor.FailureHints() {
+		out.PrintSublog("  💡 " + hint)
+	}
+}
+
+// recordTimeline adds when the request was acknowledged and the readiness
+// timeline to the result, flagging a resource that oscillated between ready
+// and not ready
+func recordTimeline(result *report.Result, monitor *events.Monitor) {
+	if acknowledged := monitor.AcknowledgedAt(); !acknowledged.IsZero() && !result.TriggeredAt.IsZero() {
+		result.AcknowledgedAfter = acknowledged.Sub(result.TriggeredAt)
+	}
+	result.Flapping = monitor.Flapping()
+	result.Transitions = nil
+	for _, t := range monitor.Transitions() {
+		result.Transitions = append(result.Transitions, t.String())
+	}
+}
+
+// redactInventory registers the names of the objects a Kustomization applies,
+// since its events list them
+func redactInventory(monitor *events.Monitor) {
+	objects, err := monitor.Inventory()
+	if err != nil {
+		return
+	}
+	for _, o := range objects {
+		output.RedactNames(o.Name)
+		output.RedactNamespaces(o.Namespace)
+	}
+}
+
+// redactSources registers the names of the resource's watched sources
+func redactSources(monitor *events.Monitor) {
+	for _, r := range monitor.Sources() {
+		output.RedactNames(r.Name)
+		output.RedactNamespaces(r.Namespace)
+	}
+}
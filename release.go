@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/config"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/history"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/notify"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/tracing"
+)
+
+const releaseUsage = `Usage: flux-enhanced-cli release <command> [options]
+
+Commands:
+  deploy <name>   Reconcile and verify every resource of a release
+  list            List the releases defined in the config file
+`
+
+// releaseCommand runs the "release" subcommand and returns the exit code
+func releaseCommand(args []string) int {
+	fs := flag.NewFlagSet("release", flag.ExitOnError)
+	configPath := fs.String("config", config.DefaultPath(), "Path to the config file defining releases")
+	timeout := fs.Duration("timeout", 5*time.Minute, "Default wait timeout per resource")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	redactNames := fs.Bool("redact-names", false, "Replace resource names, namespaces and URLs in all output with hashed tokens")
+	expandErrors := fs.Bool("expand-errors", false, "Print long condition and event messages in full instead of a preview")
+	ciMode := fs.String("ci-mode", "", "Emit CI workflow commands for annotations and groups (github)")
+	junitReport := fs.String("junit-report", "", "Write a JUnit XML report of the reconciled resources to this path")
+	historyFile := fs.String("history-file", history.DefaultPath(), "File recording every run for the history command (empty disables)")
+	hintAt := fs.String("hint-at", "50,80", "Percentages of the timeout at which to explain what blocks a resource, with suggestions (none disables)")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, releaseUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return 1
+	}
+
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+	if *redactNames {
+		output.EnableRedaction()
+	}
+	if *expandErrors {
+		output.ExpandErrors()
+	}
+	if err := output.SetCIMode(*ciMode); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	switch positional[0] {
+	case "list":
+		names := make([]string, 0, len(cfg.Releases))
+		for name := range cfg.Releases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		rows := make([][]string, 0, len(names))
+		for _, name := range names {
+			release := cfg.Releases[name]
+			count := len(release.Resources)
+			if release.Source != nil {
+				count++
+			}
+			rows = append(rows, []string{name, fmt.Sprintf("%d", count), release.Description})
+		}
+		output.PrintTable([]string{"NAME", "RESOURCES", "DESCRIPTION"}, rows)
+		return 0
+	case "deploy":
+		if len(positional) != 2 {
+			fmt.Fprintf(os.Stderr, "Error: release deploy requires exactly one release name\n")
+			return 1
+		}
+		name := positional[1]
+		release, ok := cfg.Releases[name]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: release '%s' not found in %s\n", name, *configPath)
+			return 1
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		handleInterrupts(cancel)
+
+		flushTracing := initTracing()
+		defer flushTracing()
+
+		rules, err := hintRules(cfg.Hints)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		thresholds, err := events.ParseHintThresholds(*hintAt)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		startedAt := time.Now()
+		results, ok := deployRelease(ctx, name, release, *timeout, *clientOpts, rules, thresholds)
+		runs := runRecords(results)
+		switch {
+		case release.NotifyURL != "" && *historyFile == "":
+			output.PrintWarning("Not notifying: the release outcome is compared with the run history, which --history-file disables")
+		case release.NotifyURL != "":
+			// The release outcome is recorded as a run of its own, the
+			// previous outcome of the next deploy
+			releaseRun := []history.Run{{StartedAt: startedAt, Kind: "release", Name: name, Success: ok, Duration: time.Since(startedAt)}}
+			for _, r := range results {
+				if !r.Success && !r.Skipped {
+					releaseRun[0].Message = fmt.Sprintf("%s/%s: %s", r.Kind, r.Name, r.Message)
+					break
+				}
+			}
+			notifyRuns(*historyFile, &notify.Notifier{URL: release.NotifyURL}, releaseRun)
+			runs = append(runs, releaseRun...)
+		}
+		recordRuns(*historyFile, runs)
+
+		if *junitReport != "" {
+			if err := report.WriteJUnit(*junitReport, "release/"+name, results); err != nil {
+				output.PrintWarning(err.Error())
+			}
+		}
+		if !ok {
+			return 1
+		}
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown release command '%s'\n\n", positional[0])
+		fs.Usage()
+		return 1
+	}
+}
+
+// deployRelease reconciles the release's source and resources in order,
+// stopping at the first failure, then runs the post-check and prints a
+// summary. It returns a result per resource and whether the release succeeded.
+// rules and thresholds configure the hints printed while waiting.
+func deployRelease(ctx context.Context, name string, release config.Release, defaultTimeout time.Duration, clientOpts events.ClientOptions, rules []events.HintRule, thresholds []int) ([]report.Result, bool) {
+	ctx, span := tracing.Start(ctx, "release", "release.name", name)
+	defer span.End()
+
+	startTime := time.Now()
+	resources := release.Resources
+	if release.Source != nil {
+		resources = append([]config.Resource{*release.Source}, resources...)
+	}
+	output.RedactNames(name, clientOpts.Context, clientOpts.As)
+	for _, r := range resources {
+		output.RedactNames(r.Name)
+		output.RedactNamespaces(r.Namespace)
+	}
+
+	output.PrintMain("🚀", fmt.Sprintf("Deploying release %s", name), output.ColorBlue)
+
+	var results []report.Result
+	failed := false
+	for _, r := range resources {
+		namespace := r.Namespace
+		if namespace == "" {
+			namespace = "flux-system"
+		}
+		sourceType := r.SourceType
+		if sourceType == "" {
+			sourceType = "git"
+		}
+
+		if failed {
+			results = append(results, report.Result{
+				Kind: r.Kind, Name: r.Name, Namespace: namespace,
+				Skipped: true, Message: "skipped after an earlier failure",
+			})
+			continue
+		}
+
+		timeout := defaultTimeout
+		if r.Timeout != nil {
+			timeout = r.Timeout.Duration
+		}
+
+		output.PrintMain("▶", fmt.Sprintf("%s/%s (%s)", r.Kind, r.Name, namespace), output.ColorCyan)
+		resourceCtx, cancel := context.WithTimeout(ctx, timeout)
+		result := runReconcile(resourceCtx, reconcileOptions{
+			kind:           r.Kind,
+			name:           r.Name,
+			namespace:      namespace,
+			sourceType:     sourceType,
+			wait:           true,
+			timeout:        timeout,
+			client:         clientOpts,
+			preHook:        r.PreHook,
+			postHook:       r.PostHook,
+			hintRules:      rules,
+			hintThresholds: thresholds,
+		})
+		cancel()
+		results = append(results, result)
+		if !result.Success {
+			failed = true
+		}
+	}
+
+	if !failed && release.PostCheck != nil {
+		result := runPostCheck(ctx, *release.PostCheck)
+		results = append(results, result)
+		failed = !result.Success
+	}
+
+	// Single summary for the whole release
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		status := "Ready"
+		if r.Skipped {
+			status = "Skipped"
+		} else if !r.Success {
+			status = "Failed"
+		}
+		rows = append(rows, []string{r.Kind, r.Name, r.Namespace, status, r.Duration.Round(time.Second).String()})
+	}
+	output.Println()
+	output.PrintTable([]string{"KIND", "NAME", "NAMESPACE", "STATUS", "DURATION"}, rows)
+	output.Println()
+
+	elapsed := time.Since(startTime).Round(time.Second)
+	if failed {
+		output.PrintError(fmt.Sprintf("Release %s failed after %s", name, elapsed))
+		span.SetError(fmt.Errorf("release %s failed", name))
+		return results, false
+	}
+	span.SetOK()
+	output.PrintMain("✅", fmt.Sprintf("Release %s deployed in %s", name, elapsed), output.ColorGreen)
+	return results, true
+}
+
+// runPostCheck polls the post-check URL until it returns the expected status
+// or the check times out.
+func runPostCheck(ctx context.Context, check config.PostCheck) report.Result {
+	timeout := time.Minute
+	if check.Timeout != nil {
+		timeout = check.Timeout.Duration
+	}
+	result := report.Result{Kind: "postcheck", Name: check.URL}
+	startTime := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	output.PrintSublog(fmt.Sprintf("Verifying %s", check.URL))
+	client := &http.Client{Timeout: 10 * time.Second}
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+
+	var lastErr string
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.URL, nil)
+		if err != nil {
+			result.Message = err.Error()
+			result.ExitCode = 1
+			return result
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			ok := resp.StatusCode >= 200 && resp.StatusCode < 300
+			if check.ExpectStatus != 0 {
+				ok = resp.StatusCode == check.ExpectStatus
+			}
+			if ok {
+				result.Success = true
+				result.Duration = time.Since(startTime)
+				output.PrintSublog(fmt.Sprintf("✅ %s returned %s", check.URL, resp.Status))
+				return result
+			}
+			lastErr = fmt.Sprintf("unexpected status %s", resp.Status)
+		} else {
+			lastErr = err.Error()
+		}
+
+		select {
+		case <-ctx.Done():
+			result.Message = fmt.Sprintf("post-check failed: %s", lastErr)
+			result.ExitCode = 1
+			result.Duration = time.Since(startTime)
+			output.PrintError(result.Message)
+			return result
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// waitGroupTitle is the title of the CI group the wait for the resource is
+// printed in
+func waitGroupTitle(opts reconcileOptions) string {
+	return fmt.Sprintf("Waiting for %s/%s", opts.kind, opts.name)
+}
+
+// retriggerOnStall re-issues the reconcile request whenever the monitor has
+// seen no new events or condition changes for opts.forceAfter, until ctx is
+// done. For HelmReleases the re-trigger can force an upgrade. CI groups can't
+// nest, so the wait's group is closed around the re-trigger, which opens its
+// own.
+func retriggerOnStall(ctx context.Context, opts reconcileOptions, monitor *events.Monitor) {
+	out := output.FromContext(ctx)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	retriggerOpts := opts
+	retriggerOpts.force = opts.force || (opts.kind == "helmrelease" && opts.retriggerForce)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			idle := time.Since(monitor.LastActivity())
+			if idle < opts.forceAfter {
+				continue
+			}
+			out.PrintWarning(fmt.Sprintf("No progress for %s, re-triggering reconcile", idle.Round(time.Second)))
+			monitor.MarkActivity()
+			out.EndGroup()
+			token, err := runTrigger(ctx, retriggerOpts)
+			if err != nil && ctx.Err() == nil {
+				out.PrintWarning(fmt.Sprintf("Re-trigger failed: %v", err))
+			}
+			if token != "" {
+				monitor.ExpectHandled(token)
+			}
+			out.StartGroup(waitGroupTitle(opts))
+		}
+	}
+}
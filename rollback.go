@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+const rollbackUsage = `Usage: flux-enhanced-cli rollback helmrelease <name> [--to-revision N] [options]
+
+Rolls a HelmRelease back to the chart version and values of an earlier release
+revision and waits for it to become Ready. The release history is shown first;
+without --to-revision the previous successful revision is used.
+`
+
+// rollbackCommand implements "rollback helmrelease <name>"
+func rollbackCommand(args []string) int {
+	fs := flag.NewFlagSet("rollback", flag.ContinueOnError)
+	namespace := fs.String("namespace", "flux-system", "Namespace")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace (shorthand)")
+	toRevision := fs.Int64("to-revision", 0, "Release revision to roll back to (defaults to the previous successful one)")
+	timeout := fs.Duration("timeout", 5*time.Minute, "Timeout for waiting (e.g., 5m, 1h)")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	redactNames := fs.Bool("redact-names", false, "Replace resource names, namespaces and URLs in all output with hashed tokens")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, rollbackUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(positional) != 2 || resourceKindAliases[positional[0]] != "helmrelease" {
+		fs.Usage()
+		return 1
+	}
+	name := positional[1]
+
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+	if *redactNames {
+		output.EnableRedaction()
+	}
+
+	output.RedactNames(name, clientOpts.Context, clientOpts.As)
+	output.RedactNamespaces(*namespace)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	handleInterrupts(cancel)
+
+	monitor, err := events.NewMonitor(ctx, *clientOpts, "helmrelease", name, *namespace)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	defer monitor.Stop()
+
+	history, err := monitor.HelmHistory(ctx)
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Failed to read release history: %v", err))
+		return 1
+	}
+	printHelmHistory(history)
+	output.Println()
+
+	target, err := rollbackTarget(history, *toRevision)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	output.PrintMain("⏪", fmt.Sprintf("Rolling back helmrelease %s/%s to revision %d (chart %s and its values)",
+		*namespace, name, target.Revision, target.ChartVersion), output.ColorBold)
+	output.PrintWarning("The next apply of the HelmRelease from Git reverts the rollback unless it is made there too")
+	token, err := monitor.RollBackTo(ctx, target, history[0])
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Failed to roll back helmrelease: %v", err))
+		return 1
+	}
+	monitor.ExpectHandled(token)
+
+	go monitor.Watch()
+	output.PrintWaiting("helmrelease", name)
+	if err := monitor.WaitForReady(ctx, *timeout); err != nil {
+		output.PrintError(fmt.Sprintf("Rollback failed or timed out: %v", err))
+		return 1
+	}
+	output.PrintSuccess("helmrelease", name)
+	return 0
+}
+
+// rollbackTarget picks the snapshot to roll back to: the requested revision,
+// or the newest successful one before the current release.
+func rollbackTarget(history []events.HelmReleaseSnapshot, revision int64) (events.HelmReleaseSnapshot, error) {
+	if revision > 0 {
+		for _, s := range history {
+			if s.Revision == revision {
+				return s, nil
+			}
+		}
+		return events.HelmReleaseSnapshot{}, fmt.Errorf("revision %d is not in the release history", revision)
+	}
+	for i, s := range history {
+		if i > 0 && (s.Status == "superseded" || s.Status == "deployed") {
+			return s, nil
+		}
+	}
+	return events.HelmReleaseSnapshot{}, fmt.Errorf("no earlier successful revision to roll back to")
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/config"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/notify"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/schedule"
+)
+
+// runSchedule reconciles the schedule's resources every time it is due,
+// until ctx is done. A run that is still going when the next one is due
+// makes that one be skipped.
+func (s *server) runSchedule(sched config.Schedule) {
+	cron, _ := schedule.Parse(sched.Cron) // validated by config.Load
+	ctx := output.WithPrinter(s.ctx, output.NewPrinter("schedule/"+sched.Name))
+	out := output.FromContext(ctx)
+	var running atomic.Bool
+
+	for {
+		next := cron.Next(time.Now())
+		if next.IsZero() {
+			out.PrintWarning(fmt.Sprintf("Schedule '%s' never fires", sched.Cron))
+			return
+		}
+		if sched.Jitter != nil && sched.Jitter.Duration > 0 {
+			next = next.Add(time.Duration(rand.Int63n(int64(sched.Jitter.Duration))))
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !running.CompareAndSwap(false, true) {
+			out.PrintWarning("Skipping this run, the previous one is still in progress")
+			continue
+		}
+		go func() {
+			defer running.Store(false)
+			results := s.runScheduledResources(ctx, sched)
+			// Notified before the runs are recorded as the previous ones
+			runs := runRecords(results)
+			if sched.NotifyURL != "" && s.historyFile != "" {
+				notifyRuns(s.historyFile, &notify.Notifier{URL: sched.NotifyURL, FailureThreshold: sched.NotifyFailures}, runs)
+			}
+			recordRuns(s.historyFile, runs)
+		}()
+	}
+}
+
+// runScheduledResources reconciles the resources in order, skipping the
+// rest after a failure
+func (s *server) runScheduledResources(ctx context.Context, sched config.Schedule) []report.Result {
+	out := output.FromContext(ctx)
+	out.PrintMain("⏰", fmt.Sprintf("Running schedule %s", sched.Name), output.ColorBlue)
+	startTime := time.Now()
+
+	var results []report.Result
+	var failed []string
+	for _, r := range sched.Resources {
+		namespace := namespaceOrDefault(r.Namespace)
+		if len(failed) > 0 {
+			results = append(results, report.Result{
+				Kind: r.Kind, Name: r.Name, Namespace: namespace,
+				Skipped: true, Message: "skipped after an earlier failure",
+			})
+			continue
+		}
+		sourceType := r.SourceType
+		if sourceType == "" {
+			sourceType = "git"
+		}
+		timeout := s.scheduleTimeout
+		if r.Timeout != nil {
+			timeout = r.Timeout.Duration
+		}
+
+		resourceCtx, cancel := context.WithTimeout(ctx, timeout)
+		result := runReconcile(resourceCtx, reconcileOptions{
+			kind:           r.Kind,
+			name:           r.Name,
+			namespace:      namespace,
+			sourceType:     sourceType,
+			wait:           true,
+			timeout:        timeout,
+			client:         s.clientOpts,
+			force:          sched.Force && r.Kind == "helmrelease",
+			preHook:        r.PreHook,
+			postHook:       r.PostHook,
+			hintRules:      s.hintRules,
+			hintThresholds: s.hintThresholds,
+		})
+		cancel()
+		results = append(results, result)
+		if !result.Success {
+			failed = append(failed, fmt.Sprintf("%s/%s", r.Kind, r.Name))
+		}
+	}
+
+	elapsed := time.Since(startTime).Round(time.Second)
+	if len(failed) > 0 {
+		out.PrintError(fmt.Sprintf("Schedule %s failed after %s: %s", sched.Name, elapsed, strings.Join(failed, ", ")))
+	} else {
+		out.PrintMain("✅", fmt.Sprintf("Schedule %s completed in %s", sched.Name, elapsed), output.ColorGreen)
+	}
+	return results
+}
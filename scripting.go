@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+)
+
+// Exit codes of the scripting helpers
+const (
+	exitReady    = 0
+	exitNotReady = 1
+	exitError    = 2
+)
+
+// isReadyCommand implements "is-ready <kind>/<name>": it checks readiness
+// once and reports it only through the exit status.
+func isReadyCommand(args []string) int {
+	fs := flag.NewFlagSet("is-ready", flag.ContinueOnError)
+	namespace := fs.String("namespace", "flux-system", "Namespace")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace (shorthand)")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: flux-enhanced-cli is-ready <kind>/<name> [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Exits 0 if the resource is Ready, 1 if not, 2 on error.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	monitor, code := scriptingMonitor(fs, args, namespace, clientOpts)
+	if monitor == nil {
+		return code
+	}
+	defer monitor.Stop()
+
+	ready, err := monitor.IsReady()
+	if err != nil {
+		return exitError
+	}
+	if ready {
+		return exitReady
+	}
+	return exitNotReady
+}
+
+// waitUntilReadyCommand implements "wait-until-ready <kind>/<name> --max N":
+// it polls readiness silently for up to N seconds.
+func waitUntilReadyCommand(args []string) int {
+	fs := flag.NewFlagSet("wait-until-ready", flag.ContinueOnError)
+	namespace := fs.String("namespace", "flux-system", "Namespace")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace (shorthand)")
+	maxSeconds := fs.Int("max", 300, "Maximum number of seconds to wait")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: flux-enhanced-cli wait-until-ready <kind>/<name> [--max seconds] [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Exits 0 once the resource is Ready, 1 if it is not Ready in time, 2 on error.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	monitor, code := scriptingMonitor(fs, args, namespace, clientOpts)
+	if monitor == nil {
+		return code
+	}
+	defer monitor.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*maxSeconds)*time.Second)
+	defer cancel()
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if ready, err := monitor.IsReady(); err == nil && ready {
+			return exitReady
+		}
+		select {
+		case <-ctx.Done():
+			return exitNotReady
+		case <-ticker.C:
+		}
+	}
+}
+
+// scriptingMonitor parses the arguments of a scripting helper and builds a
+// monitor for the referenced resource. On failure it returns a nil monitor
+// and the exit code to use.
+func scriptingMonitor(fs *flag.FlagSet, args []string, namespace *string, clientOpts *events.ClientOptions) (*events.Monitor, int) {
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return nil, exitError
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, exitError
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return nil, exitError
+	}
+	kind, name, err := parseResourceRef(positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, exitError
+	}
+
+	monitor, err := events.NewMonitor(context.Background(), *clientOpts, kind, name, *namespace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, exitError
+	}
+	return monitor, exitReady
+}
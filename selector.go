@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/fields"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+)
+
+// selectResources lists the resources of opts.kind matching a label selector.
+// Namespaces are queried concurrently; with no namespaces a single
+// cluster-wide list is made.
+func selectResources(ctx context.Context, opts reconcileOptions, selector string, namespaces []string) ([]reconcileOptions, error) {
+	cluster, err := events.NewCluster(opts.client)
+	if err != nil {
+		return nil, err
+	}
+
+	monitorKind := opts.kind
+	if opts.kind == "source" {
+		monitorKind = opts.sourceType
+	}
+
+	items, err := cluster.ListResources(ctx, events.ListOptions{
+		Kind:          monitorKind,
+		Namespaces:    namespaces,
+		LabelSelector: selector,
+		Progress: func(done, total int) {
+			output.PrintProgress(fmt.Sprintf("Searching namespaces... %d/%d", done, total))
+		},
+	})
+	output.ClearProgress()
+	if err != nil && len(items) == 0 {
+		return nil, err
+	}
+	if err != nil {
+		// Partial results: report the namespaces that could not be listed
+		output.PrintWarning(fmt.Sprintf("Some namespaces could not be searched: %v", err))
+	}
+
+	selected := make([]reconcileOptions, 0, len(items))
+	for _, item := range items {
+		output.RedactNames(item.GetName())
+		output.RedactNamespaces(item.GetNamespace())
+		itemOpts := opts
+		itemOpts.name = item.GetName()
+		itemOpts.namespace = item.GetNamespace()
+		selected = append(selected, itemOpts)
+	}
+	return selected, nil
+}
+
+// findNamespace looks up the namespace of the resource named opts.name across
+// all namespaces. It fails when the name is not found, or is found in more
+// than one namespace, listing the candidates.
+func findNamespace(ctx context.Context, opts reconcileOptions) (string, error) {
+	cluster, err := events.NewCluster(opts.client)
+	if err != nil {
+		return "", err
+	}
+	monitorKind := opts.kind
+	if opts.kind == "source" {
+		monitorKind = opts.sourceType
+	}
+
+	items, err := cluster.ListResources(ctx, events.ListOptions{
+		Kind:          monitorKind,
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", opts.name).String(),
+	})
+	if err != nil {
+		return "", err
+	}
+	switch len(items) {
+	case 0:
+		return "", fmt.Errorf("%s %s not found in any namespace", opts.kind, opts.name)
+	case 1:
+		output.RedactNamespaces(items[0].GetNamespace())
+		return items[0].GetNamespace(), nil
+	}
+	candidates := make([]string, len(items))
+	for i, item := range items {
+		output.RedactNamespaces(item.GetNamespace())
+		candidates[i] = item.GetNamespace()
+	}
+	return "", fmt.Errorf("%s %s exists in several namespaces, pick one with --namespace: %s",
+		opts.kind, opts.name, strings.Join(candidates, ", "))
+}
+
+// runSelected reconciles the selected resources one after another, each with
+// its own timeout. With stopOnError a failure skips the rest.
+func runSelected(ctx context.Context, selected []reconcileOptions, stopOnError bool) []report.Result {
+	results := make([]report.Result, 0, len(selected))
+	failed := ""
+	for _, opts := range selected {
+		if ctx.Err() != nil {
+			results = append(results, skippedResult(opts, "cancelled"))
+			continue
+		}
+		if failed != "" {
+			results = append(results, skippedResult(opts, fmt.Sprintf("skipped after %s failed", failed)))
+			continue
+		}
+		output.PrintMain("▶", fmt.Sprintf("%s %s/%s", opts.kind, opts.namespace, opts.name), output.ColorBold)
+		resourceCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+		result := runReconcile(resourceCtx, opts)
+		cancel()
+		if !result.Success && stopOnError {
+			failed = opts.namespace + "/" + opts.name
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// printSelectionSummary prints the aggregate outcome of a selector run
+func printSelectionSummary(results []report.Result) {
+	rows := make([][]string, 0, len(results))
+	failed := 0
+	for _, r := range results {
+		status := "Ready"
+		switch {
+		case r.Skipped:
+			status = "Skipped"
+			if !r.Success {
+				failed++
+			}
+		case !r.Success:
+			status = "Failed"
+			failed++
+		case r.Flapping:
+			status = "Ready (flapping)"
+		}
+		row := append([]string{r.Namespace + "/" + r.Name, status}, timingCells(r)...)
+		rows = append(rows, append(row, r.Duration.Round(time.Second).String(), r.Message))
+	}
+
+	output.Println()
+	output.PrintTable(append(append([]string{"RESOURCE", "STATUS"}, timingColumns...), "DURATION", "MESSAGE"), rows)
+	output.Println()
+
+	if failed > 0 {
+		output.PrintError(fmt.Sprintf("Reconciliation failed for %d of %d resources", failed, len(results)))
+		return
+	}
+	output.PrintMain("✅", fmt.Sprintf("Reconciled all %d resources", len(results)), output.ColorGreen)
+}
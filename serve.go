@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/config"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/history"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/webhook"
+)
+
+const serveUsage = `Usage: flux-enhanced-cli serve [options]
+
+Runs an HTTP API to trigger and observe reconciles:
+
+  POST /reconcile                          {"kind": "ks", "namespace": "apps", "name": "web"}
+  GET  /status/<kind>/<namespace>/<name>   optionally ?token=<token from POST /reconcile>
+  GET  /healthz
+  POST /webhook/github, /webhook/gitlab     with --webhook-secret
+
+Resource status is served from shared informer caches. With --token (or
+FLUX_ENHANCED_CLI_TOKEN) requests must send "Authorization: Bearer <token>";
+without one the API only listens on localhost.
+
+Push webhooks are mapped to a GitRepository and its Kustomizations by the
+"webhooks" routes of the config file. Each is reconciled and waited for, and
+the outcome is posted as a commit status when GITHUB_TOKEN or GITLAB_TOKEN is
+set.
+
+The "schedules" of the config file reconcile resources on cron schedules
+(e.g. nightly forced HelmRelease upgrades), recording each run for history.
+
+With --tail-notifications the notification-controller's log lines about
+events are printed, to verify that alerts are actually dispatched.
+`
+
+// servedKinds are the monitor kinds the API accepts
+var servedKinds = []string{"kustomization", "helmrelease", "git", "oci", "bucket"}
+
+// reconcileRequest is the body of POST /reconcile
+type reconcileRequest struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// WithSource also requests a reconcile of a Kustomization's or
+	// HelmRelease's source (without waiting for it)
+	WithSource bool `json:"withSource"`
+	// Force upgrades a HelmRelease even when nothing changed
+	Force bool `json:"force"`
+}
+
+// reconcileResponse is the answer to POST /reconcile
+type reconcileResponse struct {
+	Token  string `json:"token"`
+	Status string `json:"status"`
+}
+
+// statusResponse is the answer to GET /status
+type statusResponse struct {
+	*events.ResourceStatus
+	// Handled is set when a token was given: whether the resource has
+	// handled that reconcile request
+	Handled *bool `json:"handled,omitempty"`
+}
+
+type server struct {
+	ctx        context.Context
+	cluster    *events.Cluster
+	informers  *events.Informers
+	clientOpts events.ClientOptions
+	token      string
+	// ready is set once the informer caches have synced
+	ready    atomic.Bool
+	webhooks *webhookReceiver
+	// historyFile records scheduled runs
+	historyFile     string
+	scheduleTimeout time.Duration
+	// hintRules and hintThresholds configure the hints of scheduled runs
+	hintRules      []events.HintRule
+	hintThresholds []int
+}
+
+// serveCommand implements "serve"
+func serveCommand(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", "", "Address to listen on (defaults to :8080, or localhost:8080 without a token)")
+	namespace := fs.String("namespace", "", "Only serve resources in this namespace (defaults to all namespaces)")
+	fs.StringVar(namespace, "n", "", "Namespace (shorthand)")
+	token := fs.String("token", os.Getenv("FLUX_ENHANCED_CLI_TOKEN"), "Bearer token required from clients (defaults to $FLUX_ENHANCED_CLI_TOKEN)")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	configPath := fs.String("config", config.DefaultPath(), "Path to the config file defining webhook routes")
+	webhookSecret := fs.String("webhook-secret", os.Getenv("FLUX_ENHANCED_CLI_WEBHOOK_SECRET"), "Secret validating Git push webhooks; enables /webhook/github and /webhook/gitlab (defaults to $FLUX_ENHANCED_CLI_WEBHOOK_SECRET)")
+	webhookTimeout := fs.Duration("webhook-timeout", 5*time.Minute, "Timeout for each reconcile triggered by a push")
+	scheduleTimeout := fs.Duration("schedule-timeout", 5*time.Minute, "Default timeout for each reconcile of a schedule")
+	historyFile := fs.String("history-file", history.DefaultPath(), "File recording scheduled runs for the history command (empty disables)")
+	githubURL := fs.String("github-url", "https://api.github.com", "GitHub API URL for commit statuses")
+	gitlabURL := fs.String("gitlab-url", "https://gitlab.com", "GitLab URL for commit statuses")
+	notifications := fs.Bool("tail-notifications", false, "Print the notification-controller's log lines about events it receives and dispatches")
+	fluxNamespace := fs.String("flux-namespace", "flux-system", "Namespace of the Flux controllers, for --tail-notifications")
+	hintAt := fs.String("hint-at", "50,80", "Percentages of the timeout at which scheduled runs explain what blocks a resource (none disables)")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, serveUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+	switch {
+	case *token != "" && *listen == "":
+		*listen = ":8080"
+	case *listen == "":
+		*listen = "localhost:8080"
+		output.PrintWarning("No --token set, the API accepts unauthenticated requests and only listens on localhost")
+	case !loopbackAddress(*listen):
+		fmt.Fprintf(os.Stderr, "Error: listening on %s without --token would accept unauthenticated requests from the network, set --token or listen on localhost\n", *listen)
+		return 1
+	default:
+		output.PrintWarning("No --token set, the API accepts unauthenticated requests")
+	}
+	// Webhooks need routes; otherwise the config only adds schedules
+	cfg := &config.Config{}
+	if _, err := os.Stat(*configPath); err == nil || *webhookSecret != "" {
+		if cfg, err = config.Load(*configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+	rules, err := hintRules(cfg.Hints)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	thresholds, err := events.ParseHintThresholds(*hintAt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	var receiver *webhookReceiver
+	if *webhookSecret != "" {
+		if len(cfg.Webhooks) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: --webhook-secret is set but %s defines no webhooks\n", *configPath)
+			return 1
+		}
+		receiver = newWebhookReceiver(cfg.Webhooks, *webhookSecret, *webhookTimeout, webhook.StatusReporter{
+			GitHubToken: os.Getenv("GITHUB_TOKEN"),
+			GitHubURL:   *githubURL,
+			GitLabToken: os.Getenv("GITLAB_TOKEN"),
+			GitLabURL:   *gitlabURL,
+		})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cluster, err := events.NewCluster(*clientOpts)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	var kinds []string
+	for _, kind := range servedKinds {
+		if _, err := cluster.ResolveKind(kind); err != nil {
+			output.PrintWarning(fmt.Sprintf("Not serving %s: %v", kind, err))
+			continue
+		}
+		kinds = append(kinds, kind)
+	}
+	if len(kinds) == 0 {
+		output.PrintError("None of the Flux resource kinds are served by the cluster")
+		return 1
+	}
+	informers, err := cluster.NewInformers(events.InformerOptions{Namespace: *namespace}, kinds)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+
+	s := &server{
+		ctx:             ctx,
+		cluster:         cluster,
+		informers:       informers,
+		clientOpts:      *clientOpts,
+		token:           *token,
+		webhooks:        receiver,
+		historyFile:     *historyFile,
+		scheduleTimeout: *scheduleTimeout,
+		hintRules:       rules,
+		hintThresholds:  thresholds,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/reconcile", s.authorized(s.synced(s.handleReconcile)))
+	mux.HandleFunc("/status/", s.authorized(s.synced(s.handleStatus)))
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	if receiver != nil {
+		// Deliveries carry their own signature instead of the bearer token
+		mux.HandleFunc("/webhook/github", s.handleWebhook(webhook.ParseGitHub))
+		mux.HandleFunc("/webhook/gitlab", s.handleWebhook(webhook.ParseGitLab))
+	}
+	httpServer := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- httpServer.ListenAndServe() }()
+	output.PrintMain("🛰️", fmt.Sprintf("Listening on %s", *listen), output.ColorCyan)
+
+	if err := informers.Start(ctx); err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	s.ready.Store(true)
+	output.PrintStatus(fmt.Sprintf("Caches synced for %s", strings.Join(kinds, ", ")))
+	for _, sched := range cfg.Schedules {
+		output.PrintStatus(fmt.Sprintf("Schedule %s: %s", sched.Name, sched.Cron))
+		if sched.NotifyURL != "" && *historyFile == "" {
+			output.PrintWarning(fmt.Sprintf("Schedule %s won't notify: outcomes are compared with the run history, which --history-file disables", sched.Name))
+		}
+		go s.runSchedule(sched)
+	}
+	if *notifications {
+		go tailNotifications(ctx, cluster, *fluxNamespace)
+	}
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			output.PrintError(err.Error())
+			return 1
+		}
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		output.PrintWarning(fmt.Sprintf("Shutdown: %v", err))
+	}
+	return 0
+}
+
+// authorized requires the bearer token, when one is configured
+func (s *server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) != 1 {
+				writeJSONError(w, http.StatusUnauthorized, "invalid or missing bearer token")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// synced answers 503 until the informer caches have synced, since a resource
+// missing from them would be reported as not found
+func (s *server) synced(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.ready.Load() {
+			writeJSONError(w, http.StatusServiceUnavailable, "caches not synced")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if !s.ready.Load() {
+		writeJSONError(w, http.StatusServiceUnavailable, "caches not synced")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *server) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	var req reconcileRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	kind, ok := s.servedKind(req.Kind)
+	if !ok || req.Name == "" || req.Namespace == "" {
+		writeJSONError(w, http.StatusBadRequest, "kind, namespace and name are required")
+		return
+	}
+	if req.Force && kind != "helmrelease" {
+		writeJSONError(w, http.StatusBadRequest, "force is only supported for helmreleases")
+		return
+	}
+	if _, err := s.informers.Status(kind, req.Namespace, req.Name); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if req.WithSource && (kind == "kustomization" || kind == "helmrelease") {
+		source, err := s.cluster.Source(r.Context(), kind, req.Namespace, req.Name)
+		if err == nil {
+			_, err = s.cluster.RequestReconcile(r.Context(), source.Kind, source.Namespace, source.Name, false)
+		}
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, fmt.Sprintf("failed to reconcile the source: %v", err))
+			return
+		}
+	}
+	token, err := s.cluster.RequestReconcile(r.Context(), kind, req.Namespace, req.Name, req.Force)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+	output.PrintStatus(fmt.Sprintf("Reconcile requested for %s %s/%s", kind, req.Namespace, req.Name))
+	writeJSON(w, http.StatusAccepted, reconcileResponse{
+		Token:  token,
+		Status: fmt.Sprintf("/status/%s/%s/%s?token=%s", kind, req.Namespace, req.Name, url.QueryEscape(token)),
+	})
+}
+
+func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/status/"), "/")
+	if len(parts) != 3 {
+		writeJSONError(w, http.StatusNotFound, "expected /status/<kind>/<namespace>/<name>")
+		return
+	}
+	kind, ok := s.servedKind(parts[0])
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("unsupported kind '%s'", parts[0]))
+		return
+	}
+	status, err := s.informers.Status(kind, parts[1], parts[2])
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	resp := statusResponse{ResourceStatus: status}
+	if token := r.URL.Query().Get("token"); token != "" {
+		handled := status.LastHandledReconcileAt == token
+		resp.Handled = &handled
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// loopbackAddress reports whether a listen address only accepts connections
+// from this host
+func loopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// servedKind maps a kind or alias (ks, hr, gitrepository, ...) to a served
+// monitor kind
+func (s *server) servedKind(kind string) (string, bool) {
+	kind = strings.ToLower(kind)
+	if alias, ok := resourceKindAliases[kind]; ok {
+		kind = alias
+	}
+	return kind, s.informers.Serves(kind)
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeJSONError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
+
+// writeStatusError answers with the status code of a Kubernetes API error
+func writeStatusError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	var status apierrors.APIStatus
+	if errors.As(err, &status) && status.Status().Code != 0 {
+		code = int(status.Status().Code)
+	}
+	writeJSONError(w, code, err.Error())
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/history"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+const statsUsage = `Usage: flux-enhanced-cli stats [<kind> [<name>]] [options]
+
+Aggregates the recorded runs (see "history") over a time window: p50/p95
+durations of successful runs, failure rates, and the slowest resources with
+the trend of their durations.
+`
+
+// statsCommand implements "stats [<kind> [<name>]]"
+func statsCommand(args []string) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	since := fs.Duration("since", 7*24*time.Hour, "Only aggregate runs started within this window")
+	top := fs.Int("top", 10, "Number of slowest resources listed (0 for all)")
+	namespace := fs.String("namespace", "", "Only aggregate runs of resources in this namespace")
+	fs.StringVar(namespace, "n", "", "Namespace (shorthand)")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	redactNames := fs.Bool("redact-names", false, "Replace resource names, namespaces and URLs in all output with hashed tokens")
+	historyFile := fs.String("history-file", history.DefaultPath(), "File the runs are recorded in")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, statsUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+	if *redactNames {
+		output.EnableRedaction()
+	}
+
+	filter := history.Filter{Namespace: *namespace, Since: time.Now().Add(-*since)}
+	if len(positional) > 0 {
+		if filter.Kind, _, filter.Name, err = parseResourceArgs(positional); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fs.Usage()
+			return 1
+		}
+	}
+
+	runs, err := history.Load(*historyFile, filter)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	if len(runs) == 0 {
+		output.PrintStatus(fmt.Sprintf("No recorded runs in the last %s", formatWindow(*since)))
+		return 0
+	}
+
+	resources, total := history.Summarize(runs)
+	output.PrintMain("📊", fmt.Sprintf("%d runs of %d resources in the last %s: p50 %s, p95 %s, %.0f%% failed",
+		total.Runs, len(resources), formatWindow(*since), total.P50.Round(time.Second), total.P95.Round(time.Second),
+		100*total.FailureRate()), output.ColorCyan)
+
+	if *top > 0 && len(resources) > *top {
+		resources = resources[:*top]
+	}
+	rows := make([][]string, 0, len(resources))
+	for _, s := range resources {
+		output.RedactNames(s.Name, s.Context)
+		output.RedactNamespaces(s.Namespace)
+		resource := fmt.Sprintf("%s/%s/%s", s.Kind, s.Namespace, s.Name)
+		if s.Context != "" {
+			resource = s.Context + ":" + resource
+		}
+		trend := ""
+		if s.HasTrend {
+			trend = fmt.Sprintf("%+.0f%%", 100*s.Trend)
+		}
+		rows = append(rows, []string{
+			resource,
+			fmt.Sprint(s.Runs),
+			fmt.Sprintf("%.0f%%", 100*s.FailureRate()),
+			s.P50.Round(time.Second).String(),
+			s.P95.Round(time.Second).String(),
+			s.Max.Round(time.Second).String(),
+			trend,
+		})
+	}
+	output.PrintTable([]string{"RESOURCE", "RUNS", "FAILED", "P50", "P95", "MAX", "TREND"}, rows)
+	return 0
+}
+
+// formatWindow prints whole-day windows in days ("7d") and others as durations
+func formatWindow(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+)
+
+// printRunSummary prints the timing breakdown of a single reconcile: when it
+// was triggered, how long the controller took to pick it up and to become
+// ready, the warnings seen and the final revision
+func printRunSummary(r report.Result) {
+	if r.TriggeredAt.IsZero() {
+		return
+	}
+	rows := [][]string{
+		{"Triggered", r.TriggeredAt.Format("15:04:05")},
+		{"Acknowledged in", summaryDuration(r.AcknowledgedAfter)},
+		{"Ready in", summaryDuration(r.ReadyAfter)},
+		{"Warning events", strconv.Itoa(len(r.WarningEvents))},
+		{"Revision", orDash(r.Revision)},
+		{"Total duration", r.Duration.Round(time.Second).String()},
+	}
+	output.Println()
+	output.PrintMain("📋", "Summary", output.ColorBold)
+	for _, row := range rows {
+		output.PrintSublog(fmt.Sprintf("%-16s %s", row[0]+":", row[1]))
+	}
+}
+
+// timingColumns are the summary table columns for the timing breakdown
+var timingColumns = []string{"ACK", "READY", "WARNINGS"}
+
+// timingCells returns the timing breakdown of r for timingColumns
+func timingCells(r report.Result) []string {
+	return []string{summaryDuration(r.AcknowledgedAfter), summaryDuration(r.ReadyAfter), strconv.Itoa(len(r.WarningEvents))}
+}
+
+// summaryDuration formats a duration for summaries, "-" when not measured
+func summaryDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	if d < 10*time.Second {
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// syncFluxCommand implements "sync-flux": it reconciles the GitRepository and
+// Kustomization that manage the Flux installation itself, guarded by
+// controller health and CRD checks before and after.
+func syncFluxCommand(args []string) int {
+	fs := flag.NewFlagSet("sync-flux", flag.ExitOnError)
+	name := fs.String("name", "flux-system", "Name of the self-managing GitRepository and Kustomization")
+	namespace := fs.String("namespace", "flux-system", "Namespace of the Flux installation")
+	timeout := fs.Duration("timeout", 5*time.Minute, "Timeout for each reconcile and for controllers to become healthy")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	redactNames := fs.Bool("redact-names", false, "Replace resource names, namespaces and URLs in all output with hashed tokens")
+	expandErrors := fs.Bool("expand-errors", false, "Print long condition and event messages in full instead of a preview")
+	ciMode := fs.String("ci-mode", "", "Emit CI workflow commands for annotations and groups (github)")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: flux-enhanced-cli sync-flux [options]\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+	if _, err := parseArgs(fs, args); err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+	if *redactNames {
+		output.EnableRedaction()
+		output.RedactNames(*name, clientOpts.Context, clientOpts.As)
+		output.RedactNamespaces(*namespace)
+	}
+	if *expandErrors {
+		output.ExpandErrors()
+	}
+	if err := output.SetCIMode(*ciMode); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handleInterrupts(cancel)
+
+	flushTracing := initTracing()
+	defer flushTracing()
+
+	cluster, err := events.NewCluster(*clientOpts)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+
+	// Preflight: never touch Flux while it is already unhealthy
+	output.PrintMain("🔍", "Checking Flux installation before sync", output.ColorBlue)
+	if err := checkFluxHealth(ctx, cluster, *namespace, true); err != nil {
+		output.PrintError(fmt.Sprintf("Preflight failed, not reconciling Flux: %v", err))
+		return 1
+	}
+
+	steps := []reconcileOptions{
+		{kind: "source", sourceType: "git", name: *name, namespace: *namespace, wait: true, timeout: *timeout, client: *clientOpts},
+		{kind: "kustomization", name: *name, namespace: *namespace, wait: true, timeout: *timeout, client: *clientOpts},
+	}
+	for _, step := range steps {
+		output.PrintMain("▶", fmt.Sprintf("%s/%s", step.kind, step.name), output.ColorCyan)
+		stepCtx, stepCancel := context.WithTimeout(ctx, step.timeout)
+		result := runReconcile(stepCtx, step)
+		stepCancel()
+		if !result.Success {
+			return result.ExitCode
+		}
+	}
+
+	// Reconciling Flux may upgrade the controllers; wait for them to settle
+	output.PrintMain("🔍", "Checking Flux installation after sync", output.ColorBlue)
+	if err := waitForFluxHealth(ctx, cluster, *namespace, *timeout); err != nil {
+		output.PrintError(fmt.Sprintf("Flux is unhealthy after sync: %v", err))
+		return 1
+	}
+
+	output.PrintMain("✅", "Flux synced and healthy", output.ColorGreen)
+	return 0
+}
+
+// checkFluxHealth verifies that the Flux CRDs are served and every controller
+// Deployment is fully rolled out, printing the result of each check when
+// verbose is set.
+func checkFluxHealth(ctx context.Context, cluster *events.Cluster, namespace string, verbose bool) error {
+	missing, err := cluster.MissingCRDs()
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("CRDs not available: %s", strings.Join(missing, ", "))
+	}
+	if verbose {
+		output.PrintSublog("Flux CRDs are served")
+		if apis, err := cluster.ServedAPIVersions(); err == nil {
+			for _, w := range compatWarnings("", nil, apis) {
+				output.PrintWarning(w)
+			}
+		}
+	}
+
+	controllers, err := cluster.ControllerHealth(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	if len(controllers) == 0 {
+		return fmt.Errorf("no controllers found in namespace %s", namespace)
+	}
+
+	var unhealthy []string
+	for _, c := range controllers {
+		if !c.Ready {
+			unhealthy = append(unhealthy, c.Name)
+		}
+		if !verbose {
+			continue
+		}
+		if c.Ready {
+			output.PrintSublog(fmt.Sprintf("✅ %s (%d/%d)", c.Name, c.Available, c.Desired))
+		} else {
+			output.PrintWarning(fmt.Sprintf("%s: %s", c.Name, c.Message))
+		}
+	}
+	if len(unhealthy) > 0 {
+		return fmt.Errorf("controllers not healthy: %s", strings.Join(unhealthy, ", "))
+	}
+	return nil
+}
+
+// waitForFluxHealth polls checkFluxHealth until it passes or timeout elapses
+func waitForFluxHealth(ctx context.Context, cluster *events.Cluster, namespace string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := checkFluxHealth(ctx, cluster, namespace, false); err == nil {
+			return checkFluxHealth(ctx, cluster, namespace, true)
+		}
+		select {
+		case <-ctx.Done():
+			return checkFluxHealth(context.Background(), cluster, namespace, true)
+		case <-ticker.C:
+			output.PrintStatus("Waiting for controllers to become healthy...")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// printTerraformStatus prints the plan a Terraform applied and the names of
+// its outputs. Output values are not printed since they may be sensitive.
+func printTerraformStatus(ctx context.Context, monitor *events.Monitor) {
+	out := output.FromContext(ctx)
+	status, err := monitor.TerraformStatus()
+	if err != nil {
+		return
+	}
+	if status.Plan != "" {
+		out.PrintSublog(fmt.Sprintf("📋 %s", status.Plan))
+	}
+	if len(status.Outputs) == 0 {
+		return
+	}
+	outputs := fmt.Sprintf("📤 Outputs: %s", strings.Join(status.Outputs, ", "))
+	if status.OutputsSecret != "" {
+		outputs += fmt.Sprintf(" (written to Secret %s)", status.OutputsSecret)
+	}
+	out.PrintSublog(outputs)
+}
+
+// reportPendingPlan explains a Terraform wait that ended on a plan awaiting
+// manual approval
+func reportPendingPlan(ctx context.Context, monitor *events.Monitor) {
+	status, err := monitor.TerraformStatus()
+	if err != nil || !status.AwaitingApproval {
+		return
+	}
+	kind := "Plan"
+	if status.DestroyPlan {
+		kind = "Destroy plan"
+	}
+	output.FromContext(ctx).PrintWarning(fmt.Sprintf("%s %s awaits approval: set spec.approvePlan to %q to apply it", kind, status.PendingPlan, status.PendingPlan))
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/tracing"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/webhook"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Backoff between trigger retries: 2s, 4s, 8s, ... up to 30s
+const (
+	retryBaseDelay = 2 * time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+// triggerError is a failed trigger with the exit code to report
+type triggerError struct {
+	code    int
+	message string
+	// transient failures (timeouts, server errors, refused connections) may
+	// succeed when retried
+	transient bool
+}
+
+func (e *triggerError) Error() string { return e.message }
+
+// transientMessages are the parts of error messages, of flux or the API
+// client, that mark a failure that may pass when retried
+var transientMessages = []string{
+	"timeout",
+	"timed out",
+	"connection refused",
+	"connection reset",
+	"no route to host",
+	"unexpected eof",
+	"too many requests",
+	"internal error",
+	"service unavailable",
+	"the server is currently unable",
+}
+
+// transientError reports whether a failed request may succeed when retried:
+// timeouts, throttling, server errors and failed connections. A resource that
+// is not found, forbidden or suspended, or invalid arguments, fail the same
+// way again.
+func transientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsInternalError(err) || apierrors.IsServiceUnavailable(err) || apierrors.IsUnexpectedServerError(err) {
+		return true
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return status.Status().Code >= 500
+	}
+	var response *webhook.ResponseError
+	if errors.As(err, &response) {
+		return response.StatusCode >= 500 || response.StatusCode == 429
+	}
+	return transientMessage(err.Error())
+}
+
+// transientMessage reports whether an error message, e.g. one flux logged,
+// describes a failure that may pass when retried
+func transientMessage(message string) bool {
+	message = strings.ToLower(message)
+	for _, m := range transientMessages {
+		if strings.Contains(message, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// fluxReconcileArgs builds the "flux reconcile" command line for opts
+func fluxReconcileArgs(opts reconcileOptions) []string {
+	var args []string
+	if opts.kind == "source" {
+		// For source, we need "flux reconcile source <type> <name>"
+		args = []string{"flux", "reconcile", "source", opts.sourceType, opts.name, "-n", opts.namespace}
+	} else {
+		args = []string{"flux", "reconcile", opts.kind, opts.name, "-n", opts.namespace}
+		if (opts.kind == "kustomization" || opts.kind == "helmrelease") && !opts.skipSource {
+			args = append(args, "--with-source")
+		}
+	}
+	if opts.triggerTimeout > 0 {
+		// flux reconcile is stopped once the request was made when
+		// waiting, otherwise this bounds its wait for the reconcile
+		args = append(args, "--timeout", opts.triggerTimeout.String())
+	}
+	return append(args, opts.client.FluxArgs()...)
+}
+
+// nativeTrigger reports whether the reconcile is requested by annotating the
+// resource instead of running "flux reconcile", which may not be installed
+func nativeTrigger(opts reconcileOptions) bool {
+	return nativeOnly(opts) || fluxMissing()
+}
+
+// nativeOnly reports whether the reconcile needs the native trigger, since
+// "flux reconcile" can't force upgrades or reconcile kinds outside Flux
+func nativeOnly(opts reconcileOptions) bool {
+	return opts.force || opts.client.InCluster || opts.gvr != nil || opts.kind == "terraform"
+}
+
+// fluxMissing reports whether the flux binary is not in PATH, so reconciles
+// are requested natively instead. It is checked once.
+var fluxMissing = sync.OnceValue(func() bool {
+	_, err := exec.LookPath("flux")
+	return err != nil
+})
+
+// triggerWithRetries runs the trigger, retrying transient failures up to
+// opts.retries times with exponential backoff. Cancellation and failures that
+// would repeat (not found, forbidden, bad arguments) are never retried.
+func triggerWithRetries(ctx context.Context, opts reconcileOptions) (string, error) {
+	out := output.FromContext(ctx)
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		token, err := runTrigger(ctx, opts)
+		if err == nil || attempt >= opts.retries || ctx.Err() != nil {
+			return token, err
+		}
+		var triggerErr *triggerError
+		if errors.As(err, &triggerErr) && !triggerErr.transient {
+			if attempt == 0 {
+				out.PrintStatus("Not retrying the trigger, the failure isn't transient")
+			}
+			return token, err
+		}
+
+		out.PrintWarning(fmt.Sprintf("Trigger failed (attempt %d/%d): %v, retrying in %s",
+			attempt+1, opts.retries+1, err, delay))
+		select {
+		case <-ctx.Done():
+			return "", err
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+}
+
+// runTrigger requests the reconcile once, within opts.triggerTimeout when
+// set, so a hung API call or kubeconfig exec plugin fails the attempt instead
+// of stalling the run
+func runTrigger(ctx context.Context, opts reconcileOptions) (string, error) {
+	if opts.triggerTimeout <= 0 {
+		return requestReconcile(ctx, opts)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, opts.triggerTimeout)
+	defer cancel()
+	token, err := requestReconcile(attemptCtx, opts)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		message := fmt.Sprintf("trigger timed out after %s (--trigger-timeout)", opts.triggerTimeout)
+		output.FromContext(ctx).PrintError(message)
+		return "", &triggerError{code: 1, message: message, transient: true}
+	}
+	return token, err
+}
+
+// requestReconcile requests the reconcile and returns the request token. It
+// runs "flux reconcile", streaming its output with the flux log lines and
+// Kubernetes client warnings re-rendered through pkg/output, except when
+// nativeTrigger applies: then the resource is annotated directly. With --via-receiver the Receiver's webhook is
+// called instead.
+func requestReconcile(ctx context.Context, opts reconcileOptions) (string, error) {
+	if opts.viaReceiver != "" {
+		return runReceiverTrigger(ctx, opts)
+	}
+	if nativeTrigger(opts) {
+		return runNativeTrigger(ctx, opts)
+	}
+	out := output.FromContext(ctx)
+	args := fluxReconcileArgs(opts)
+
+	// flux reconcile goes on to wait for the reconcile, which is left to
+	// WaitForReady under --wait-timeout when waiting: flux is stopped once it
+	// logs that it waits for the resource itself, past the annotation and
+	// the source fetched with --with-source
+	fluxCtx, stopFlux := context.WithCancel(ctx)
+	defer stopFlux()
+	var requested atomic.Bool
+	kind := opts.kind
+	if kind == "source" {
+		kind = opts.sourceType
+	}
+	waiting := ""
+	if apiKind := events.ControllerKind(kind); opts.wait && apiKind != "" {
+		waiting = fmt.Sprintf("waiting for %s reconciliation", apiKind)
+	}
+	// The error lines flux logs tell whether a failure is worth retrying
+	var fluxErrors []string
+	onFluxLine := func(line string) {
+		if waiting != "" && strings.Contains(line, waiting) {
+			requested.Store(true)
+			stopFlux()
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "✗") {
+			fluxErrors = append(fluxErrors, line)
+		}
+	}
+	cmd := command(fluxCtx, args[0], args[1:]...)
+
+	// Run command and stream output
+	_, triggerSpan := tracing.Start(ctx, "trigger", "flux.command", strings.Join(cmd.Args, " "))
+	defer triggerSpan.End()
+	out.StartGroup(strings.Join(cmd.Args, " "))
+	defer out.EndGroup()
+	out.PrintCommand(cmd.Args...)
+	stdout := newFluxLogWriter(out)
+	cmd.Stdout = stdout
+
+	// Intercept stderr to format warnings nicely. The pipe is closed only
+	// after Wait returned, so everything flux wrote is rendered before the
+	// outcome is handled, also when it was stopped on cancel.
+	stderrReader, stderrWriter := io.Pipe()
+	cmd.Stderr = stderrWriter
+
+	// Start the command
+	if err := cmd.Start(); err != nil {
+		triggerSpan.SetError(err)
+		fmt.Fprintf(out.Stderr(), "Error starting flux: %v\n", err)
+		return "", &triggerError{code: 1, message: err.Error()}
+	}
+
+	// Process stderr in a goroutine with WaitGroup to ensure completion
+	var stderrWg sync.WaitGroup
+	stderrWg.Add(1)
+	go processStderr(stderrReader, out, opts.client.SuppressWarnings, onFluxLine, &stderrWg)
+
+	// Wait for command to complete; on cancel flux gets SIGTERM and
+	// childGracePeriod to exit before it is killed
+	cmdErr := cmd.Wait()
+	stderrWriter.Close()
+
+	// Wait for stderr processing to complete before handling errors
+	stderrWg.Wait()
+	stdout.Flush()
+	if requested.Load() && ctx.Err() == nil {
+		cmdErr = nil
+	}
+	triggerSpan.SetError(cmdErr)
+
+	if cmdErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(cmdErr, &exitErr) {
+			return "", &triggerError{
+				code:      exitErr.ExitCode(),
+				message:   fmt.Sprintf("flux reconcile exited with code %d", exitErr.ExitCode()),
+				transient: transientMessage(strings.Join(fluxErrors, "\n")),
+			}
+		}
+		fmt.Fprintf(out.Stderr(), "Error running flux: %v\n", cmdErr)
+		return "", &triggerError{code: 1, message: cmdErr.Error()}
+	}
+	if !requested.Load() {
+		return "", nil
+	}
+	// flux was stopped before it saw the reconcile through, so WaitForReady
+	// needs the request token to tell it from the previous reconcile
+	token, err := requestedToken(ctx, opts.client, kind, opts.namespace, opts.name)
+	if err != nil {
+		message := fmt.Sprintf("failed to read the reconcile request of %s: %v", kind, err)
+		triggerSpan.SetError(err)
+		out.PrintError(message)
+		return "", &triggerError{code: 1, message: message, transient: transientError(err)}
+	}
+	return token, nil
+}
+
+// requestedToken reads back the requestedAt annotation flux set on the
+// resource
+func requestedToken(ctx context.Context, clientOpts events.ClientOptions, kind, namespace, name string) (string, error) {
+	cluster, err := events.NewCluster(clientOpts)
+	if err != nil {
+		return "", err
+	}
+	obj, err := cluster.GetResource(ctx, kind, namespace, name)
+	if err != nil {
+		return "", err
+	}
+	token := obj.GetAnnotations()[events.RequestedAtAnnotation]
+	if token == "" {
+		return "", fmt.Errorf("%s/%s has no %s annotation", namespace, name, events.RequestedAtAnnotation)
+	}
+	return token, nil
+}
+
+// runNativeTrigger sets the requestedAt annotation (and forceAt with
+// opts.force, so helm-controller upgrades the release even when chart and
+// values are unchanged) without the flux binary
+func runNativeTrigger(ctx context.Context, opts reconcileOptions) (string, error) {
+	out := output.FromContext(ctx)
+	_, span := tracing.Start(ctx, "trigger", "flux.force", fmt.Sprint(opts.force))
+	defer span.End()
+
+	kind := opts.kind
+	if kind == "source" {
+		kind = opts.sourceType
+	}
+	cluster, err := events.NewCluster(opts.client)
+	if err != nil {
+		span.SetError(err)
+		return "", &triggerError{code: 1, message: err.Error()}
+	}
+	if !opts.skipSource && (kind == "kustomization" || kind == "helmrelease" || kind == "terraform") {
+		if err := reconcileSourceNative(ctx, cluster, opts); err != nil {
+			span.SetError(err)
+			return "", &triggerError{code: 1, message: fmt.Sprintf("failed to reconcile source: %v", err), transient: transientError(err)}
+		}
+	}
+
+	var token string
+	if opts.gvr != nil {
+		token, err = cluster.RequestReconcileGVR(ctx, *opts.gvr, opts.namespace, opts.name)
+	} else {
+		token, err = cluster.RequestReconcile(ctx, kind, opts.namespace, opts.name, opts.force)
+	}
+	if err != nil {
+		span.SetError(err)
+		return "", &triggerError{code: 1, message: fmt.Sprintf("failed to annotate %s: %v", kind, err), transient: transientError(err)}
+	}
+	annotations := []string{events.RequestedAtAnnotation + "=" + token}
+	if opts.force {
+		annotations = append(annotations, events.ForceAtAnnotation+"="+token)
+	}
+	out.PrintCommand(append([]string{"annotate", kind + "/" + opts.name, "-n", opts.namespace}, annotations...)...)
+	return token, nil
+}
+
+// reconcileSourceNative requests a reconcile of the resource's source through
+// the annotation and waits until the source has handled it, as
+// "flux reconcile --with-source" does
+func reconcileSourceNative(ctx context.Context, cluster *events.Cluster, opts reconcileOptions) error {
+	out := output.FromContext(ctx)
+	source, err := cluster.Source(ctx, opts.kind, opts.namespace, opts.name)
+	if err != nil {
+		return err
+	}
+	token, err := cluster.RequestReconcile(ctx, source.Kind, source.Namespace, source.Name, false)
+	if err != nil {
+		return err
+	}
+	out.PrintCommand("annotate", source.Kind+"/"+source.Name, "-n", source.Namespace, events.RequestedAtAnnotation+"="+token)
+	return cluster.WaitHandled(ctx, source.Kind, source.Namespace, source.Name, token)
+}
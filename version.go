@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+const versionUsage = `Usage: flux-enhanced-cli version [--full] [options]
+
+Prints the version of this CLI. With --full it also reports the flux binary,
+the Flux controllers installed in the cluster and the API versions served for
+the Flux resources, with a warning for everything this CLI doesn't expect.
+`
+
+// versionCommand implements "version"
+func versionCommand(args []string) int {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	full := fs.Bool("full", false, "Also report the flux binary, controller and API versions and check them against this CLI")
+	namespace := fs.String("namespace", "flux-system", "Namespace of the Flux installation")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout for the cluster queries")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, versionUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+	if _, err := parseArgs(fs, args); err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+
+	fmt.Printf("flux-enhanced-cli %s (built %s)\n", Version, BuildTime)
+	if !*full {
+		return 0
+	}
+
+	cliVersion := fluxBinaryVersion()
+	if cliVersion == "" {
+		fmt.Println("flux: not found")
+	} else {
+		fmt.Printf("flux: %s\n", cliVersion)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	cluster, err := events.NewCluster(*clientOpts)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	controllers, err := cluster.ControllerVersions(ctx, *namespace)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	apis, err := cluster.ServedAPIVersions()
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+
+	output.Println()
+	if len(controllers) == 0 {
+		fmt.Printf("No Flux controllers found in %s\n", *namespace)
+	} else {
+		rows := make([][]string, 0, len(controllers))
+		for _, c := range controllers {
+			rows = append(rows, []string{c.Name, orDash(c.Version), orDash(c.Distribution)})
+		}
+		output.PrintTable([]string{"CONTROLLER", "VERSION", "DISTRIBUTION"}, rows)
+	}
+	output.Println()
+	rows := make([][]string, 0, len(apis))
+	for _, a := range apis {
+		rows = append(rows, []string{a.Resource.String(), orDash(strings.Join(a.Served, ",")), strings.Join(a.Expected, ",")})
+	}
+	output.PrintTable([]string{"RESOURCE", "SERVED", "EXPECTED"}, rows)
+
+	warnings := compatWarnings(cliVersion, controllers, apis)
+	if len(warnings) > 0 {
+		output.Println()
+	}
+	for _, w := range warnings {
+		output.PrintWarning(w)
+	}
+	return 0
+}
+
+// fluxBinaryVersion returns the version of the flux binary in PATH, or "" if
+// it is missing
+func fluxBinaryVersion() string {
+	if fluxMissing() {
+		return ""
+	}
+	out, err := exec.Command("flux", "--version").Output()
+	if err != nil {
+		return ""
+	}
+	// "flux version 2.2.3"
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return ""
+	}
+	version := strings.TrimPrefix(fields[len(fields)-1], "v")
+	if version == "" || version[0] < '0' || version[0] > '9' {
+		return ""
+	}
+	return "v" + version
+}
+
+// compatWarnings lists the mismatches between this CLI's expectations, the
+// flux binary and the cluster
+func compatWarnings(cliVersion string, controllers []events.ControllerVersion, apis []events.APIVersions) []string {
+	var warnings []string
+	for _, a := range apis {
+		switch {
+		case len(a.Served) == 0:
+			warnings = append(warnings, fmt.Sprintf("%s is not served by the cluster", a.Resource))
+		case !a.Compatible():
+			warnings = append(warnings, fmt.Sprintf("%s is served as %s, but this CLI uses %s", a.Resource, strings.Join(a.Served, ", "), strings.Join(a.Expected, " or ")))
+		}
+	}
+
+	var distributions []string
+	for _, c := range controllers {
+		if c.Distribution != "" && !slices.Contains(distributions, c.Distribution) {
+			distributions = append(distributions, c.Distribution)
+		}
+	}
+	switch {
+	case len(distributions) > 1:
+		warnings = append(warnings, fmt.Sprintf("the controllers come from different Flux releases: %s", strings.Join(distributions, ", ")))
+	case len(distributions) == 1 && cliVersion != "" && minorVersion(cliVersion) != minorVersion(distributions[0]):
+		warnings = append(warnings, fmt.Sprintf("the flux binary is %s but the cluster runs Flux %s", cliVersion, distributions[0]))
+	}
+	return warnings
+}
+
+// minorVersion trims a version to its major and minor parts: v2.2.3 -> v2.2
+func minorVersion(version string) string {
+	parts := strings.SplitN("v"+strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return parts[0]
+	}
+	return parts[0] + "." + parts[1]
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+const waitUsage = `Usage: flux-enhanced-cli wait <kind> <name> [options]
+
+Waits for the reconciliation of a resource that is already in flight, without
+triggering one, e.g. after a webhook or another pipeline requested it. Events,
+condition changes and hints are shown as for a triggered reconcile. When a
+reconcile request is pending, the wait ends only once the controller handled
+it; otherwise it ends once the resource is ready at its current generation.
+The exit code is 1 when the reconcile failed or timed out.
+
+Kinds: kustomization (ks), helmrelease (hr), gitrepository, ocirepository,
+bucket, terraform (tf).
+`
+
+// waitCommand implements "wait <kind> <name>"
+func waitCommand(args []string) int {
+	fs := flag.NewFlagSet("wait", flag.ContinueOnError)
+	namespace := fs.String("namespace", "flux-system", "Namespace")
+	fs.StringVar(namespace, "n", "flux-system", "Namespace (shorthand)")
+	timeout := fs.Duration("timeout", 5*time.Minute, "How long to wait for the reconcile")
+	healthCheck := fs.String("health-check", "", "Extra checks after the resource is Ready (inventory: verify every Kustomization inventory object is Current)")
+	recursive := fs.Bool("recursive", false, "For kustomizations, also wait for the Kustomizations and HelmReleases in its inventory, and in theirs")
+	logLines := fs.Int("log-lines", 20, "Log lines shown for crash looping pods and failed Helm tests")
+	fluxNamespace := fs.String("flux-namespace", defaultFluxNamespace, "Namespace the Flux controllers run in")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	expandErrors := fs.Bool("expand-errors", false, "Print long condition and event messages in full instead of a preview")
+	clientOpts := addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, waitUsage)
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if err := applyInCluster(fs, clientOpts, namespace); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return 1
+	}
+	kind, sourceType, name, err := parseResourceArgs(positional)
+	if err == nil && name == "" {
+		err = errors.New("a resource name is required")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *healthCheck != healthCheckNone && *healthCheck != healthCheckInventory {
+		fmt.Fprintf(os.Stderr, "Error: invalid --health-check '%s'. Valid values: %s\n", *healthCheck, healthCheckInventory)
+		return 1
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		output.DisableColors()
+	}
+	if *expandErrors {
+		output.ExpandErrors()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	handleInterrupts(cancel)
+
+	result := runReconcile(ctx, reconcileOptions{
+		kind:          kind,
+		name:          name,
+		namespace:     *namespace,
+		sourceType:    sourceType,
+		wait:          true,
+		waitOnly:      true,
+		timeout:       *timeout,
+		client:        *clientOpts,
+		healthCheck:   *healthCheck,
+		recursive:     *recursive,
+		logLines:      *logLines,
+		skipSource:    true,
+		fluxNamespace: *fluxNamespace,
+	})
+	if !result.Success {
+		return 1
+	}
+	return 0
+}
+
+// pendingRequest returns the reconcile request the controller has yet to
+// handle, reporting whether one is in flight, in place of a trigger
+func pendingRequest(ctx context.Context, monitor *events.Monitor) (string, error) {
+	if monitor == nil {
+		return "", errors.New("waiting needs access to the resource")
+	}
+	token, err := monitor.PendingRequest()
+	if err != nil {
+		return "", err
+	}
+	out := output.FromContext(ctx)
+	if token != "" {
+		out.PrintStatus(fmt.Sprintf("Waiting for the reconcile requested at %s", token))
+	} else {
+		out.PrintStatus("No reconcile request pending, waiting for the current generation to be ready")
+	}
+	return token, nil
+}
+
+// runningReconcile checks for a reconcile already in progress to attach to
+// (--attach-if-running), returning its pending request, if any. The resource
+// is triggered as usual when none is running or the check fails.
+func runningReconcile(ctx context.Context, monitor *events.Monitor) (string, bool) {
+	out := output.FromContext(ctx)
+	running, token, err := monitor.ReconcileInProgress()
+	if err != nil {
+		out.PrintWarning(fmt.Sprintf("Could not check for a reconcile in progress: %v", err))
+		return "", false
+	}
+	if !running {
+		return "", false
+	}
+	if token != "" {
+		out.PrintStatus(fmt.Sprintf("A reconcile requested at %s is in progress, attaching to it instead of requesting another", token))
+	} else {
+		out.PrintStatus("A reconcile is in progress, attaching to it instead of requesting another")
+	}
+	return token, true
+}
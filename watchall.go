@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/events"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+)
+
+// watchRedrawInterval limits how often the watched table is redrawn; it is
+// also redrawn this often to keep AGE current
+const watchRedrawInterval = time.Second
+
+// watchResources keeps a table of the resources on screen, backed by
+// informers, until interrupted. On a terminal the table is redrawn in place;
+// otherwise rows are printed whenever they change.
+func watchResources(cluster *events.Cluster, kinds []string, opts events.InformerOptions, withNamespace, wide bool) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var served []string
+	for _, kind := range kinds {
+		if _, err := cluster.ResolveKind(kind); err != nil {
+			if len(kinds) == 1 {
+				output.PrintError(err.Error())
+				return 1
+			}
+			continue
+		}
+		served = append(served, kind)
+	}
+	if len(served) == 0 {
+		output.PrintError("None of the Flux resource kinds are served by the cluster")
+		return 1
+	}
+
+	informers, err := cluster.NewInformers(opts, served)
+	if err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	changed := make(chan struct{}, 1)
+	if err := informers.OnChange(func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}); err != nil {
+		output.PrintError(err.Error())
+		return 1
+	}
+	if err := informers.Start(ctx); err != nil {
+		if ctx.Err() != nil {
+			return 0
+		}
+		output.PrintError(err.Error())
+		return 1
+	}
+
+	live := output.Interactive()
+	table := &output.LiveTable{}
+	// printed holds the last printed state of each resource when not live
+	printed := map[string]events.ResourceSummary{}
+	render := func() {
+		summaries := watchedSummaries(informers, served)
+		if live {
+			table.Draw(getRows(summaries, len(served) > 1, withNamespace, wide))
+			return
+		}
+
+		var updates []events.ResourceSummary
+		current := map[string]bool{}
+		for _, s := range summaries {
+			key := s.Kind + "/" + s.Namespace + "/" + s.Name
+			current[key] = true
+			if prev, ok := printed[key]; !ok || summaryChanged(prev, s) {
+				updates = append(updates, s)
+				printed[key] = s
+			}
+		}
+		for key, s := range printed {
+			if !current[key] {
+				delete(printed, key)
+				s.Ready, s.Message = "Deleted", ""
+				updates = append(updates, s)
+			}
+		}
+		if len(updates) > 0 {
+			output.PrintTable(getRows(updates, len(served) > 1, withNamespace, wide))
+		}
+	}
+
+	render()
+	ticker := time.NewTicker(watchRedrawInterval)
+	defer ticker.Stop()
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-changed:
+			pending = true
+		case <-ticker.C:
+			if pending || live {
+				render()
+				pending = false
+			}
+		}
+	}
+}
+
+// watchedSummaries returns the cached resources, ordered by kind, namespace
+// and name
+func watchedSummaries(informers *events.Informers, kinds []string) []events.ResourceSummary {
+	var summaries []events.ResourceSummary
+	for _, kind := range kinds {
+		objects, err := informers.List(kind)
+		if err != nil {
+			continue
+		}
+		start := len(summaries)
+		for _, obj := range objects {
+			summaries = append(summaries, events.Summarize(obj, kind))
+		}
+		byName := summaries[start:]
+		sort.Slice(byName, func(i, j int) bool {
+			if byName[i].Namespace != byName[j].Namespace {
+				return byName[i].Namespace < byName[j].Namespace
+			}
+			return byName[i].Name < byName[j].Name
+		})
+	}
+	return summaries
+}
+
+// summaryChanged reports whether a resource changed in a way shown by "get"
+func summaryChanged(a, b events.ResourceSummary) bool {
+	return a.Ready != b.Ready || a.Message != b.Message || a.Revision != b.Revision ||
+		a.Suspended != b.Suspended || a.Source != b.Source || a.Interval != b.Interval
+}
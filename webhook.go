@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/config"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/output"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/report"
+	"github.com/junovy-hosting/flux-enhanced-cli/pkg/webhook"
+)
+
+// webhookReceiver maps Git pushes to reconcile-and-wait runs
+type webhookReceiver struct {
+	routes   []config.WebhookRoute
+	secret   string
+	timeout  time.Duration
+	statuses webhook.StatusReporter
+	// locks serializes the runs of each route
+	locks []sync.Mutex
+}
+
+func newWebhookReceiver(routes []config.WebhookRoute, secret string, timeout time.Duration, statuses webhook.StatusReporter) *webhookReceiver {
+	return &webhookReceiver{
+		routes:   routes,
+		secret:   secret,
+		timeout:  timeout,
+		statuses: statuses,
+		locks:    make([]sync.Mutex, len(routes)),
+	}
+}
+
+// handleWebhook validates a push delivery, answers right away and runs the
+// matching routes in the background
+func (s *server) handleWebhook(parse func(http.Header, []byte, string) (*webhook.Push, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSONError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 5*1024*1024))
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("failed to read the request body: %v", err))
+			return
+		}
+		push, err := parse(r.Header, body, s.webhooks.secret)
+		switch {
+		case errors.Is(err, webhook.ErrUnauthorized):
+			writeJSONError(w, http.StatusUnauthorized, err.Error())
+			return
+		case errors.Is(err, webhook.ErrIgnored):
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+			return
+		case err != nil:
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var matched []int
+		for i, route := range s.webhooks.routes {
+			if route.Repository == push.Repository && (route.Branch == "" || route.Branch == push.Branch) {
+				matched = append(matched, i)
+			}
+		}
+		if len(matched) == 0 {
+			writeJSON(w, http.StatusOK, map[string]string{"status": "no matching route"})
+			return
+		}
+		output.PrintStatus(fmt.Sprintf("Push to %s@%s (%s), running %d route(s)", push.Repository, push.Branch, push.ShortSHA(), len(matched)))
+		for _, i := range matched {
+			go s.runWebhookRoute(i, push)
+		}
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{"status": "accepted", "routes": len(matched)})
+	}
+}
+
+// runWebhookRoute reconciles the route's GitRepository, then each of its
+// Kustomizations, posting a commit status for every Kustomization. Only a
+// Kustomization that applied the pushed commit gets a success status.
+func (s *server) runWebhookRoute(index int, push *webhook.Push) {
+	receiver := s.webhooks
+	route := receiver.routes[index]
+	lock := &receiver.locks[index]
+	lock.Lock()
+	defer lock.Unlock()
+
+	ctx := output.WithPrinter(s.ctx, output.NewPrinter(fmt.Sprintf("%s@%s", push.Repository, push.ShortSHA())))
+	out := output.FromContext(ctx)
+	setStatus := func(ks config.ObjectRef, state, description string) {
+		name := fmt.Sprintf("flux/%s/%s", namespaceOrDefault(ks.Namespace), ks.Name)
+		if err := receiver.statuses.SetStatus(ctx, push, state, name, description); err != nil {
+			out.PrintWarning(err.Error())
+		}
+	}
+	for _, ks := range route.Kustomizations {
+		setStatus(ks, webhook.StatePending, "Waiting for the source to fetch the commit")
+	}
+
+	source := s.reconcileAndWait(ctx, "source", route.GitRepository, false)
+	if !source.Success {
+		for _, ks := range route.Kustomizations {
+			setStatus(ks, webhook.StateFailure, fmt.Sprintf("GitRepository %s failed: %s", route.GitRepository.Name, source.Message))
+		}
+		return
+	}
+	if !revisionAtCommit(source.Revision, push.SHA) {
+		message := fmt.Sprintf("GitRepository %s is at %s, not this commit (a newer push may supersede it)", route.GitRepository.Name, source.Revision)
+		out.PrintWarning(message)
+		for _, ks := range route.Kustomizations {
+			setStatus(ks, webhook.StateFailure, message)
+		}
+		return
+	}
+
+	for _, ks := range route.Kustomizations {
+		setStatus(ks, webhook.StatePending, "Reconciling")
+		result := s.reconcileAndWait(ctx, "kustomization", ks, true)
+		switch {
+		case !result.Success:
+			setStatus(ks, webhook.StateFailure, result.Message)
+		case !revisionAtCommit(result.Revision, push.SHA):
+			setStatus(ks, webhook.StateFailure, fmt.Sprintf("Reconciled %s, not this commit", result.Revision))
+		default:
+			setStatus(ks, webhook.StateSuccess, fmt.Sprintf("Reconciled %s in %s", result.Revision, result.Duration.Round(time.Second)))
+		}
+	}
+}
+
+// reconcileAndWait reconciles a Flux resource of a webhook route and waits
+// until it is ready
+func (s *server) reconcileAndWait(ctx context.Context, kind string, ref config.ObjectRef, skipSource bool) report.Result {
+	ctx, cancel := context.WithTimeout(ctx, s.webhooks.timeout)
+	defer cancel()
+	return runReconcile(ctx, reconcileOptions{
+		kind:       kind,
+		name:       ref.Name,
+		namespace:  namespaceOrDefault(ref.Namespace),
+		sourceType: "git",
+		wait:       true,
+		timeout:    s.webhooks.timeout,
+		client:     s.clientOpts,
+		skipSource: skipSource,
+	})
+}
+
+func namespaceOrDefault(namespace string) string {
+	if namespace == "" {
+		return "flux-system"
+	}
+	return namespace
+}